@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AccessLogConfig configures access entries (HTTPMiddleware and RPCCall.Finish) independently of the application entries
+type AccessLogConfig struct {
+	// Level is the minimum level of access entries, independent of the logger level and overrides,
+	// e.g. "info" keeps access entries while the application logs at "warn". The logger level applies if it's empty
+	Level string
+	// SampleEvery logs only every N-th access entry of successful requests. Failed requests (5xx responses
+	// and server-side RPC errors) are always logged. Zero and one log all entries
+	SampleEvery uint64
+}
+
+// accessLog is the access log configuration shared between a logger and its clones
+type accessLog struct {
+	level    zapcore.Level
+	hasLevel bool
+	every    uint64
+	count    atomic.Uint64
+}
+
+// WithAccessLog returns a logger writing access entries of HTTPMiddleware and StartRPC calls by the config,
+// e.g. sampling 1% of successful requests while logging all the failed ones. Invalid levels are ignored
+func (l *Logger) WithAccessLog(cfg AccessLogConfig) *Logger {
+	access := &accessLog{every: cfg.SampleEvery}
+	if cfg.Level != "" {
+		if lvl, err := parseLevel(cfg.Level); err == nil {
+			access.level, access.hasLevel = lvl, true
+		}
+	}
+
+	clone := l.clone()
+	clone.access = access
+	return clone
+}
+
+// checkAccess checks an access entry. Enabled entries below Error level are sampled
+func (l *Logger) checkAccess(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
+	if l == nil {
+		return nil
+	}
+	log := l.accessLogger()
+	if !log.Core().Enabled(lvl) {
+		return nil
+	}
+	if access := l.access; access != nil && lvl < ErrorLevel && access.every > 1 && (access.count.Inc()-1)%access.every != 0 {
+		return nil
+	}
+	return log.Check(lvl, msg)
+}
+
+// accessLogger returns the zap logger of access entries, built once per logger
+func (l *Logger) accessLogger() *zap.Logger {
+	l.applied.accessOnce.Do(func() {
+		log := l
+		if l.access != nil && l.access.hasLevel {
+			log = l.withFields(minLevelField(l.access.level))
+		}
+		l.applied.access = log.base().WithOptions(zap.WithCaller(false))
+	})
+	return l.applied.access
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithAccessLog(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("warn")
+
+	status := http.StatusOK
+	handler := log.WithAccessLog(AccessLogConfig{Level: "info", SampleEvery: 3}).HTTPMiddleware(HTTPConfig{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			FromContext(r.Context()).Info("application entry")
+			w.WriteHeader(status)
+		}),
+	)
+	for i := 0; i < 6; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	status = http.StatusBadGateway
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var statuses []int64
+	for _, e := range log.ObservedLogs().AllUntimed() {
+		if e.Message != "request completed" {
+			t.Errorf("want only access entries, got %+v", e)
+		}
+		statuses = append(statuses, e.ContextMap()["status"].(int64))
+	}
+	if len(statuses) != 3 || statuses[0] != 200 || statuses[1] != 200 || statuses[2] != 502 {
+		t.Errorf("want 2 of 6 successful requests and the failed one, got %v", statuses)
+	}
+}
+
+func TestAccessLogSamplesEnabledEntries(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("warn")
+
+	handler := log.WithAccessLog(AccessLogConfig{SampleEvery: 2}).HTTPMiddleware(HTTPConfig{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+	// Disabled entries don't advance the sampling counter
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	log.SetLevel("info")
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if n := log.ObservedLogs().Len(); n != 1 {
+		t.Errorf("want the first enabled entry, got %d entries", n)
+	}
+}
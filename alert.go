@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AlertRule fires an alert if more than Threshold matching entries are logged within Window, e.g.
+// more than 10 Error entries with the service=billing field in 5 minutes
+type AlertRule struct {
+	// Name identifies the rule in alerts
+	Name string
+	// Level is the minimum level of counted entries. Defaults to Error
+	Level zapcore.LevelEnabler
+	// Fields are the values of context or entry fields counted entries must have, e.g. {"service": "billing"}
+	Fields    map[string]string
+	Threshold int
+	Window    time.Duration
+	// Notify is called synchronously when the alert fires, e.g. the hook of NewAlertNotifier.
+	// Errors are reported to stderr
+	Notify func(Alert) error
+}
+
+// Alert is a fired AlertRule
+type Alert struct {
+	Rule   string
+	Count  int
+	Window time.Duration
+	Time   time.Time
+	// Fields are the Fields of the rule
+	Fields map[string]string
+	// Message is the message of the last counted entry
+	Message string
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("alert %s: %d entries in last %s", a.Rule, a.Count, a.Window)
+}
+
+// NewAlertNotifier returns an AlertRule.Notify hook sending alerts to Slack, MS Teams or PagerDuty,
+// see NewNotifyCore. Alerts are critical for PagerDuty, the default template is "[critical] {msg}"
+func NewAlertNotifier(cfg NotifyConfig) (func(Alert) error, error) {
+	if cfg.Template == "" {
+		cfg.Template = "[critical] {msg}"
+	}
+	n, err := newNotifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func(a Alert) error {
+		fields := make(map[string]interface{}, len(a.Fields)+2)
+		for key, value := range a.Fields {
+			fields[key] = value
+		}
+		fields["alert"], fields["count"] = a.Rule, a.Count
+		ent := zapcore.Entry{Level: zapcore.DPanicLevel, Time: a.Time, Message: a.String()}
+		return n.notify(publishedEntry{Entry: ent, Fields: fields})
+	}, nil
+}
+
+// alertRule counts the times of matching entries within the window
+type alertRule struct {
+	AlertRule
+	mu    sync.Mutex
+	times []time.Time
+}
+
+// record counts the entry and reports whether the alert fires, resetting the counter if it does
+func (r *alertRule) record(t time.Time) (count int, fire bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.times = append(r.times, t)
+	start := t.Add(-r.Window)
+	expired := 0
+	for expired < len(r.times) && r.times[expired].Before(start) {
+		expired++
+	}
+	r.times = append(r.times[:0], r.times[expired:]...)
+
+	if len(r.times) <= r.Threshold {
+		return 0, false
+	}
+	count = len(r.times)
+	r.times = r.times[:0]
+	return count, true
+}
+
+func (r *alertRule) matches(ent zapcore.Entry, values map[string]string) bool {
+	if !r.Level.Enabled(ent.Level) {
+		return false
+	}
+	for key, want := range r.Fields {
+		if values[key] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// alertEngine evaluates Config.Alerts
+type alertEngine struct {
+	core  zapcore.Core // core to write alert entries to
+	rules []*alertRule
+	keys  map[string]bool // field keys of all the rules
+}
+
+func newAlertEngine(core zapcore.Core, rules []AlertRule) (*alertEngine, error) {
+	e := &alertEngine{core: core, keys: map[string]bool{}}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, errors.New("alert rule must have Name")
+		}
+		if rule.Threshold <= 0 || rule.Window <= 0 {
+			return nil, errors.Errorf("alert rule %q must have positive Threshold and Window", rule.Name)
+		}
+		if rule.Level == nil {
+			rule.Level = zapcore.ErrorLevel
+		}
+		for key := range rule.Fields {
+			e.keys[key] = true
+		}
+		e.rules = append(e.rules, &alertRule{AlertRule: rule})
+	}
+	return e, nil
+}
+
+// withValues returns the values with the string values of the fields with rule keys added.
+// The values are copied if any are added
+func (e *alertEngine) withValues(values map[string]string, fields []zapcore.Field) map[string]string {
+	copied := false
+	for _, f := range fields {
+		if !e.keys[f.Key] {
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		value, ok := enc.Fields[f.Key]
+		if !ok {
+			continue
+		}
+		if !copied {
+			clone := make(map[string]string, len(e.keys))
+			for key, v := range values {
+				clone[key] = v
+			}
+			values, copied = clone, true
+		}
+		values[f.Key] = fmt.Sprint(value)
+	}
+	return values
+}
+
+// fire writes the alert entry at DPanic level, which doesn't panic when written to cores, and calls the hook
+func (e *alertEngine) fire(rule *alertRule, count int, ent zapcore.Entry) {
+	alert := Alert{Rule: rule.Name, Count: count, Window: rule.Window, Time: ent.Time, Fields: rule.Fields, Message: ent.Message}
+
+	alertEnt := zapcore.Entry{Level: zapcore.DPanicLevel, Time: ent.Time, LoggerName: ent.LoggerName, Message: alert.String()}
+	if ce := e.core.Check(alertEnt, nil); ce != nil {
+		fields := []zapcore.Field{zap.String("alert", rule.Name), zap.Int("count", count), zap.Duration("window", rule.Window)}
+		keys := make([]string, 0, len(rule.Fields))
+		for key := range rule.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fields = append(fields, zap.String(key, rule.Fields[key]))
+		}
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+
+	if rule.Notify != nil {
+		if err := rule.Notify(alert); err != nil {
+			fmt.Fprintf(stderr, "%v failed to notify alert %s: %v\n", time.Now().UTC(), rule.Name, err)
+		}
+	}
+}
+
+// alertCore counts entries passing through it for the alert rules, see Config.Alerts
+type alertCore struct {
+	core   zapcore.Core
+	engine *alertEngine
+	values map[string]string // context field values with rule keys
+}
+
+func (c *alertCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *alertCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.core = c.core.With(fields)
+	clone.values = c.engine.withValues(c.values, fields)
+	return &clone
+}
+
+func (c *alertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.core.Check(ent, ce)
+	for _, rule := range c.engine.rules {
+		if rule.Level.Enabled(ent.Level) {
+			return ce.AddCore(ent, c)
+		}
+	}
+	return ce
+}
+
+// Write only counts the entry, it's written by the wrapped core
+func (c *alertCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	values := c.engine.withValues(c.values, fields)
+	for _, rule := range c.engine.rules {
+		if !rule.matches(ent, values) {
+			continue
+		}
+		if count, fire := rule.record(ent.Time); fire {
+			c.engine.fire(rule, count, ent)
+		}
+	}
+	return nil
+}
+
+func (c *alertCore) Sync() error {
+	return c.core.Sync()
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAlerts(t *testing.T) {
+	buf := &bufferSyncer{}
+	var alerts []Alert
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Encoding:      EncodingJSON,
+		Outputs:       []zapcore.WriteSyncer{buf},
+		Alerts: []AlertRule{{
+			Name:      "billing",
+			Fields:    map[string]string{"service": "billing"},
+			Threshold: 2,
+			Window:    time.Minute,
+			Notify:    func(a Alert) error { alerts = append(alerts, a); return nil },
+		}},
+	})
+
+	billing := log.WithField("service", "billing")
+	billing.Error("charge failed 1")
+	billing.Warn("not counted")
+	log.WithField("service", "shipping").Error("not matched")
+	log.Error("not matched either")
+	billing.Error("charge failed 2")
+	if len(alerts) != 0 {
+		t.Fatalf("want no alerts before the threshold, got %v", alerts)
+	}
+	log.Event(ErrorLevel).Str("service", "billing").Msg("charge failed 3")
+
+	if len(alerts) != 1 || alerts[0].Count != 3 || alerts[0].Message != "charge failed 3" {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+	want := `"level":"dpanic","ts":`
+	if !bytes.Contains(buf.Bytes(), []byte(want)) || !bytes.Contains(buf.Bytes(), []byte(`"msg":"alert billing: 3 entries in last 1m0s","alert":"billing","count":3,"window":"1m0s","service":"billing"`)) {
+		t.Errorf("want the alert entry, got %s", buf.Bytes())
+	}
+
+	billing.Error("charge failed 4")
+	if len(alerts) != 1 {
+		t.Errorf("want the counter reset after the alert, got %d alerts", len(alerts))
+	}
+}
+
+func TestAlertRuleWindow(t *testing.T) {
+	rule := &alertRule{AlertRule: AlertRule{Threshold: 1, Window: time.Minute}}
+	start := time.Now()
+	if _, fire := rule.record(start); fire {
+		t.Fatal("want no alert for the first entry")
+	}
+	if _, fire := rule.record(start.Add(2 * time.Minute)); fire {
+		t.Fatal("want expired entries not counted")
+	}
+	if count, fire := rule.record(start.Add(2*time.Minute + time.Second)); !fire || count != 2 {
+		t.Errorf("want an alert of 2 entries, got %d %v", count, fire)
+	}
+}
+
+func TestAlertsInvalid(t *testing.T) {
+	for _, rule := range []AlertRule{{Threshold: 1, Window: time.Second}, {Name: "no window", Threshold: 1}} {
+		if _, err := New(Config{DisableStdOut: true, Alerts: []AlertRule{rule}}); err == nil {
+			t.Errorf("want an error for %+v", rule)
+		}
+	}
+}
+
+func TestAlertNotifier(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+	}))
+	defer server.Close()
+
+	notify, err := NewAlertNotifier(NotifyConfig{Service: NotifySlack, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notify(Alert{Rule: "billing", Count: 3, Window: time.Minute, Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := body["text"].(string); !strings.HasPrefix(text, "[critical] alert billing: 3 entries") {
+		t.Errorf("unexpected notification: %v", body)
+	}
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultIPKeys are the field keys holding IP addresses by default, including the "rpc.peer" field of StartRPC
+var defaultIPKeys = []string{"ip", "client_ip", "remote_ip", "remote_addr", "peer", "peer_addr", "rpc.peer", "x_forwarded_for", "x_real_ip"}
+
+// IPAnonymization anonymizes IP addresses in field values, e.g. to keep access logs GDPR-compliant
+type IPAnonymization struct {
+	Enabled bool
+	// Hash replaces addresses with keyed hashes, so entries of a client can still be correlated.
+	// By default addresses are truncated: the last octet of IPv4 and the last 80 bits of IPv6 addresses are zeroed
+	Hash bool
+	// HashKey is the HMAC key of Hash, so hashes can't be reversed by hashing all the addresses.
+	// A random key is generated if it's empty, so hashes differ between processes
+	HashKey []byte
+	// Keys are the field keys (case-insensitive) holding addresses, defaults to common ones like "ip", "remote_addr"
+	// and "rpc.peer". Dotted keys match by their suffixes too, e.g. "http.client_ip" matches the "client_ip" key.
+	// Values can be addresses with ports or comma-separated lists like X-Forwarded-For
+	Keys []string
+	// DetectValues also anonymizes string values of other fields if they're addresses as a whole
+	DetectValues bool
+}
+
+// ipAnonymizer anonymizes addresses as configured by IPAnonymization
+type ipAnonymizer struct {
+	cfg  IPAnonymization
+	keys map[string]bool
+}
+
+func newIPAnonymizer(cfg IPAnonymization) (*ipAnonymizer, error) {
+	if cfg.Hash && len(cfg.HashKey) == 0 {
+		cfg.HashKey = make([]byte, sha256.Size)
+		if _, err := rand.Read(cfg.HashKey); err != nil {
+			return nil, errors.Wrap(err, "failed to generate IP hash key")
+		}
+	}
+	keys := cfg.Keys
+	if len(keys) == 0 {
+		keys = defaultIPKeys
+	}
+	a := &ipAnonymizer{cfg: cfg, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		a.keys[strings.ToLower(key)] = true
+	}
+	return a, nil
+}
+
+// matchKey reports whether the field key or a suffix of it after a dot is one of the keys
+func (a *ipAnonymizer) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for {
+		if a.keys[key] {
+			return true
+		}
+		i := strings.IndexByte(key, '.')
+		if i < 0 {
+			return false
+		}
+		key = key[i+1:]
+	}
+}
+
+// anonymizeValue anonymizes the addresses of a value like "10.1.2.3", "[::1]:443" or "10.1.2.3, 10.4.5.6".
+// It reports false if the value isn't an address or a list of them
+func (a *ipAnonymizer) anonymizeValue(value string) (string, bool) {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		trimmed := strings.TrimSpace(part)
+		host, port, err := net.SplitHostPort(trimmed)
+		if err != nil {
+			host, port = trimmed, ""
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return value, false
+		}
+
+		anonymized := a.anonymizeIP(ip)
+		if port != "" {
+			anonymized = net.JoinHostPort(anonymized, port)
+		}
+		parts[i] = strings.Replace(part, trimmed, anonymized, 1)
+	}
+	return strings.Join(parts, ","), true
+}
+
+func (a *ipAnonymizer) anonymizeIP(ip net.IP) string {
+	if a.cfg.Hash {
+		mac := hmac.New(sha256.New, a.cfg.HashKey)
+		mac.Write(ip.To16())
+		return hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// anonymizeFields returns the fields with addresses anonymized, copying them only if needed
+func (a *ipAnonymizer) anonymizeFields(fields []zapcore.Field) []zapcore.Field {
+	result, copied := fields, false
+	for i, f := range fields {
+		byKey := a.matchKey(f.Key)
+		if !byKey && !a.cfg.DetectValues {
+			continue
+		}
+
+		var value string
+		switch f.Type {
+		case zapcore.StringType:
+			value = f.String
+		case zapcore.ByteStringType:
+			b, _ := f.Interface.([]byte)
+			value = string(b)
+		case zapcore.StringerType:
+			value = fmt.Sprint(f.Interface)
+		default:
+			continue
+		}
+		anonymized, ok := a.anonymizeValue(value)
+		if !ok {
+			continue
+		}
+		if !copied {
+			result, copied = append([]zapcore.Field(nil), fields...), true
+		}
+		result[i] = zap.String(f.Key, anonymized)
+	}
+	return result
+}
+
+// anonymizeCore anonymizes IP addresses in field values, see Config.AnonymizeIPs
+type anonymizeCore struct {
+	core       zapcore.Core
+	anonymizer *ipAnonymizer
+}
+
+func (c *anonymizeCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *anonymizeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &anonymizeCore{core: c.core.With(c.anonymizer.anonymizeFields(fields)), anonymizer: c.anonymizer}
+}
+
+func (c *anonymizeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *anonymizeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(c.anonymizer.anonymizeFields(fields)...)
+	}
+	return nil
+}
+
+func (c *anonymizeCore) Sync() error {
+	return c.core.Sync()
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAnonymizeIPs(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, AnonymizeIPs: IPAnonymization{Enabled: true}})
+
+	log.WithField("remote_addr", "203.0.113.42:51234").Event(InfoLevel).
+		Str("X_Forwarded_For", "198.51.100.7, 2001:db8:85a3:8d3:1319:8a2e:370:7348").
+		Stringer("client_ip", net.ParseIP("192.0.2.200")).
+		Str("ip", "unknown").
+		Str("upstream", "10.0.0.1").
+		Str("http.Client_IP", "192.0.2.201").
+		Msg("request")
+
+	fields := log.ObservedLogs().AllUntimed()[0].ContextMap()
+	for key, want := range map[string]string{
+		"remote_addr":     "203.0.113.0:51234",
+		"X_Forwarded_For": "198.51.100.0, 2001:db8:85a3::",
+		"client_ip":       "192.0.2.0",
+		"ip":              "unknown",
+		"upstream":        "10.0.0.1",
+		"http.Client_IP":  "192.0.2.0",
+	} {
+		if fields[key] != want {
+			t.Errorf("want %s=%s, got %v", key, want, fields[key])
+		}
+	}
+}
+
+func TestAnonymizeRPCPeer(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, AnonymizeIPs: IPAnonymization{Enabled: true}})
+
+	ctx, call := log.StartRPC(context.Background(), "/pkg.Service/Method", "10.1.2.3:5000")
+	FromContext(ctx).Info("handling")
+	call.Finish("OK", nil)
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want the entry and the RPC completion, got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		if peer := e.ContextMap()["rpc.peer"]; peer != "10.1.2.0:5000" {
+			t.Errorf("want anonymized peer in %q, got %v", e.Message, peer)
+		}
+	}
+}
+
+func TestAnonymizeIPsHash(t *testing.T) {
+	a, err := newIPAnonymizer(IPAnonymization{Enabled: true, Hash: true, HashKey: []byte("key"), DetectValues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := a.anonymizeValue("203.0.113.42")
+	second, _ := a.anonymizeValue("203.0.113.42")
+	other, _ := a.anonymizeValue("203.0.113.43")
+	if first != second || first == other || len(first) != 16 || strings.Contains(first, "203") {
+		t.Errorf("unexpected hashes: %s %s %s", first, second, other)
+	}
+
+	fields := a.anonymizeFields([]zap.Field{zap.String("upstream", "[::1]:443"), zap.String("msg", "from 10.0.0.1")})
+	if !strings.HasSuffix(fields[0].String, ":443") || strings.Contains(fields[0].String, "::1") || fields[1].String != "from 10.0.0.1" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
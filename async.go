@@ -0,0 +1,493 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncQueueSize     = 4096
+	defaultDropNoticeInterval = 10 * time.Second
+)
+
+// AsyncConfig configures writing entries to the outputs from a background goroutine
+type AsyncConfig struct {
+	// Enabled makes logging calls only enqueue entries
+	Enabled bool
+	// QueueSize is the number of queued entries. Defaults to 4096
+	QueueSize int
+	// NonBlocking drops entries when the queue is full instead of waiting, guaranteeing logging never blocks
+	// hard real-time paths. With Config.ConcurrentOutputs the per-output queues drop entries too, even if Enabled isn't set.
+	// Dropped entries are counted by level (once per output dropping them) and reported by a periodic notice.
+	// Entries above Error level are never dropped by the async queue, since they can terminate the program
+	NonBlocking bool
+	// DropNoticeInterval is the interval of "N entries dropped" notices. Defaults to 10s
+	DropNoticeInterval time.Duration
+	// PriorityLanes queues entries in lanes by level: Error and above, Info and Warn, Debug.
+	// Higher lanes are written first, and an entry arriving at the full queue evicts the oldest entry
+	// of the lowest lower lane (Debug entries first) instead of waiting or being dropped,
+	// so critical entries survive overload. Evicted entries are reported as dropped.
+	// Entries keep their order within a lane only
+	PriorityLanes bool
+	// CaptureFields encodes mutable values of entry fields (maps, slices, pointers, marshalers)
+	// and evaluates Stringers when entries are queued, so entries have the values at the logging call
+	// even if the caller changes them before the entries are written. Captured objects have sorted keys
+	CaptureFields bool
+}
+
+// asyncEntry is a queued entry or a sync request if done isn't nil
+type asyncEntry struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	done   chan struct{}
+}
+
+// asyncWriter writes queued entries to their cores
+type asyncWriter struct {
+	cfg   AsyncConfig
+	queue chan asyncEntry
+	lanes *laneQueue // replaces queue if AsyncConfig.PriorityLanes is set
+	root  zapcore.Core
+	drops *dropCounter
+
+	// mu guards the queue from receiving entries after the writer is closed, when they would never be written
+	mu         sync.RWMutex
+	closeOnce  sync.Once
+	closed     chan struct{}
+	stopped    chan struct{}
+	stopNotice func() error
+}
+
+// newAsyncWriter creates the writer counting dropped entries to the drops shared with the outputs, if not nil
+func newAsyncWriter(root zapcore.Core, cfg AsyncConfig, drops *dropCounter) *asyncWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+	if cfg.DropNoticeInterval <= 0 {
+		cfg.DropNoticeInterval = defaultDropNoticeInterval
+	}
+	if drops == nil {
+		drops = &dropCounter{}
+	}
+
+	w := &asyncWriter{
+		cfg:     cfg,
+		root:    root,
+		drops:   drops,
+		closed:  make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if cfg.PriorityLanes {
+		w.lanes = newLaneQueue(cfg.QueueSize)
+		go w.runLanes()
+	} else {
+		w.queue = make(chan asyncEntry, cfg.QueueSize)
+		go w.run()
+	}
+	if cfg.NonBlocking || cfg.PriorityLanes {
+		w.stopNotice = runEvery(cfg.DropNoticeInterval, w.reportDropped)
+	}
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	for {
+		select {
+		case e := <-w.queue:
+			w.write(e)
+		case <-w.closed:
+			// Drain the entries queued before closing
+			for {
+				select {
+				case e := <-w.queue:
+					w.write(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *asyncWriter) write(e asyncEntry) {
+	if e.done != nil {
+		close(e.done)
+		return
+	}
+	if ce := e.core.Check(e.ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(e.fields...)
+	}
+}
+
+// runLanes is run for AsyncConfig.PriorityLanes
+func (w *asyncWriter) runLanes() {
+	defer close(w.stopped)
+	for {
+		if e, ok := w.lanes.pop(); ok {
+			w.write(e)
+			continue
+		}
+		select {
+		case <-w.lanes.ready:
+		case <-w.closed:
+			for e, ok := w.lanes.pop(); ok; e, ok = w.lanes.pop() {
+				w.write(e)
+			}
+			return
+		}
+	}
+}
+
+// enqueue queues the entry, dropping it if the queue is full in the non-blocking mode or the writer is closed
+func (w *asyncWriter) enqueue(e asyncEntry) {
+	if !w.push(e, w.cfg.NonBlocking) {
+		w.drops.add(e.ent.Level)
+	}
+}
+
+// push queues the entry, waiting for room unless mayDrop is set. It fails if the entry isn't queued
+func (w *asyncWriter) push(e asyncEntry, mayDrop bool) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.isClosed() {
+		return false
+	}
+	if w.lanes != nil {
+		return w.enqueueLane(e, mayDrop)
+	}
+	if !mayDrop {
+		w.queue <- e
+		return true
+	}
+	select {
+	case w.queue <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueueLane queues the entry to its lane, evicting a lower priority entry if the queue is full.
+// It's called with mu read-locked, so the writer isn't closed until the entry is queued
+func (w *asyncWriter) enqueueLane(e asyncEntry, mayDrop bool) bool {
+	for {
+		evicted, hasEvicted, ok, space := w.lanes.push(e)
+		if hasEvicted {
+			w.drops.add(evicted.ent.Level)
+		}
+		if ok {
+			return true
+		}
+		if mayDrop {
+			return false
+		}
+		<-space
+	}
+}
+
+// writeSync writes the entry from the writer goroutine after the queued ones and waits until it's written,
+// so it doesn't race with them. The entry is never dropped
+func (w *asyncWriter) writeSync(e asyncEntry) {
+	if !w.push(e, false) {
+		// The entries queued before closing are written once the writer is stopped
+		<-w.stopped
+		w.write(e)
+		return
+	}
+	w.flush()
+}
+
+// isClosed reports whether Close is called. The result holds while mu is read-locked
+func (w *asyncWriter) isClosed() bool {
+	select {
+	case <-w.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush waits until the entries queued before the call are written
+func (w *asyncWriter) flush() {
+	done := make(chan struct{})
+	w.mu.RLock()
+	if w.isClosed() {
+		w.mu.RUnlock()
+		// The queued entries are written before the writer stops
+		<-w.stopped
+		return
+	}
+	if w.lanes != nil {
+		// The lowest lane is written last, so the entries of all lanes queued before are written by then
+		w.lanes.pushLast(asyncEntry{done: done})
+	} else {
+		w.queue <- asyncEntry{done: done}
+	}
+	w.mu.RUnlock()
+	select {
+	case <-done:
+	case <-w.stopped:
+	}
+}
+
+// reportDropped logs the number of entries dropped since the previous report
+func (w *asyncWriter) reportDropped() {
+	if ent, fields, ok := w.drops.notice(); ok {
+		w.enqueue(asyncEntry{core: w.root, ent: ent, fields: fields})
+	}
+}
+
+func (w *asyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		if w.stopNotice != nil {
+			_ = w.stopNotice()
+		}
+		w.mu.Lock()
+		close(w.closed)
+		w.mu.Unlock()
+		<-w.stopped
+
+		// The writer is stopped, so the final notice is written directly.
+		// It includes the entries dropped since the writer is closed
+		if ent, fields, ok := w.drops.notice(); ok {
+			w.write(asyncEntry{core: w.root, ent: ent, fields: fields})
+		}
+	})
+	return nil
+}
+
+// dropCounter counts dropped entries by level for the "N entries dropped" notices, see AsyncConfig.NonBlocking
+type dropCounter struct {
+	dropped [sampleLevels]atomic.Int64
+}
+
+func (d *dropCounter) add(lvl zapcore.Level) {
+	d.dropped[lvl-zapcore.DebugLevel].Inc()
+}
+
+// notice returns the notice of the entries dropped since the previous call, false if none are dropped
+func (d *dropCounter) notice() (zapcore.Entry, []zapcore.Field, bool) {
+	var total int64
+	fields := make([]zapcore.Field, 0, sampleLevels)
+	for i := range d.dropped {
+		if n := d.dropped[i].Swap(0); n > 0 {
+			total += n
+			fields = append(fields, zap.Int64("dropped."+(zapcore.DebugLevel+zapcore.Level(i)).String(), n))
+		}
+	}
+	if total == 0 {
+		return zapcore.Entry{}, nil, false
+	}
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: fmt.Sprintf("%d entries dropped", total)}
+	return ent, fields, true
+}
+
+// startDropNotices writes the notices of the dropped entries to the core periodically and once more when stopped.
+// It's used for the non-blocking ConcurrentOutputs if Async isn't enabled, asyncWriter writes the notices otherwise
+func startDropNotices(core zapcore.Core, drops *dropCounter, interval time.Duration) (stop func() error) {
+	if interval <= 0 {
+		interval = defaultDropNoticeInterval
+	}
+	notify := func() {
+		ent, fields, ok := drops.notice()
+		if !ok {
+			return
+		}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.ErrorOutput = stderr
+			ce.Write(fields...)
+		}
+	}
+
+	stopTicker := runEvery(interval, notify)
+	return func() error {
+		err := stopTicker()
+		notify()
+		return err
+	}
+}
+
+// dropCore counts the entries dropped by a non-blocking ConcurrentOutputs queue instead of failing their writes
+type dropCore struct {
+	zapcore.Core
+	drops *dropCounter
+}
+
+func (c *dropCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dropCore{Core: c.Core.With(fields), drops: c.drops}
+}
+
+func (c *dropCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dropCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if errors.Is(err, errEntryDropped) {
+		c.drops.add(ent.Level)
+		return nil
+	}
+	return err
+}
+
+// asyncCore enqueues entries to be written by asyncWriter.
+// Entries above Error level are never dropped and are waited for until written, since they can terminate the program
+type asyncCore struct {
+	core   zapcore.Core
+	writer *asyncWriter
+}
+
+func (c *asyncCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncCore{core: c.core.With(fields), writer: c.writer}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level > zapcore.ErrorLevel {
+		c.writer.writeSync(asyncEntry{core: c.core, ent: ent, fields: fields})
+		return nil
+	}
+
+	// The fields slice can be reused by the caller, e.g. by Event
+	if c.writer.cfg.CaptureFields {
+		fields = captureFields(fields)
+	} else {
+		fields = append([]zapcore.Field(nil), fields...)
+	}
+	c.writer.enqueue(asyncEntry{core: c.core, ent: ent, fields: fields})
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	c.writer.flush()
+	return c.core.Sync()
+}
+
+// laneCount is the number of AsyncConfig.PriorityLanes
+const laneCount = 3
+
+// laneOf returns the lane of the level, 0 is the highest priority
+func laneOf(lvl zapcore.Level) int {
+	switch {
+	case lvl >= zapcore.ErrorLevel:
+		return 0
+	case lvl >= zapcore.InfoLevel:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// laneQueue is a bounded queue of entries in priority lanes, see AsyncConfig.PriorityLanes
+type laneQueue struct {
+	mu       sync.Mutex
+	lanes    [laneCount][]asyncEntry
+	size     int
+	capacity int
+
+	ready chan struct{} // signaled on push
+	space chan struct{} // closed and replaced on pop if there are waiting pushes, waking up all of them
+	waits bool
+}
+
+func newLaneQueue(capacity int) *laneQueue {
+	return &laneQueue{capacity: capacity, ready: make(chan struct{}, 1), space: make(chan struct{})}
+}
+
+// push queues the entry. If the queue is full, the oldest entry of the lowest lane below the entry lane
+// is evicted to make room. It fails if there's no such entry, returning a channel closed once there's room
+func (q *laneQueue) push(e asyncEntry) (evicted asyncEntry, hasEvicted, ok bool, space <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lane := laneOf(e.ent.Level)
+	if q.size >= q.capacity {
+		for lower := laneCount - 1; lower > lane && !hasEvicted; lower-- {
+			if i := q.firstEntry(lower); i >= 0 {
+				evicted, hasEvicted = q.lanes[lower][i], true
+				q.lanes[lower] = append(q.lanes[lower][:i], q.lanes[lower][i+1:]...)
+				q.size--
+			}
+		}
+		if !hasEvicted {
+			q.waits = true
+			return evicted, false, false, q.space
+		}
+	}
+	q.lanes[lane] = append(q.lanes[lane], e)
+	q.size++
+	signal(q.ready)
+	return evicted, hasEvicted, true, nil
+}
+
+// firstEntry returns the index of the first entry of the lane skipping sync requests, -1 if there's none
+func (q *laneQueue) firstEntry(lane int) int {
+	for i, e := range q.lanes[lane] {
+		if e.done == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// pushLast queues a sync request to the lowest lane regardless of the capacity
+func (q *laneQueue) pushLast(e asyncEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lanes[laneCount-1] = append(q.lanes[laneCount-1], e)
+	signal(q.ready)
+}
+
+// pop dequeues the first entry of the highest non-empty lane
+func (q *laneQueue) pop() (asyncEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for lane := range q.lanes {
+		if len(q.lanes[lane]) == 0 {
+			continue
+		}
+		e := q.lanes[lane][0]
+		q.lanes[lane][0] = asyncEntry{}
+		q.lanes[lane] = q.lanes[lane][1:]
+		if e.done == nil {
+			q.size--
+			if q.waits {
+				close(q.space)
+				q.space, q.waits = make(chan struct{}), false
+			}
+		}
+		return e, true
+	}
+	return asyncEntry{}, false
+}
+
+// signal notifies a waiter of the channel without blocking
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAsync(t *testing.T) {
+	out := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, Outputs: []zapcore.WriteSyncer{out}, Async: AsyncConfig{Enabled: true}})
+
+	for i := 0; i < 100; i++ {
+		log.WithField("i", i).Info("async")
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out.Bytes()), "\tasync"); n != 100 {
+		t.Errorf("want 100 entries, got %d", n)
+	}
+}
+
+func TestAsyncNonBlocking(t *testing.T) {
+	out := &bufferSyncer{delay: 50 * time.Millisecond}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Outputs:       []zapcore.WriteSyncer{out},
+		Async:         AsyncConfig{Enabled: true, QueueSize: 1, NonBlocking: true, DropNoticeInterval: time.Hour},
+	})
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		log.Info("entry")
+		log.Debug("entry")
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("logging blocked for %s", elapsed)
+	}
+
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := string(out.Bytes())
+	if !strings.Contains(got, "entries dropped") || !strings.Contains(got, `"dropped.info": `) || !strings.Contains(got, `"dropped.debug": `) {
+		t.Errorf("want a drop notice, got %s", got)
+	}
+}
+
+func TestAsyncPriorityLanes(t *testing.T) {
+	out := &bufferSyncer{delay: 20 * time.Millisecond, started: make(chan struct{})}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Outputs:       []zapcore.WriteSyncer{out},
+		Async:         AsyncConfig{Enabled: true, QueueSize: 4, PriorityLanes: true, DropNoticeInterval: time.Hour},
+	})
+
+	// The first entry keeps the writer busy, so the rest are queued
+	log.Info("first")
+	<-out.started
+	for i := 0; i < 4; i++ {
+		log.Debug("debug")
+	}
+	for i := 0; i < 3; i++ {
+		log.Error("critical")
+	}
+	log.Info("info")
+
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := string(out.Bytes())
+	if n := strings.Count(got, "\tcritical"); n != 3 {
+		t.Errorf("want all the critical entries, got %d in %s", n, got)
+	}
+	if n := strings.Count(got, "\tdebug"); n != 0 {
+		t.Errorf("want the debug entries evicted, got %d in %s", n, got)
+	}
+	if !strings.Contains(got, "\tinfo") || !strings.Contains(got, `"dropped.debug": 4`) {
+		t.Errorf("want the info entry and a drop notice, got %s", got)
+	}
+	if strings.Index(got, "\tcritical") > strings.Index(got, "\tinfo") {
+		t.Errorf("want critical entries written first, got %s", got)
+	}
+}
+
+func TestAsyncPriorityLanesBlocking(t *testing.T) {
+	out := &bufferSyncer{}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Outputs:       []zapcore.WriteSyncer{out},
+		Async:         AsyncConfig{Enabled: true, QueueSize: 2, PriorityLanes: true},
+	})
+
+	done := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		go func() {
+			for i := 0; i < 50; i++ {
+				log.Error("critical")
+			}
+			done <- struct{}{}
+		}()
+	}
+	for g := 0; g < 4; g++ {
+		<-done
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out.Bytes()), "\tcritical"); n != 200 {
+		t.Errorf("want all the entries in the blocking mode, got %d", n)
+	}
+}
+
+func TestConcurrentOutputsNonBlocking(t *testing.T) {
+	out := &bufferSyncer{delay: 50 * time.Millisecond}
+	log := newLogger(t, Config{
+		DisableStdOut:     true,
+		Outputs:           []zapcore.WriteSyncer{out},
+		ConcurrentOutputs: true,
+		OutputQueueSize:   1,
+		Async:             AsyncConfig{NonBlocking: true, DropNoticeInterval: time.Hour},
+	})
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		log.Warn("entry")
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("logging blocked for %s", elapsed)
+	}
+
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := string(out.Bytes())
+	if !strings.Contains(got, "entries dropped") || !strings.Contains(got, `"dropped.warn": `) {
+		t.Errorf("want a drop notice, got %s", got)
+	}
+}
+
+func TestAsyncWriterDropsAfterClose(t *testing.T) {
+	for _, cfg := range []AsyncConfig{{NonBlocking: true}, {PriorityLanes: true}} {
+		out := &bufferSyncer{}
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), out, zapcore.DebugLevel)
+		w := newAsyncWriter(core, cfg, nil)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		w.enqueue(asyncEntry{core: core, ent: zapcore.Entry{Level: zapcore.InfoLevel, Message: "late"}})
+		w.flush()
+		ent, fields, ok := w.drops.notice()
+		if !ok || ent.Message != "1 entries dropped" || len(fields) != 1 || fields[0].Key != "dropped.info" {
+			t.Errorf("%+v: want the late entry counted as dropped, got %v %v", cfg, ent.Message, fields)
+		}
+	}
+}
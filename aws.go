@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+var (
+	awsRetryPattern     = regexp.MustCompile(`^retrying request (\S+)/(\S+), attempt (\d+)`)
+	awsRequestIDPattern = regexp.MustCompile(`(?im)^x-amzn?-request-?id: *(\S+)`)
+)
+
+// AWSLogger adapts a logger to the logging.Logger interface of smithy-go used by the AWS SDK v2.
+// Its classification is a string, so the logger doesn't depend on smithy-go; the logging.Logger implementation
+// is loggeraws.Logger of the github.com/kiteggrad/logger/loggeraws module:
+//
+//	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+//		o.ClientLogMode = aws.LogRetries | aws.LogResponse
+//		o.Logger = loggeraws.New(log.Named("s3"))
+//	})
+//
+// WARN entries are logged at Warn level, others at Debug level. Retry attempts and request IDs
+// are added as the aws.service, aws.operation, aws.attempt and aws.request_id fields
+type AWSLogger struct {
+	log      *Logger
+	disabled atomic.Bool
+}
+
+// NewAWSLogger creates an enabled AWS SDK logger, one per client to toggle them separately
+func NewAWSLogger(l *Logger) *AWSLogger {
+	return &AWSLogger{log: l.withOptions(zap.WithCaller(false))}
+}
+
+// SetEnabled turns the logging of the client on or off
+func (a *AWSLogger) SetEnabled(enabled bool) {
+	a.disabled.Store(!enabled)
+}
+
+// Logf logs an SDK message of the classification ("WARN" or "DEBUG")
+func (a *AWSLogger) Logf(classification string, format string, v ...interface{}) {
+	if a.disabled.Load() {
+		return
+	}
+	lvl := DebugLevel
+	if classification == "WARN" {
+		lvl = WarnLevel
+	}
+	base := a.log.base()
+	// The message is formatted before Check, so cores matching messages (sampling, routes...) see it
+	if !base.Core().Enabled(lvl) {
+		return
+	}
+	msg := fmt.Sprintf(format, v...)
+	ce := base.Check(lvl, msg)
+	if ce == nil {
+		return
+	}
+
+	var fields []zap.Field
+	if m := awsRetryPattern.FindStringSubmatch(msg); m != nil {
+		attempt, _ := strconv.Atoi(m[3])
+		fields = append(fields, zap.String("aws.service", m[1]), zap.String("aws.operation", m[2]), zap.Int("aws.attempt", attempt))
+	}
+	if m := awsRequestIDPattern.FindStringSubmatch(msg); m != nil {
+		fields = append(fields, zap.String("aws.request_id", m[1]))
+	}
+	ce.Write(fields...)
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAWSLogger(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	aws := NewAWSLogger(log)
+	aws.Logf("DEBUG", "retrying request %s/%s, attempt %d", "S3", "GetObject", 2)
+	aws.Logf("DEBUG", "Response\nHTTP/1.1 200 OK\r\nX-Amz-Request-Id: ABC123\r\n")
+	aws.Logf("WARN", "failed to rewind transport stream for retry")
+	aws.SetEnabled(false)
+	aws.Logf("WARN", "dropped")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Level != zapcore.DebugLevel || e.Message != "retrying request S3/GetObject, attempt 2" ||
+		e.ContextMap()["aws.service"] != "S3" || e.ContextMap()["aws.operation"] != "GetObject" || e.ContextMap()["aws.attempt"] != int64(2) {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.ContextMap()["aws.request_id"] != "ABC123" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[2]; e.Level != zapcore.WarnLevel || e.Caller.Defined {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestAWSLoggerCheckedMessage(t *testing.T) {
+	checked := &messageCore{LevelEnabler: zapcore.InfoLevel}
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{checked}})
+
+	NewAWSLogger(log).Logf("WARN", "failed to rewind transport stream for %s", "retry")
+	NewAWSLogger(log).Logf("DEBUG", "disabled %s", "entry")
+	if len(checked.messages) != 1 || checked.messages[0] != "failed to rewind transport stream for retry" {
+		t.Errorf("want the message known in Check, got %q", checked.messages)
+	}
+}
+
+// messageCore records the messages of the checked entries
+type messageCore struct {
+	zapcore.LevelEnabler
+	messages []string
+}
+
+func (c *messageCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c *messageCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		c.messages = append(c.messages, ent.Message)
+	}
+	return ce
+}
+
+func (c *messageCore) Write(zapcore.Entry, []zapcore.Field) error { return nil }
+
+func (c *messageCore) Sync() error { return nil }
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// DefaultBinaryMaxLen is the initial number of bytes rendered by Hex and Base64 fields, see SetBinaryMaxLen
+const DefaultBinaryMaxLen = 256
+
+var binaryMaxLen = atomic.NewInt64(DefaultBinaryMaxLen)
+
+// SetBinaryMaxLen sets the number of bytes rendered by Hex and Base64 fields. Non-positive values disable truncation
+func SetBinaryMaxLen(n int) {
+	binaryMaxLen.Store(int64(n))
+}
+
+// Hex returns a field with the data hex-encoded, so raw frames don't corrupt console output
+// or produce invalid UTF-8 in JSON. Data longer than the max length (see SetBinaryMaxLen) is truncated
+// with the total size noted, e.g. "0a1b2c... (1024 bytes)"
+func Hex(key string, data []byte) zap.Field {
+	return zap.String(key, encodeBinary(data, hex.EncodeToString))
+}
+
+// Base64 returns a field with the data encoded with standard base64, truncated like Hex
+func Base64(key string, data []byte) zap.Field {
+	return zap.String(key, encodeBinary(data, base64.StdEncoding.EncodeToString))
+}
+
+// WithHex returns a logger with a Hex field
+func (l *Logger) WithHex(key string, data []byte) *Logger {
+	return l.withFields(Hex(key, data))
+}
+
+// WithBase64 returns a logger with a Base64 field
+func (l *Logger) WithBase64(key string, data []byte) *Logger {
+	return l.withFields(Base64(key, data))
+}
+
+func encodeBinary(data []byte, encode func([]byte) string) string {
+	if limit := int(binaryMaxLen.Load()); limit > 0 && len(data) > limit {
+		return encode(data[:limit]) + "... (" + strconv.Itoa(len(data)) + " bytes)"
+	}
+	return encode(data)
+}
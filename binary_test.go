@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestBinaryFields(t *testing.T) {
+	t.Cleanup(func() { SetBinaryMaxLen(DefaultBinaryMaxLen) })
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	frame := []byte{0x00, 0xff, 0x10, 0x80, 'a'}
+	log.WithHex("hex", frame).WithBase64("base64", frame).Info("frame")
+	SetBinaryMaxLen(2)
+	log.WithHex("hex", frame).WithBase64("base64", frame).Info("truncated")
+
+	entries := log.ObservedLogs().AllUntimed()
+	for i, want := range []map[string]interface{}{
+		{"hex": "00ff108061", "base64": "AP8QgGE="},
+		{"hex": "00ff... (5 bytes)", "base64": "AP8=... (5 bytes)"},
+	} {
+		fields := entries[i].ContextMap()
+		for key, value := range want {
+			if fields[key] != value {
+				t.Errorf("%s %s: want %s, got %v", entries[i].Message, key, value, fields[key])
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrCircuitOpen is returned by a circuit breaker without a fallback while writes are suspended
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed writes opening the circuit. Defaults to 5
+	FailureThreshold int
+	// CoolDown is the time to suspend writes for before probing the output again. Defaults to 30s
+	CoolDown time.Duration
+}
+
+// circuitBreaker is a zapcore.WriteSyncer suspending writes to a failing output.
+// After FailureThreshold consecutive failures writes go to the fallback for CoolDown,
+// then a single write probes the output: on success the circuit is closed again
+type circuitBreaker struct {
+	ws       zapcore.WriteSyncer
+	fallback zapcore.WriteSyncer
+	cfg      CircuitBreakerConfig
+	now      func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker wraps a (usually remote) output with a circuit breaker,
+// avoiding latency amplification when the output is down.
+// Fallback receives entries while the circuit is open, they are dropped if it's nil
+func NewCircuitBreaker(ws, fallback zapcore.WriteSyncer, cfg CircuitBreakerConfig) zapcore.WriteSyncer {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+
+	return &circuitBreaker{
+		ws:       ws,
+		fallback: fallback,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+func (b *circuitBreaker) Write(p []byte) (int, error) {
+	if !b.allow() {
+		return b.writeFallback(p)
+	}
+
+	n, err := b.ws.Write(p)
+	b.report(err)
+	if err != nil {
+		return b.writeFallback(p)
+	}
+	return n, nil
+}
+
+func (b *circuitBreaker) Sync() error {
+	if !b.allow() {
+		if b.fallback != nil {
+			return b.fallback.Sync()
+		}
+		return nil
+	}
+
+	err := b.ws.Sync()
+	b.report(err)
+	return err
+}
+
+// allow reports whether the output can be used. Once the cool down is over
+// only a single caller is allowed to probe the output
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.cfg.FailureThreshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) report(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openUntil = b.now().Add(b.cfg.CoolDown)
+	}
+}
+
+func (b *circuitBreaker) writeFallback(p []byte) (int, error) {
+	if b.fallback == nil {
+		return 0, ErrCircuitOpen
+	}
+	return b.fallback.Write(p)
+}
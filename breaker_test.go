@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	remote := &bufferSyncer{err: errors.New("connection refused")}
+	fallback := &bufferSyncer{}
+
+	ws := NewCircuitBreaker(remote, fallback, CircuitBreakerConfig{FailureThreshold: 2, CoolDown: time.Minute})
+	b := ws.(*circuitBreaker)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := ws.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("1\n") // failure 1, written to fallback
+	write("2\n") // failure 2, circuit is open
+	remote.setErr(nil)
+	write("3\n") // the circuit is still open
+
+	now = now.Add(time.Minute)
+	write("4\n") // probe succeeds, the circuit is closed
+	write("5\n")
+
+	if got := string(fallback.Bytes()); got != "1\n2\n3\n" {
+		t.Errorf("unexpected fallback data: %q", got)
+	}
+	if got := string(remote.Bytes()); got != "4\n5\n" {
+		t.Errorf("unexpected remote data: %q", got)
+	}
+}
+
+func TestCircuitBreakerWithoutFallback(t *testing.T) {
+	remote := &bufferSyncer{err: errors.New("connection refused")}
+	ws := NewCircuitBreaker(remote, nil, CircuitBreakerConfig{FailureThreshold: 1})
+
+	_, _ = ws.Write([]byte("1\n"))
+	if _, err := ws.Write([]byte("2\n")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("want ErrCircuitOpen, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
+)
+
+// EntryBus publishes entries to in-process subscribers, so application components (e.g. an admin UI
+// or an anomaly detector) consume logs without parsing the output. Its core is added with Config.Cores:
+//
+//	bus := logger.NewEntryBus()
+//	log, err := logger.New(logger.Config{Cores: []zapcore.Core{bus.Core(logger.InfoLevel)}})
+//	sub := bus.Subscribe(100, func(e logger.Entry) bool { return e.Level >= logger.ErrorLevel })
+//	defer sub.Close()
+//	for e := range sub.Entries() {
+//		...
+//	}
+type EntryBus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives the entries of an EntryBus, see EntryBus.Subscribe
+type Subscription struct {
+	bus     *EntryBus
+	filter  func(e Entry) bool
+	entries chan Entry
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// NewEntryBus creates an entry bus without subscribers
+func NewEntryBus() *EntryBus {
+	return &EntryBus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription to the entries accepted by the filter, all of them if it's nil.
+// Entries are buffered up to the buffer size and dropped when it's full, so a slow subscriber doesn't block logging
+func (b *EntryBus) Subscribe(buffer int, filter func(e Entry) bool) *Subscription {
+	s := &Subscription{bus: b, filter: filter, entries: make(chan Entry, buffer)}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Core returns a core publishing the entries of the level to the subscribers. All entries are published if it's nil
+func (b *EntryBus) Core(level zapcore.LevelEnabler) zapcore.Core {
+	if level == nil {
+		level = zapcore.DebugLevel
+	}
+	return &busCore{LevelEnabler: level, bus: b}
+}
+
+func (b *EntryBus) hasSubscribers() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs) != 0
+}
+
+func (b *EntryBus) publish(e Entry) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.entries <- e:
+		default:
+			s.dropped.Inc()
+		}
+	}
+}
+
+// Entries returns the channel of the entries. It's closed by Close
+func (s *Subscription) Entries() <-chan Entry {
+	return s.entries
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes the entries channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.entries)
+	})
+}
+
+// busCore publishes entries to an EntryBus
+type busCore struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+	bus    *EntryBus
+}
+
+func (c *busCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *busCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) && c.bus.hasSubscribers() {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *busCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.bus.publish(entryOf(ent, fieldsMap(c.fields, fields)))
+	return nil
+}
+
+func (c *busCore) Sync() error { return nil }
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEntryBus(t *testing.T) {
+	bus := NewEntryBus()
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{bus.Core(InfoLevel)}})
+
+	log.Info("before subscribing")
+	all := bus.Subscribe(10, nil)
+	errs := bus.Subscribe(1, func(e Entry) bool { return e.Level >= ErrorLevel })
+
+	log.Debug("below the level")
+	log.WithField("user", "bob").Info("login")
+	log.Error("first")
+	log.Error("second")
+	all.Close()
+	errs.Close()
+	log.Error("after closing")
+
+	var got []Entry
+	for e := range all.Entries() {
+		got = append(got, e)
+	}
+	if len(got) != 3 || got[0].Message != "login" || got[0].Fields["user"] != "bob" {
+		t.Errorf("unexpected entries: %+v", got)
+	}
+
+	var gotErrs []string
+	for e := range errs.Entries() {
+		gotErrs = append(gotErrs, e.Message)
+	}
+	if len(gotErrs) != 1 || gotErrs[0] != "first" || errs.Dropped() != 1 {
+		t.Errorf("want the first error and 1 dropped, got %v and %d", gotErrs, errs.Dropped())
+	}
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// CanonicalTimestamp replaces entry times in EncodingCanonical
+const CanonicalTimestamp = "TIMESTAMP"
+
+// canonicalEncoder writes JSON entries with sorted keys, CanonicalTimestamp instead of the time,
+// the caller file without line and no stacktraces, so the output of the same code is byte-for-byte stable.
+// The "<key>Verbose" stacktraces of errors are stripped by canonicalCore
+type canonicalEncoder struct {
+	zapcore.Encoder
+}
+
+func newCanonicalEncoder() canonicalEncoder {
+	cfg := jsonEncoderConfig()
+	cfg.StacktraceKey = zapcore.OmitKey
+	cfg.EncodeTime = func(_ time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(CanonicalTimestamp)
+	}
+	cfg.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(filepath.Base(caller.File))
+	}
+	return canonicalEncoder{Encoder: zapcore.NewJSONEncoder(cfg)}
+}
+
+func (e canonicalEncoder) Clone() zapcore.Encoder {
+	return canonicalEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e canonicalEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	// Decoding into a map sorts the keys on all levels, numbers are kept as written
+	var entry map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&entry); err != nil {
+		return nil, err
+	}
+
+	out := bufferPool.Get()
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(entry); err != nil {
+		out.Free()
+		return nil, err
+	}
+	return out, nil
+}
+
+// newOutputCore creates the core writing to an output with the encoder
+func newOutputCore(enc zapcore.Encoder, ws zapcore.WriteSyncer) zapcore.Core {
+	var core zapcore.Core
+	if out, ok := ws.(*batchWriter); ok {
+		core = &batchCore{enc: enc, out: out}
+	} else {
+		// The level is checked by levelCore
+		core = zapcore.NewCore(enc, ws, zapcore.DebugLevel)
+	}
+	if _, ok := enc.(canonicalEncoder); ok {
+		return canonicalCore{Core: core}
+	}
+	return core
+}
+
+// canonicalCore hides fmt.Formatter of error fields, so the encoder doesn't generate their "<key>Verbose" stacktraces.
+// Other fields are kept, including the ones named "<key>Verbose" by the caller
+type canonicalCore struct {
+	zapcore.Core
+}
+
+func (c canonicalCore) With(fields []zapcore.Field) zapcore.Core {
+	return canonicalCore{Core: c.Core.With(plainErrorFields(fields))}
+}
+
+func (c canonicalCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c canonicalCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, plainErrorFields(fields))
+}
+
+// plainErrorFields returns the fields with plainError values of error fields, copying the slice if any is replaced
+func plainErrorFields(fields []zapcore.Field) []zapcore.Field {
+	copied := false
+	for i, f := range fields {
+		if f.Type != zapcore.ErrorType {
+			continue
+		}
+		err, ok := f.Interface.(error)
+		if !ok {
+			continue
+		}
+		if plain := plainErrorOf(err); plain != err {
+			if !copied {
+				fields = append([]zapcore.Field(nil), fields...)
+				copied = true
+			}
+			fields[i].Interface = plain
+		}
+	}
+	return fields
+}
+
+// plainError hides fmt.Formatter of the error, keeping its message
+type plainError struct {
+	error
+}
+
+// plainErrorGroup is a plainError keeping the causes of a multierr error, see zapcore "<key>Causes" fields
+type plainErrorGroup struct {
+	plainError
+	causes []error
+}
+
+func (e plainErrorGroup) Errors() []error { return e.causes }
+
+// plainErrorOf returns the error without fmt.Formatter, or the error itself if it has no verbose form
+func plainErrorOf(err error) error {
+	// zap encodes nil pointers as "<nil>" only if they aren't wrapped
+	if v := reflect.ValueOf(err); v.Kind() == reflect.Ptr && v.IsNil() {
+		return err
+	}
+	if group, ok := err.(interface{ Errors() []error }); ok {
+		causes := group.Errors()
+		plain := plainErrorGroup{plainError: plainError{err}, causes: make([]error, len(causes))}
+		for i, cause := range causes {
+			plain.causes[i] = plainErrorOf(cause)
+		}
+		return plain
+	}
+	if _, ok := err.(fmt.Formatter); ok {
+		return plainError{err}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureFields returns a copy of the fields with the values encoded lazily captured at the call time:
+// reflected values and marshalers are encoded and Stringers are evaluated, see AsyncConfig.CaptureFields.
+// Values aren't copied, so the mutexes and unexported fields of structs are never read outside of their methods
+func captureFields(fields []zapcore.Field) []zapcore.Field {
+	captured := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		switch f.Type {
+		case zapcore.StringerType:
+			captured = append(captured, zap.String(f.Key, fmt.Sprint(f.Interface)))
+		case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+			// The encoding errors are captured as the "<key>Error" fields too
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			for _, key := range sortedKeys(enc.Fields) {
+				captured = append(captured, capturedField(key, captureValue(enc.Fields[key])))
+			}
+		case zapcore.InlineMarshalerType:
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			captured = append(captured, zap.Inline(captureValue(enc.Fields).(capturedObject)))
+		default:
+			captured = append(captured, f)
+		}
+	}
+	return captured
+}
+
+// capturedObject is an object encoded by the map encoder, see captureValue
+type capturedObject map[string]interface{}
+
+func (o capturedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, key := range sortedKeys(o) {
+		switch v := o[key].(type) {
+		case capturedObject:
+			_ = enc.AddObject(key, v)
+		case capturedArray:
+			_ = enc.AddArray(key, v)
+		case time.Time:
+			enc.AddTime(key, v)
+		case time.Duration:
+			enc.AddDuration(key, v)
+		case []byte:
+			enc.AddBinary(key, v)
+		case reflectError:
+			enc.AddString(key+"Error", v.Error())
+		default:
+			_ = enc.AddReflected(key, v)
+		}
+	}
+	return nil
+}
+
+// capturedArray is an array encoded by the map encoder, see captureValue
+type capturedArray []interface{}
+
+func (a capturedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, value := range a {
+		switch v := value.(type) {
+		case capturedObject:
+			_ = enc.AppendObject(v)
+		case capturedArray:
+			_ = enc.AppendArray(v)
+		case time.Time:
+			enc.AppendTime(v)
+		case time.Duration:
+			enc.AppendDuration(v)
+		case reflectError:
+			enc.AppendString(v.Error())
+		default:
+			_ = enc.AppendReflected(v)
+		}
+	}
+	return nil
+}
+
+// reflectError is the error of encoding a captured reflected value
+type reflectError struct{ error }
+
+// capturedField returns the field of a captured value
+func capturedField(key string, v interface{}) zapcore.Field {
+	switch v := v.(type) {
+	case capturedObject:
+		return zap.Object(key, v)
+	case capturedArray:
+		return zap.Array(key, v)
+	case reflectError:
+		return zap.String(key+"Error", v.Error())
+	case []byte:
+		return zap.Binary(key, v)
+	case json.RawMessage:
+		return zap.Reflect(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
+
+// captureValue converts a value of the map encoder to the captured one: objects and arrays are converted
+// recursively, binary values are copied, and reflected values are encoded to JSON
+func captureValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128, time.Time, time.Duration:
+		return v
+	case map[string]interface{}:
+		o := make(capturedObject, len(v))
+		for key, value := range v {
+			o[key] = captureValue(value)
+		}
+		return o
+	case []interface{}:
+		a := make(capturedArray, len(v))
+		for i, value := range v {
+			a[i] = captureValue(value)
+		}
+		return a
+	case []byte:
+		return append([]byte(nil), v...)
+	default:
+		// Encoded like the reflected encoder of zap, which doesn't escape HTML
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return reflectError{err}
+		}
+		return json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	}
+}
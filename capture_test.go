@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type captureNode struct {
+	Name  string
+	Tags  []string
+	Next  *captureNode
+	count int
+}
+
+func TestAsyncCaptureFields(t *testing.T) {
+	out := &bufferSyncer{delay: 10 * time.Millisecond}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Encoding:      EncodingJSON,
+		Outputs:       []zapcore.WriteSyncer{out},
+		Async:         AsyncConfig{Enabled: true, CaptureFields: true},
+	})
+
+	attrs := map[string]int{"n": 1}
+	log.Event(InfoLevel).Any("attrs", attrs).Msg("first")
+	log.Event(InfoLevel).Any("attrs", attrs).Msg("second")
+	attrs["n"] = 2
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(string(out.Bytes()), `"attrs":{"n":1}`); n != 2 {
+		t.Errorf("want the values at the logging call, got %s", out.Bytes())
+	}
+}
+
+func TestCaptureFields(t *testing.T) {
+	node := &captureNode{Name: "a", Tags: []string{"x"}, count: 1}
+	guarded := &captureGuarded{values: map[string]int{"n": 1}}
+	fields := captureFields([]zapcore.Field{
+		zap.Any("node", node),
+		zap.Object("guarded", guarded),
+		zap.Inline(guarded),
+		zap.Array("times", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+			enc.AppendDuration(time.Second)
+			return enc.AppendReflected(node)
+		})),
+		zap.Any("bad", func() {}),
+	})
+	node.Tags[0], node.Name = "y", "b"
+	guarded.set("n", 2)
+
+	enc := zapcore.NewJSONEncoder(jsonEncoderConfig())
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "captured"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"node":{"Name":"a","Tags":["x"],"Next":null}`,
+		`"guarded":{"n":1},"n":1,`,
+		`"times":["1s",{"Name":"a","Tags":["x"],"Next":null}]`,
+		`"badError":"json: unsupported type: func()"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %s in %s", want, buf)
+		}
+	}
+}
+
+// captureGuarded is a marshaler reading its unexported fields under a lock
+type captureGuarded struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+func (g *captureGuarded) set(key string, value int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] = value
+}
+
+func (g *captureGuarded) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, value := range g.values {
+		enc.AddInt(key, value)
+	}
+	return nil
+}
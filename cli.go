@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Command is implemented by *cobra.Command of github.com/spf13/cobra
+type Command interface {
+	Context() context.Context
+	SetContext(ctx context.Context)
+}
+
+// InitCLI creates the logger, sets it global (see SetGlobal) and returns a copy of ctx carrying it (see FromContext).
+// It's the glue of CLI frameworks, e.g. the Before hook of urfave/cli:
+//
+//	Before: func(c *cli.Context) (err error) {
+//		c.Context, _, err = logger.InitCLI(c.Context, cfg)
+//		return err
+//	},
+//	After: func(c *cli.Context) error { return logger.ShutdownCLI(c.Context) },
+func InitCLI(ctx context.Context, cfg Config) (context.Context, *Logger, error) {
+	log, err := New(cfg)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "failed to New")
+	}
+	SetGlobal(log)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return ToContext(ctx, log), log, nil
+}
+
+// InitCommand is InitCLI for cobra, setting the context of the command. The config is usually bound to
+// the persistent flags, so it's read once they are parsed:
+//
+//	cfg := logger.DefaultConfig()
+//	cfg.RegisterPFlags(root.PersistentFlags())
+//	root.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+//		_, err := logger.InitCommand(cmd, cfg)
+//		return err
+//	}
+//	root.PersistentPostRunE = func(cmd *cobra.Command, _ []string) error {
+//		return logger.ShutdownCLI(cmd.Context())
+//	}
+func InitCommand(cmd Command, cfg Config) (*Logger, error) {
+	ctx, log, err := InitCLI(cmd.Context(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	cmd.SetContext(ctx)
+	return log, nil
+}
+
+// ShutdownCLI shuts down the logger created by InitCLI or InitCommand, flushing the outputs
+func ShutdownCLI(ctx context.Context) error {
+	return FromContext(ctx).Shutdown(context.Background())
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type testCommand struct{ ctx context.Context }
+
+func (c *testCommand) Context() context.Context       { return c.ctx }
+func (c *testCommand) SetContext(ctx context.Context) { c.ctx = ctx }
+
+func TestInitCommand(t *testing.T) {
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	buf := &bufferSyncer{}
+	cmd := &testCommand{}
+	log, err := InitCommand(cmd, Config{DisableStdOut: true, Encoding: EncodingJSON, Outputs: []zapcore.WriteSyncer{buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if L() != log || cmd.Context() == nil {
+		t.Fatal("want the global logger and the command context set")
+	}
+
+	FromContext(cmd.Context()).Info("from command")
+	if err := ShutdownCLI(cmd.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"msg":"from command"`)) {
+		t.Errorf("unexpected output: %s", buf.Bytes())
+	}
+
+	if _, err := InitCommand(&testCommand{ctx: context.Background()}, Config{Level: "verbose"}); err == nil {
+		t.Error("want an invalid level error")
+	}
+}
@@ -0,0 +1,277 @@
+// Command logtail is a debugging console for the logger output. It follows a live-tail endpoint
+// (EntryBus.TailHandler) or files written with the JSON or console encoding, renders entries
+// in the pretty format and takes commands from stdin:
+//
+//	level <level>   show entries of the level and above
+//	/<terms>        search by message and field substrings or key=value, "/" clears the search
+//	p               pause or resume, new entries are held while paused
+//	b [n]           scroll back the last n (20 by default) matching entries
+//	q               quit
+//
+// Usage:
+//
+//	logtail -url http://localhost:8080/debug/tail
+//	logtail -level warn -search user=bob app.log
+//
+// Files written with Config.FileIndex are read from the -since time using the index
+// and can be checked for truncation and corruption with -verify
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/query"
+)
+
+func main() {
+	var (
+		tailURL    = flag.String("url", "", "live-tail endpoint URL")
+		level      = flag.String("level", "", "minimum level of shown entries")
+		search     = flag.String("search", "", "search terms, see the /<terms> command")
+		scrollback = flag.Int("scrollback", 10000, "number of entries kept for scrollback")
+		noColor    = flag.Bool("no-color", false, "disable colors")
+		interval   = flag.Duration("interval", 500*time.Millisecond, "file polling interval")
+		since      = flag.Duration("since", 0, "skip indexed file entries older than the duration")
+		verify     = flag.Bool("verify", false, "verify indexed files and exit")
+	)
+	flag.Parse()
+
+	if *verify {
+		if !verifyFiles(os.Stdout, flag.Args()) {
+			os.Exit(1)
+		}
+		return
+	}
+	var from time.Time
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	}
+	if err := run(*tailURL, flag.Args(), *level, *search, *scrollback, !*noColor, *interval, from); err != nil {
+		fmt.Fprintln(os.Stderr, "logtail:", err)
+		os.Exit(1)
+	}
+}
+
+func run(tailURL string, files []string, level, search string, scrollback int, color bool, interval time.Duration, since time.Time) error {
+	if (tailURL == "") == (len(files) == 0) {
+		return errors.New("either -url or files must be passed")
+	}
+	f, err := parseFilter(level, search)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	records := make(chan query.Record, 1000)
+	errs := make(chan error, len(files)+1)
+	if tailURL != "" {
+		go func() { errs <- followURL(ctx, tailURL, records) }()
+	}
+	for _, path := range files {
+		path := path
+		go func() { errs <- followFile(ctx, path, interval, since, records) }()
+	}
+
+	commands := make(chan string)
+	go readCommands(os.Stdin, commands)
+
+	v := newView(os.Stdout, color, f, scrollback)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if err != nil {
+				return err
+			}
+		case r := <-records:
+			v.add(r)
+		case cmd, ok := <-commands:
+			if !ok {
+				// stdin is closed (e.g. redirected), keep following without commands
+				commands = nil
+				continue
+			}
+			if quit := execute(v, cmd); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func readCommands(in io.Reader, commands chan<- string) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		commands <- strings.TrimSpace(scanner.Text())
+	}
+	close(commands)
+}
+
+// execute executes a console command, see the package doc
+func execute(v *view, cmd string) (quit bool) {
+	name, arg, _ := strings.Cut(cmd, " ")
+	switch {
+	case cmd == "":
+	case cmd == "q":
+		return true
+	case cmd == "p":
+		v.setPaused(!v.paused)
+	case name == "level":
+		f, err := parseFilter(strings.TrimSpace(arg), strings.Join(v.filter.terms, " "))
+		if err != nil {
+			v.notice(err.Error())
+			return false
+		}
+		v.filter = f
+		v.notice("level " + f.level.String())
+	case strings.HasPrefix(cmd, "/"):
+		v.filter.terms = strings.Fields(cmd[1:])
+		v.notice("search " + strings.Join(v.filter.terms, " "))
+	case name == "b":
+		n := 20
+		if arg != "" {
+			var err error
+			if n, err = strconv.Atoi(strings.TrimSpace(arg)); err != nil {
+				v.notice("invalid number " + arg)
+				return false
+			}
+		}
+		v.notice("scrollback")
+		for _, r := range v.last(n) {
+			v.print(r)
+		}
+		v.notice("end of scrollback")
+	default:
+		v.notice("commands: level <level>, /<terms>, p, b [n], q")
+	}
+	return false
+}
+
+// followURL streams JSON lines from a live-tail endpoint
+func followURL(ctx context.Context, tailURL string, records chan<- query.Record) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tailURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := scanLines(ctx, resp.Body, records); err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, "failed to read")
+	}
+	if ctx.Err() == nil {
+		return errors.New("live-tail stream closed")
+	}
+	return nil
+}
+
+// verifyFiles checks the files against their indexes, see logger.VerifyFileIndex
+func verifyFiles(out io.Writer, files []string) (ok bool) {
+	ok = true
+	for _, path := range files {
+		if err := logger.VerifyFileIndex(path); err != nil {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			ok = false
+			continue
+		}
+		fmt.Fprintf(out, "%s: ok\n", path)
+	}
+	return ok
+}
+
+// followFile reads the file from the since time if it's indexed and polls it for appended lines.
+// The file is reread if it's truncated or rotated
+func followFile(ctx context.Context, path string, interval time.Duration, since time.Time, records chan<- query.Record) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open")
+	}
+	defer func() { _ = file.Close() }()
+
+	var offset int64
+	if !since.IsZero() {
+		if blocks, err := logger.ReadFileIndex(path); err == nil {
+			if offset, err = file.Seek(logger.SeekFileIndex(blocks, since), io.SeekStart); err != nil {
+				return errors.Wrap(err, "failed to seek")
+			}
+		}
+	}
+	reader := bufio.NewReader(file)
+	var partial string
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			select {
+			case records <- query.ParseLine(partial + line):
+			case <-ctx.Done():
+				return nil
+			}
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		partial += line
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		if rotated(file, path, offset) {
+			_ = file.Close()
+			if file, err = os.Open(path); err != nil {
+				return errors.Wrap(err, "failed to reopen")
+			}
+			reader.Reset(file)
+			offset, partial = 0, ""
+		}
+	}
+}
+
+// rotated reports whether the file at the path is truncated or replaced with another one
+func rotated(file *os.File, path string, offset int64) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	current, err := file.Stat()
+	return info.Size() < offset || err == nil && !os.SameFile(info, current)
+}
+
+func scanLines(ctx context.Context, r io.Reader, records chan<- query.Record) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		select {
+		case records <- query.ParseLine(scanner.Text()):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
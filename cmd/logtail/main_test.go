@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/query"
+)
+
+func TestFollowFileSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.New(logger.Config{DisableStdOut: true, Encoding: logger.EncodingJSON, Files: []string{path}, FileIndex: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("indexed")
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if !verifyFiles(out, []string{path}) || !strings.Contains(out.String(), "ok") {
+		t.Errorf("want a verified file, got %q", out.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records := make(chan query.Record, 10)
+	go func() { _ = followFile(ctx, path, time.Millisecond, time.Now().Add(time.Minute), records) }()
+	select {
+	case r := <-records:
+		t.Errorf("want entries before the since time skipped, got %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	go func() { _ = followFile(ctx, path, time.Millisecond, time.Now().Add(-time.Minute), records) }()
+	select {
+	case r := <-records:
+		if r.Message != "indexed" {
+			t.Errorf("unexpected record %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Error("want the indexed entry")
+	}
+
+	if err := os.Truncate(path, 5); err != nil {
+		t.Fatal(err)
+	}
+	if verifyFiles(out, []string{path}) {
+		t.Error("want a truncated file detected")
+	}
+}
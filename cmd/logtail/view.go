@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger/query"
+)
+
+// filter selects the shown records by minimum level and search terms
+type filter struct {
+	level zapcore.Level
+	// terms are "key=value" field matches or substrings of the message and field values, all must match
+	terms []string
+}
+
+func parseFilter(level, search string) (filter, error) {
+	f := filter{level: zapcore.DebugLevel}
+	if level != "" {
+		if err := f.level.UnmarshalText([]byte(level)); err != nil {
+			return f, errors.Errorf("unknown level %q", level)
+		}
+	}
+	f.terms = strings.Fields(search)
+	return f, nil
+}
+
+func (f filter) match(r query.Record) bool {
+	if r.Level < f.level {
+		return false
+	}
+	for _, term := range f.terms {
+		if !matchTerm(r, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchTerm(r query.Record, term string) bool {
+	if key, value, ok := strings.Cut(term, "="); ok && key != "" {
+		v, found := r.Fields[key]
+		return found && fmt.Sprint(v) == value
+	}
+	if strings.Contains(r.Message, term) || strings.Contains(r.Logger, term) {
+		return true
+	}
+	for _, v := range r.Fields {
+		if strings.Contains(fmt.Sprint(v), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// view prints records to the terminal, keeping the last ones for scrollback.
+// New records are held while it's paused and printed on resume
+type view struct {
+	out    io.Writer
+	color  bool
+	filter filter
+
+	history []query.Record // ring buffer of the last records
+	next    int
+	full    bool
+
+	paused bool
+	held   []query.Record
+}
+
+func newView(out io.Writer, color bool, f filter, scrollback int) *view {
+	if scrollback < 1 {
+		scrollback = 1
+	}
+	return &view{out: out, color: color, filter: f, history: make([]query.Record, scrollback)}
+}
+
+func (v *view) add(r query.Record) {
+	v.history[v.next] = r
+	v.next = (v.next + 1) % len(v.history)
+	v.full = v.full || v.next == 0
+
+	if !v.filter.match(r) {
+		return
+	}
+	if v.paused {
+		v.held = append(v.held, r)
+		return
+	}
+	v.print(r)
+}
+
+// last returns up to n last records matching the filter, oldest first
+func (v *view) last(n int) []query.Record {
+	var matched []query.Record
+	for i := 1; i <= len(v.history) && len(matched) < n; i++ {
+		idx := (v.next - i + len(v.history)) % len(v.history)
+		if !v.full && idx >= v.next {
+			break
+		}
+		if r := v.history[idx]; v.filter.match(r) {
+			matched = append(matched, r)
+		}
+	}
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched
+}
+
+func (v *view) setPaused(paused bool) {
+	v.paused = paused
+	if paused {
+		v.notice("paused, new entries are held")
+		return
+	}
+	held := v.held
+	v.held = nil
+	v.notice(fmt.Sprintf("resumed, %d entries held", len(held)))
+	for _, r := range held {
+		v.print(r)
+	}
+}
+
+func (v *view) notice(msg string) {
+	fmt.Fprintln(v.out, v.colorize("90", "-- "+msg+" --"))
+}
+
+func (v *view) print(r query.Record) {
+	fmt.Fprintln(v.out, v.render(r))
+}
+
+// render renders the record as the pretty encoding: time, level, logger, caller, message and key=value fields.
+// Control characters are escaped, so entries can't move the cursor or change colors of the terminal
+func (v *view) render(r query.Record) string {
+	if r.Raw != "" {
+		return escapeControl(r.Raw)
+	}
+
+	var b strings.Builder
+	if !r.Time.IsZero() {
+		b.WriteString(r.Time.Format("15:04:05.000 "))
+	}
+	b.WriteString(v.colorize(levelColor(r.Level), fmt.Sprintf("%-5s", r.Level.CapitalString())))
+	if r.Logger != "" {
+		b.WriteString(" " + escapeControl(r.Logger))
+	}
+	if r.Caller != "" {
+		b.WriteString(" " + v.colorize("90", escapeControl(r.Caller)))
+	}
+	b.WriteString(" " + escapeControl(r.Message))
+
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(" " + v.colorize("36", escapeControl(k)) + "=" + escapeControl(fmt.Sprint(r.Fields[k])))
+	}
+	return b.String()
+}
+
+// escapeControl escapes control characters except tabs and Unicode line separators like Go literals, e.g. `\x1b`
+func escapeControl(s string) string {
+	unsafe := func(r rune) bool { return unicode.IsControl(r) && r != '\t' || r == '\u2028' || r == '\u2029' }
+	if strings.IndexFunc(s, unsafe) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if unsafe(r) {
+			b.WriteString(strings.Trim(strconv.QuoteRune(r), "'"))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (v *view) colorize(color, s string) string {
+	if !v.color {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+func levelColor(lvl zapcore.Level) string {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return "35"
+	case zapcore.InfoLevel:
+		return "34"
+	case zapcore.WarnLevel:
+		return "33"
+	default:
+		return "31"
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger/query"
+)
+
+func TestView(t *testing.T) {
+	f, err := parseFilter("info", "user=bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	v := newView(out, false, f, 3)
+
+	bob := map[string]interface{}{"user": "bob"}
+	v.add(query.Record{Level: zapcore.DebugLevel, Message: "debug", Fields: bob})
+	v.add(query.Record{Level: zapcore.InfoLevel, Message: "other user", Fields: map[string]interface{}{"user": "alice"}})
+	v.add(query.Record{Level: zapcore.InfoLevel, Message: "login", Fields: bob})
+	if got := out.String(); got != "INFO  login user=bob\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	out.Reset()
+	execute(v, "p")
+	v.add(query.Record{Level: zapcore.WarnLevel, Message: "slow", Fields: bob})
+	if strings.Contains(out.String(), "slow") {
+		t.Errorf("entries must be held while paused: %q", out.String())
+	}
+	execute(v, "p")
+	if !strings.Contains(out.String(), "WARN  slow user=bob") {
+		t.Errorf("want the held entries on resume, got %q", out.String())
+	}
+
+	execute(v, "/")
+	execute(v, "level debug")
+	if got := v.last(10); len(got) != 3 || got[0].Message != "other user" || got[2].Message != "slow" {
+		t.Errorf("want the last 3 entries in the scrollback, got %+v", got)
+	}
+	if quit := execute(v, "q"); !quit {
+		t.Error("want quit")
+	}
+}
+
+func TestRenderEscapesControlCharacters(t *testing.T) {
+	v := newView(&bytes.Buffer{}, false, filter{}, 1)
+
+	got := v.render(query.Record{Level: zapcore.InfoLevel, Message: "login\x1b[2J", Fields: map[string]interface{}{"user": "bob\nINFO forged"}})
+	if want := `INFO  login\x1b[2J user=bob\nINFO forged`; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got := v.render(query.Record{Raw: "raw\u009b2J\ttext"}); got != `raw\u009b2J`+"\ttext" {
+		t.Errorf("unexpected raw line %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// messageColor returns the color of messages of the level, see Config.ColorMessages.
+// Info messages aren't colored, so warnings and errors stand out
+func messageColor(lvl zapcore.Level) string {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return "90"
+	case zapcore.InfoLevel:
+		return ""
+	case zapcore.WarnLevel:
+		return "33"
+	default:
+		return "31"
+	}
+}
+
+// colorMessage returns the message wrapped in the color of the level
+func colorMessage(lvl zapcore.Level, msg string) string {
+	color := messageColor(lvl)
+	if color == "" || msg == "" {
+		return msg
+	}
+	return "\x1b[" + color + "m" + msg + "\x1b[0m"
+}
+
+// messageColorEncoder colors messages of a console encoder by level
+type messageColorEncoder struct {
+	zapcore.Encoder
+}
+
+func (e messageColorEncoder) Clone() zapcore.Encoder {
+	return messageColorEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e messageColorEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	ent.Message = colorMessage(ent.Level, ent.Message)
+	return e.Encoder.EncodeEntry(ent, fields)
+}
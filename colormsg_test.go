@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestColorMessages(t *testing.T) {
+	for _, encoding := range []Encoding{EncodingConsole, EncodingPretty} {
+		buf := &bytes.Buffer{}
+		log := newLogger(t, Config{DisableStdOut: true, Encoding: encoding, ColorMessages: true, Outputs: []zapcore.WriteSyncer{zapcore.AddSync(buf)}})
+		log.Info("plain")
+		log.Warn("warning")
+		log.Error("failure")
+
+		out := buf.String()
+		if strings.Contains(out, "plain\x1b[0m") {
+			t.Errorf("%s: info messages must not be colored: %q", encoding, out)
+		}
+		if !strings.Contains(out, "\x1b[33mwarning") || !strings.Contains(out, "\x1b[31mfailure") {
+			t.Errorf("%s: want colored warning and error messages, got %q", encoding, out)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, ColorMessages: true, Outputs: []zapcore.WriteSyncer{zapcore.AddSync(buf)}})
+	log.Error("failure")
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("DisableColor must disable message colors, got %q", buf.String())
+	}
+}
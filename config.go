@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"reflect"
+)
+
+// DefaultConfig returns the config with the defaults New applies to unset fields, e.g. the console encoding
+// and the queue sizes. It's a base layer for Merge:
+//
+//	cfg := logger.DefaultConfig().Merge(fileCfg).Merge(envCfg).Merge(flagsCfg)
+func DefaultConfig() Config {
+	return Config{
+		Encoding:            EncodingConsole,
+		TimeLayout:          defaultTimeLayout,
+		FileSyncInterval:    defaultFileSyncInterval,
+		OutputQueueSize:     defaultOutputQueueSize,
+		LevelFileInterval:   defaultLevelFileInterval,
+		LevelSourceInterval: defaultLevelSourceInterval,
+		Async: AsyncConfig{
+			QueueSize:          defaultAsyncQueueSize,
+			DropNoticeInterval: defaultDropNoticeInterval,
+		},
+		Sampling:       SamplingConfig{Tick: defaultSamplingTick},
+		StartupMessage: defaultStartupMessage,
+	}
+}
+
+// Merge returns the config with the set fields of the overrides applied on top of it:
+//   - non-zero fields replace the config ones, so zero values (false, "", 0, nil) mean "not set"
+//     and can't reset a field, e.g. a layer can enable DisableColor but can't disable it;
+//   - slices (Files, Outputs, Cores, ZapOptions) replace the config ones if they're not empty;
+//   - maps (Sinks, SinkGroups, LoggerSinks, PackageLevels) are merged by key, the overrides win;
+//   - nested configs (Async, Sampling, ErrorSummary, Offload, ...) are merged field by field by the same rules.
+//
+// Neither config is modified
+func (cfg Config) Merge(overrides Config) Config {
+	merged := reflect.New(reflect.TypeOf(cfg)).Elem()
+	merged.Set(reflect.ValueOf(cfg))
+	mergeValue(merged, reflect.ValueOf(overrides))
+	return merged.Interface().(Config)
+}
+
+// mergeValue merges the override into the settable value, see Config.Merge
+func mergeValue(dst, override reflect.Value) {
+	switch {
+	case dst.Kind() == reflect.Struct && hasExportedFields(dst.Type()):
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).IsExported() {
+				mergeValue(dst.Field(i), override.Field(i))
+			}
+		}
+	case dst.Kind() == reflect.Map:
+		if override.Len() == 0 {
+			if !dst.IsNil() {
+				dst.Set(copyMap(dst))
+			}
+			return
+		}
+		merged := copyMap(dst)
+		iter := override.MapRange()
+		for iter.Next() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		dst.Set(merged)
+	case dst.Kind() == reflect.Slice:
+		if override.Len() != 0 {
+			dst.Set(override)
+		}
+	default:
+		if !override.IsZero() {
+			dst.Set(override)
+		}
+	}
+}
+
+// hasExportedFields reports whether the struct has exported fields. Opaque structs (e.g. time.Time) are merged as a whole
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// copyMap returns a copy of the map, so merged configs don't share maps
+func copyMap(m reflect.Value) reflect.Value {
+	copied := reflect.MakeMapWithSize(m.Type(), m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		copied.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return copied
+}
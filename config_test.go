@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigMerge(t *testing.T) {
+	base := DefaultConfig()
+	base.Files = []string{"/var/log/app.log"}
+	base.PackageLevels = map[string]string{"db": "debug", "http": "info"}
+
+	file := Config{
+		DisableColor:  true,
+		Encoding:      EncodingJSON,
+		PackageLevels: map[string]string{"http": "warn"},
+		Async:         AsyncConfig{Enabled: true},
+	}
+	flags := Config{
+		Files:   []string{"/tmp/app.log"},
+		Outputs: []zapcore.WriteSyncer{&bufferSyncer{}},
+		Async:   AsyncConfig{QueueSize: 10},
+	}
+
+	cfg := base.Merge(file).Merge(flags)
+	if !cfg.DisableColor || cfg.Encoding != EncodingJSON || cfg.TimeLayout != defaultTimeLayout {
+		t.Errorf("unexpected scalars: %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Files, []string{"/tmp/app.log"}) || len(cfg.Outputs) != 1 {
+		t.Errorf("want the slices replaced, got %v %v", cfg.Files, cfg.Outputs)
+	}
+	if want := map[string]string{"db": "debug", "http": "warn"}; !reflect.DeepEqual(cfg.PackageLevels, want) {
+		t.Errorf("want the maps merged %v, got %v", want, cfg.PackageLevels)
+	}
+	if want := (AsyncConfig{Enabled: true, QueueSize: 10, DropNoticeInterval: defaultDropNoticeInterval}); cfg.Async != want {
+		t.Errorf("want nested configs merged %+v, got %+v", want, cfg.Async)
+	}
+
+	cfg.PackageLevels["db"] = "error"
+	if base.PackageLevels["db"] != "debug" {
+		t.Error("merged configs must not share maps")
+	}
+
+	if got := (Config{}).Merge(Config{Heartbeat: HeartbeatConfig{Interval: time.Minute}}); got.Heartbeat.Interval != time.Minute {
+		t.Errorf("unexpected heartbeat: %+v", got.Heartbeat)
+	}
+	if _, err := New(DefaultConfig().Merge(Config{DisableStdOut: true})); err != nil {
+		t.Errorf("want a valid default config, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// ContextExtractor returns fields to log from application-specific context values,
+// e.g. user ID, tenant or request ID
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+var (
+	extractorsMu sync.Mutex
+	extractors   atomic.Value // []ContextExtractor
+)
+
+// RegisterContextExtractor adds an extractor used by Logger.Ctx and FromContext.
+// It's intended to be called on initialization, e.g. from init functions of packages owning context keys
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	current, _ := extractors.Load().([]ContextExtractor)
+	extractors.Store(append(append([]ContextExtractor(nil), current...), fn))
+}
+
+type loggerKey struct{}
+
+// ToContext returns a copy of ctx carrying the logger, see FromContext
+func ToContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored by ToContext or the global one,
+// with the fields from the registered context extractors
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok {
+		l = L()
+	}
+	return l.Ctx(ctx)
+}
+
+// Ctx returns a logger with the fields from the registered context extractors, see RegisterContextExtractor.
+// With Config.SpanRecorder the context is added to the logger unless it's the one the logger already has
+// or the recorder reports it has no span, see SpanFinder
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	current, _ := extractors.Load().([]ContextExtractor)
+
+	var fields []zap.Field
+	for _, extract := range current {
+		fields = append(fields, mapToFields(extract(ctx))...)
+	}
+	withSpan := l != nil && l.out != nil && l.out.spans != nil && ctx != l.spanCtx && hasSpan(ctx, l.out.spans)
+	if withSpan {
+		fields = append(fields, spanContextField(ctx))
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	clone := l.withFields(fields...)
+	if withSpan {
+		clone.spanCtx = ctx
+	}
+	return clone
+}
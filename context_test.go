@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return map[string]interface{}{"tenant": tenant}
+		}
+		return nil
+	})
+
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	ctx := ToContext(context.WithValue(context.Background(), tenantKey{}, "acme"), log)
+
+	FromContext(ctx).Info("from context")
+	log.Ctx(context.Background()).Info("without tenant")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(entries))
+	}
+	if tenant := entries[0].ContextMap()["tenant"]; tenant != "acme" {
+		t.Errorf("want tenant acme, got %v", tenant)
+	}
+	if _, ok := entries[1].ContextMap()["tenant"]; ok {
+		t.Error("want no tenant")
+	}
+}
+
+func TestFromContextGlobal(t *testing.T) {
+	if FromContext(context.Background()) == nil {
+		t.Error("want the global logger")
+	}
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// deadLetterReasonKey is the field with the write error added to dead-letter entries
+const deadLetterReasonKey = "dead_letter_reason"
+
+// deadLetterFile stores entries that failed to be written to all the outputs as JSON lines
+type deadLetterFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	enc  zapcore.Encoder
+}
+
+func openDeadLetterFile(path string) (*deadLetterFile, error) {
+	d := &deadLetterFile{path: path, enc: zapcore.NewJSONEncoder(jsonEncoderConfig())}
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *deadLetterFile) open() error {
+	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+	if err != nil {
+		return errors.Wrap(err, "failed to open dead-letter file")
+	}
+	d.file = file
+	return nil
+}
+
+func (d *deadLetterFile) write(ent zapcore.Entry, fields []zapcore.Field, reason error) error {
+	fields = append(fields[:len(fields):len(fields)], zap.String(deadLetterReasonKey, reason.Error()))
+	buf, err := d.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode dead-letter entry")
+	}
+	defer buf.Free()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err = d.file.Write(buf.Bytes())
+	return errors.Wrap(err, "failed to write dead-letter entry")
+}
+
+// take moves the stored entries aside, so they can be re-driven while new ones are being added.
+// The current file keeps being used if the new one can't be opened
+func (d *deadLetterFile) take() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	taken := d.path + ".redrive." + time.Now().Format("20060102T150405.000000000")
+	if err := os.Rename(d.path, taken); err != nil {
+		return "", errors.Wrap(err, "failed to rename dead-letter file")
+	}
+	previous := d.file
+	if err := d.open(); err != nil {
+		// The open file is still the renamed one, so moving it back keeps the entries in place
+		return "", multierr.Append(err, errors.Wrap(os.Rename(taken, d.path), "failed to restore dead-letter file"))
+	}
+	if err := previous.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to close dead-letter file")
+	}
+	return taken, nil
+}
+
+func (d *deadLetterFile) Sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.file.Sync()
+}
+
+func (d *deadLetterFile) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.file.Close()
+}
+
+// RedriveDeadLetters logs the entries from Config.DeadLetterFile again and removes them from the file.
+// Entries that fail again are added back by the logger. It returns the number of re-driven entries
+func (l *Logger) RedriveDeadLetters() (int, error) {
+	if l == nil || l.out == nil || l.out.deadLetter == nil {
+		return 0, errors.New("dead-letter file isn't configured")
+	}
+
+	path, err := l.out.deadLetter.take()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := readDeadLetters(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// Written entry by entry, so the entries failing again are stored by deadLetterCore
+	l.writeEntries(entries)
+	return len(entries), errors.Wrap(os.Remove(path), "failed to remove re-driven entries")
+}
+
+// readDeadLetters parses a dead-letter file
+func readDeadLetters(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dead-letter file")
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scan := bufio.NewScanner(file)
+	scan.Buffer(nil, 16<<20)
+	for scan.Scan() {
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(scan.Bytes(), &fields); err != nil {
+			return nil, errors.Wrap(err, "failed to parse dead-letter entry")
+		}
+
+		var e Entry
+		if ts, ok := fields["ts"].(string); ok {
+			e.Time, _ = time.Parse(time.RFC3339Nano, ts)
+		}
+		if lvl, ok := fields["level"].(string); ok {
+			e.Level, _ = parseLevel(lvl)
+		}
+		e.LoggerName, _ = fields["logger"].(string)
+		e.Message, _ = fields["msg"].(string)
+		for _, key := range []string{"ts", "level", "logger", "caller", "msg", "stacktrace", deadLetterReasonKey} {
+			delete(fields, key)
+		}
+		e.Fields = fields
+		entries = append(entries, e)
+	}
+	return entries, errors.Wrap(scan.Err(), "failed to read dead-letter file")
+}
+
+// deadLetterCore writes entries to the output cores and stores them in the dead-letter file
+// if all the outputs that accepted an entry failed to write it
+type deadLetterCore struct {
+	cores      []zapcore.Core
+	fields     []zapcore.Field
+	deadLetter *deadLetterFile
+}
+
+func (c *deadLetterCore) Enabled(lvl zapcore.Level) bool {
+	for _, core := range c.cores {
+		if core.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *deadLetterCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &deadLetterCore{
+		cores:      make([]zapcore.Core, len(c.cores)),
+		fields:     append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		deadLetter: c.deadLetter,
+	}
+	for i, core := range c.cores {
+		clone.cores[i] = core.With(fields)
+	}
+	return clone
+}
+
+func (c *deadLetterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *deadLetterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var err error
+	attempted, delivered := false, false
+	for _, core := range c.cores {
+		if core.Check(ent, nil) == nil {
+			continue
+		}
+		attempted = true
+		if writeErr := core.Write(ent, fields); writeErr != nil {
+			err = multierr.Append(err, writeErr)
+			continue
+		}
+		delivered = true
+	}
+
+	if attempted && !delivered {
+		err = multierr.Append(err, c.deadLetter.write(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...), err))
+	}
+	return err
+}
+
+func (c *deadLetterCore) Sync() error {
+	var err error
+	for _, core := range c.cores {
+		err = multierr.Append(err, core.Sync())
+	}
+	return multierr.Append(err, c.deadLetter.Sync())
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDeadLetter(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "app.log")[0])
+	deadLetters := filepath.Join(dir, "dead.log")
+	first, second := &bufferSyncer{}, &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, Outputs: []zapcore.WriteSyncer{first, second}, DeadLetterFile: deadLetters})
+
+	first.setErr(errors.New("first is down"))
+	log.Info("delivered")
+	second.setErr(errors.New("second is down"))
+	log.WithField("a", 1).Warn("undeliverable")
+
+	checkFileLogs(t, deadLetters, [][]string{{`"level":"warn"`, `"msg":"undeliverable"`, `"a":1`, `"dead_letter_reason":"first is down; second is down"`}})
+
+	first.setErr(nil)
+	n, err := log.RedriveDeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("want 1 re-driven entry, got %d", n)
+	}
+	if got := string(first.Bytes()); !strings.Contains(got, "undeliverable") || !strings.Contains(got, `"a": 1`) || strings.Contains(got, "dead_letter_reason") {
+		t.Errorf("unexpected re-driven entry: %s", got)
+	}
+	if data := readFile(t, deadLetters); len(data) != 0 {
+		t.Errorf("want empty dead-letter file, got %s", data)
+	}
+}
+
+func TestRedriveDeadLettersNotConfigured(t *testing.T) {
+	if _, err := newLogger(t, Config{DisableStdOut: true}).RedriveDeadLetters(); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestDeadLetterConcurrentOutputs(t *testing.T) {
+	path := createTempFiles(t, "dead.log")[0]
+	if _, err := New(Config{DisableStdOut: true, DeadLetterFile: path, ConcurrentOutputs: true}); err == nil {
+		t.Error("want an error")
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// defaultNearDeadline is the remaining time CheckCtx considers near the deadline
+const defaultNearDeadline = time.Second
+
+// CheckCtx returns a logger with the ctx_err field if the context is done, and the ctx_deadline_in field
+// with the remaining time if its deadline is less than a second away, aiding diagnosis of timeout cascades.
+// Register DeadlineExtractor to add the fields in Ctx and FromContext
+func (l *Logger) CheckCtx(ctx context.Context) *Logger {
+	fields := deadlineFields(ctx, defaultNearDeadline)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.withFields(mapToFields(fields)...)
+}
+
+// DeadlineExtractor returns a context extractor adding the CheckCtx fields, with the deadline considered near
+// when it's less than near away:
+//
+//	logger.RegisterContextExtractor(logger.DeadlineExtractor(500 * time.Millisecond))
+func DeadlineExtractor(near time.Duration) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		return deadlineFields(ctx, near)
+	}
+}
+
+func deadlineFields(ctx context.Context, near time.Duration) map[string]interface{} {
+	var fields map[string]interface{}
+	if err := ctx.Err(); err != nil {
+		fields = map[string]interface{}{"ctx_err": err.Error()}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < near {
+			if fields == nil {
+				fields = make(map[string]interface{}, 1)
+			}
+			fields["ctx_deadline_in"] = remaining
+		}
+	}
+	return fields
+}
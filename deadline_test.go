@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckCtx(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	far, cancelFar := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelFar()
+	near, cancelNear := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelNear()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	log.CheckCtx(far).Info("far")
+	log.CheckCtx(near).Info("near")
+	log.CheckCtx(canceled).Info("canceled")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+	if fields := entries[0].ContextMap(); len(fields) != 0 {
+		t.Errorf("want no fields far from the deadline, got %v", fields)
+	}
+	if in, ok := entries[1].ContextMap()["ctx_deadline_in"].(time.Duration); !ok || in <= 0 || in > 100*time.Millisecond {
+		t.Errorf("unexpected fields: %v", entries[1].ContextMap())
+	}
+	if fields := entries[2].ContextMap(); fields["ctx_err"] != "context canceled" || fields["ctx_deadline_in"] != nil {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestDeadlineExtractor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	fields := DeadlineExtractor(time.Second)(ctx)
+	if fields["ctx_err"] != "context deadline exceeded" || fields["ctx_deadline_in"].(time.Duration) > -time.Second {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"hash/fnv"
+
+	"go.uber.org/zap"
+)
+
+// inDebugSample reports whether the ID falls into the percentage of IDs by its hash.
+// The hash is stable, so all services sharing a request ID make the same decision
+func inDebugSample(id string, percent int) bool {
+	if id == "" || percent <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return int(h.Sum32()%100) < percent
+}
+
+// WithDebugSample returns a logger at debug level tagged with debug_sampled=true for a deterministic percentage (0-100)
+// of IDs, e.g. request IDs, continuously sampling detailed traces with bounded volume. Other IDs get the logger as is
+func (l *Logger) WithDebugSample(id string, percent int) *Logger {
+	if !inDebugSample(id, percent) {
+		return l
+	}
+	return l.WithMinLevel("debug").withFields(zap.Bool("debug_sampled", true))
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestInDebugSample(t *testing.T) {
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		id := "req-" + strconv.Itoa(i)
+		if inDebugSample(id, 10) {
+			sampled++
+		}
+		if inDebugSample(id, 10) != inDebugSample(id, 10) || inDebugSample(id, 0) || !inDebugSample(id, 100) {
+			t.Fatalf("unexpected decision for %s", id)
+		}
+	}
+	if sampled < 800 || sampled > 1200 {
+		t.Errorf("want about 10%% of IDs sampled, got %d of 10000", sampled)
+	}
+	if inDebugSample("", 100) {
+		t.Error("want empty IDs not sampled")
+	}
+}
+
+func TestHTTPMiddlewareDebugPercent(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("info")
+
+	handler := log.HTTPMiddleware(HTTPConfig{DebugPercent: 50, DisableAccessLog: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Debug("details")
+	}))
+
+	want := 0
+	for i := 0; i < 20; i++ {
+		id := strconv.Itoa(i)
+		if inDebugSample(id, 50) {
+			want++
+		}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Request-ID", id)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if want == 0 || len(entries) != want {
+		t.Fatalf("want %d sampled debug entries, got %d", want, len(entries))
+	}
+	if entries[0].ContextMap()["debug_sampled"] != true {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+}
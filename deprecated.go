@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"runtime"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// deprecatedCallSites holds call sites already reported by Logger.Deprecated
+var deprecatedCallSites sync.Map
+
+type deprecatedCallSite struct {
+	name string
+	pc   uintptr
+}
+
+// Deprecated logs a warning about usage of the deprecated name once per call site.
+// It's meant to be called by the deprecated function itself, so the call site
+// and the reported caller are the ones of the deprecated function:
+//
+//	func OldFunc() {
+//		log.Deprecated("OldFunc", "use NewFunc")
+//		...
+//	}
+func (l *Logger) Deprecated(name, hint string) {
+	pc, _, _, ok := runtime.Caller(2)
+	if ok {
+		if _, reported := deprecatedCallSites.LoadOrStore(deprecatedCallSite{name: name, pc: pc}, struct{}{}); reported {
+			return
+		}
+	}
+
+	if ce := l.base().WithOptions(zap.AddCallerSkip(1)).Check(WarnLevel, name+" is deprecated"); ce != nil {
+		ce.Write(zap.String("deprecated", name), zap.String("hint", hint))
+	}
+}
@@ -0,0 +1,20 @@
+package logger
+
+import "testing"
+
+func TestDeprecated(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}})
+
+	oldFunc := func() { log.Deprecated("OldFunc", "use NewFunc") }
+
+	for i := 0; i < 3; i++ {
+		oldFunc() // reported once
+	}
+	oldFunc() // another call site
+
+	checkFileLogs(t, filename, [][]string{
+		{"deprecated_test.go:12", `OldFunc is deprecated	{"deprecated": "OldFunc", "hint": "use NewFunc"}`},
+		{"deprecated_test.go:14", "OldFunc is deprecated"},
+	})
+}
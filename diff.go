@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// Diff logs the changed paths between two values (maps, structs or slices of them) at Info level,
+// e.g. on a configuration reload:
+//
+//	log.Diff("config changed", oldCfg, newCfg)
+//	// config changed {"diff": {"db.pool": {"old": 10, "new": 20}, "features[1]": {"new": "beta"}}}
+//
+// Values are compared in their JSON form, so json tags name the paths and unexported fields are ignored.
+// Added paths have no old value, removed ones have no new value. Nothing is logged if there are no changes
+func (l *Logger) Diff(msg string, oldVal, newVal interface{}) {
+	ce := l.base().Check(InfoLevel, msg)
+	if ce == nil {
+		return
+	}
+
+	oldTree, err := jsonTree(oldVal)
+	if err == nil {
+		var newTree interface{}
+		if newTree, err = jsonTree(newVal); err == nil {
+			changes := make(map[string]interface{})
+			diffTrees("", oldTree, newTree, changes)
+			if len(changes) == 0 {
+				return
+			}
+			ce.Write(zap.Any("diff", changes))
+			return
+		}
+	}
+	ce.Write(zap.NamedError("diff_error", err))
+}
+
+// jsonTree converts the value to maps, slices and scalars of its JSON form
+func jsonTree(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var tree interface{}
+	return tree, dec.Decode(&tree)
+}
+
+// diffTrees adds the changed paths of the trees to changes
+func diffTrees(path string, before, after interface{}, changes map[string]interface{}) {
+	switch oldV := before.(type) {
+	case map[string]interface{}:
+		if newV, ok := after.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(oldV)+len(newV))
+			for k := range oldV {
+				keys = append(keys, k)
+			}
+			for k := range newV {
+				if _, ok := oldV[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				diffTrees(joinPath(path, k), oldV[k], newV[k], changes)
+			}
+			return
+		}
+	case []interface{}:
+		if newV, ok := after.([]interface{}); ok {
+			n := len(oldV)
+			if len(newV) > n {
+				n = len(newV)
+			}
+			for i := 0; i < n; i++ {
+				var b, a interface{}
+				if i < len(oldV) {
+					b = oldV[i]
+				}
+				if i < len(newV) {
+					a = newV[i]
+				}
+				diffTrees(path+"["+strconv.Itoa(i)+"]", b, a, changes)
+			}
+			return
+		}
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	change := make(map[string]interface{}, 2)
+	if before != nil {
+		change["old"] = before
+	}
+	if after != nil {
+		change["new"] = after
+	}
+	if path == "" {
+		path = "."
+	}
+	changes[path] = change
+}
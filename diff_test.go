@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	type db struct {
+		Host string `json:"host"`
+		Pool int    `json:"pool"`
+	}
+	type config struct {
+		DB       db                `json:"db"`
+		Features []string          `json:"features"`
+		Labels   map[string]string `json:"labels"`
+	}
+	oldCfg := config{DB: db{Host: "db1", Pool: 10}, Features: []string{"a"}, Labels: map[string]string{"env": "prod", "team": "x"}}
+	newCfg := config{DB: db{Host: "db1", Pool: 20}, Features: []string{"a", "beta"}, Labels: map[string]string{"env": "prod"}}
+
+	log.Diff("config changed", oldCfg, newCfg)
+	log.Diff("unchanged", oldCfg, oldCfg)
+	log.Diff("invalid", func() {}, oldCfg)
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(entries))
+	}
+	want := map[string]interface{}{
+		"db.pool":     map[string]interface{}{"old": json.Number("10"), "new": json.Number("20")},
+		"features[1]": map[string]interface{}{"new": "beta"},
+		"labels.team": map[string]interface{}{"old": "x"},
+	}
+	if got := entries[0].ContextMap()["diff"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if entries[1].Message != "invalid" || entries[1].ContextMap()["diff_error"] == nil {
+		t.Errorf("unexpected entry: %+v", entries[1])
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// syncDir fsyncs the directory, so the files created or renamed in it survive power failures
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open directory")
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil && !isUnsyncable(err) {
+		return errors.Wrap(err, "failed to fsync directory")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+const defaultFileSyncInterval = time.Second
+
+// FileSyncMode is how often plain files are fsynced, see Config.FileSync
+type FileSyncMode string
+
+const (
+	// FileSyncNever leaves flushing written records to the OS
+	FileSyncNever FileSyncMode = ""
+	// FileSyncEveryEntry makes every write wait until the record is fsynced.
+	// Concurrent writes share a single fsync call, amortizing its cost
+	FileSyncEveryEntry FileSyncMode = "entry"
+	// FileSyncPeriodic fsyncs files every Config.FileSyncInterval,
+	// so up to the interval of records can be lost on power failure
+	FileSyncPeriodic FileSyncMode = "interval"
+)
+
+func (m FileSyncMode) valid() bool {
+	switch m {
+	case FileSyncNever, FileSyncEveryEntry, FileSyncPeriodic:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitDurable waits until the record with the sequence number is fsynced. The first waiting write fsyncs
+// all the records written so far, so the writes queued behind it return without their own fsync
+func (w *fileWriter) waitDurable(seq uint64) error {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	if w.synced.Load() >= seq {
+		return nil
+	}
+
+	w.mu.Lock()
+	file, written := w.file, w.written
+	w.mu.Unlock()
+
+	if err := file.Sync(); err != nil {
+		// The file is fsynced before it's closed by rotation
+		if errors.Is(err, os.ErrClosed) && w.synced.Load() >= seq {
+			return nil
+		}
+		return errors.Wrap(err, "failed to fsync file")
+	}
+	storeMax(&w.synced, written)
+	return nil
+}
+
+// storeMax stores the value if it's greater than the current one
+func storeMax(v *atomic.Uint64, n uint64) {
+	for {
+		current := v.Load()
+		if n <= current || v.CAS(current, n) {
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileSyncEveryEntry(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	w, err := openFileWriter(filename, fileOptions{sync: FileSyncEveryEntry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if _, err := w.Write([]byte("record\n")); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := w.Rotate(); err != nil {
+			t.Error(err)
+		}
+	}()
+	wg.Wait()
+
+	if written, synced := w.written, w.synced.Load(); written != 160 || synced != written {
+		t.Errorf("want all 160 records fsynced, got %d of %d", synced, written)
+	}
+}
+
+func TestFileSyncPeriodic(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, FileSync: FileSyncPeriodic, FileSyncInterval: 10 * time.Millisecond})
+	log.Info("message")
+
+	file := log.out.files[0]
+	for deadline := time.Now().Add(time.Second); file.synced.Load() != 1; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the file wasn't fsynced")
+		}
+	}
+
+	if _, err := New(Config{DisableStdOut: true, FileSync: "always"}); err == nil {
+		t.Error("want an unknown sync mode error")
+	}
+}
+
+func TestFileSyncPeriodicError(t *testing.T) {
+	errOut := captureStderr(t)
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, FileSync: FileSyncPeriodic, FileSyncInterval: time.Millisecond})
+	t.Cleanup(func() { _ = log.Shutdown(context.Background()) })
+
+	file := log.out.files[0]
+	file.mu.Lock()
+	_ = file.file.Close()
+	file.mu.Unlock()
+	for deadline := time.Now().Add(time.Second); !strings.Contains(string(errOut.Bytes()), "failed to fsync"); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("want the fsync error reported")
+		}
+	}
+}
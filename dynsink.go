@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// dynamicSinks are the sinks added with Logger.AddSink. They're shared between a logger and its clones
+type dynamicSinks struct {
+	cfg      Config
+	enc      zapcore.Encoder
+	static   map[string]bool // names of the outputs and groups from the config
+	bindings map[string]sinkTargets
+
+	mu  sync.Mutex   // serializes changes
+	set atomic.Value // *sinkSet
+}
+
+// sinkSet is an immutable set of dynamic sinks, replaced on every change
+type sinkSet struct {
+	sinks []*dynamicSink
+}
+
+type dynamicSink struct {
+	name string
+	core *routeCore
+	out  *outputs // the resources opened for the sink
+}
+
+func newDynamicSinks(cfg Config, enc zapcore.Encoder, opened []namedOutput) *dynamicSinks {
+	d := &dynamicSinks{cfg: cfg, enc: enc, static: make(map[string]bool), bindings: make(map[string]sinkTargets)}
+	for _, output := range opened {
+		d.static[output.name] = true
+	}
+	for name := range cfg.SinkGroups {
+		d.static[name] = true
+	}
+	for name, targets := range cfg.LoggerSinks {
+		d.bindings[name] = append(sinkTargets(nil), targets...)
+	}
+	d.set.Store(&sinkSet{})
+	return d
+}
+
+func (d *dynamicSinks) load() *sinkSet {
+	return d.set.Load().(*sinkSet)
+}
+
+func (d *dynamicSinks) add(name string, sink SinkConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if name == "" {
+		return errors.New("sink name is empty")
+	}
+	if d.static[name] || d.find(name) >= 0 {
+		return errors.Errorf("sink %q already exists", name)
+	}
+	opts, err := d.cfg.fileOptions()
+	if err != nil {
+		return err
+	}
+	enc, err := d.cfg.sinkEncoder(name, sink)
+	if err != nil {
+		return err
+	}
+	if enc == nil {
+		enc = d.enc.Clone()
+	}
+
+	out := &outputs{}
+	ws, err := out.openSink(name, sink, opts)
+	if err != nil {
+		_ = out.close()
+		return err
+	}
+	core := newOutputCore(enc, reportingSyncer{WriteSyncer: ws, name: name})
+
+	current := d.load()
+	sinks := append(current.sinks[:len(current.sinks):len(current.sinks)], &dynamicSink{
+		name: name,
+		core: &routeCore{Core: core, names: map[string]bool{name: true}, exclusive: sink.Exclusive, bindings: d.bindings},
+		out:  out,
+	})
+	d.set.Store(&sinkSet{sinks: sinks})
+	return nil
+}
+
+func (d *dynamicSinks) remove(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.find(name)
+	if i < 0 {
+		return errors.Errorf("unknown sink %q", name)
+	}
+	current := d.load()
+	removed := current.sinks[i]
+	sinks := make([]*dynamicSink, 0, len(current.sinks)-1)
+	sinks = append(sinks, current.sinks[:i]...)
+	sinks = append(sinks, current.sinks[i+1:]...)
+	d.set.Store(&sinkSet{sinks: sinks})
+
+	return multierr.Append(removed.core.Sync(), removed.out.close())
+}
+
+// find returns the index of the sink, -1 if there's no such sink
+func (d *dynamicSinks) find(name string) int {
+	for i, sink := range d.load().sinks {
+		if sink.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// rotate rotates the files of the sinks
+func (d *dynamicSinks) rotate() (err error) {
+	for _, sink := range d.load().sinks {
+		err = multierr.Append(err, sink.out.rotate())
+	}
+	return err
+}
+
+// close closes all the sinks
+func (d *dynamicSinks) close() (err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, sink := range d.load().sinks {
+		err = multierr.Append(err, sink.out.close())
+	}
+	d.set.Store(&sinkSet{})
+	return err
+}
+
+// dynamicCore writes entries to the current dynamic sinks
+type dynamicCore struct {
+	sinks  *dynamicSinks
+	fields []zapcore.Field
+	cache  *atomic.Value // dynamicCores
+}
+
+// dynamicCores are the sink cores with the logger fields for a sink set
+type dynamicCores struct {
+	set   *sinkSet
+	cores []zapcore.Core
+}
+
+func newDynamicCore(sinks *dynamicSinks) *dynamicCore {
+	return &dynamicCore{sinks: sinks, cache: &atomic.Value{}}
+}
+
+// cores returns the cores of the current sinks with the logger fields, caching them until the sinks change
+func (c *dynamicCore) cores() []zapcore.Core {
+	set := c.sinks.load()
+	if cached, ok := c.cache.Load().(dynamicCores); ok && cached.set == set {
+		return cached.cores
+	}
+	cores := make([]zapcore.Core, len(set.sinks))
+	for i, sink := range set.sinks {
+		cores[i] = sink.core.With(c.fields)
+	}
+	c.cache.Store(dynamicCores{set: set, cores: cores})
+	return cores
+}
+
+func (c *dynamicCore) Enabled(zapcore.Level) bool {
+	return len(c.sinks.load().sinks) != 0
+}
+
+func (c *dynamicCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dynamicCore{
+		sinks:  c.sinks,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		cache:  &atomic.Value{},
+	}
+}
+
+func (c *dynamicCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	for _, core := range c.cores() {
+		ce = core.Check(ent, ce)
+	}
+	return ce
+}
+
+// Write isn't called, since Check adds the sink cores
+func (c *dynamicCore) Write(zapcore.Entry, []zapcore.Field) error {
+	return nil
+}
+
+func (c *dynamicCore) Sync() (err error) {
+	for _, sink := range c.sinks.load().sinks {
+		err = multierr.Append(err, sink.core.Sync())
+	}
+	return err
+}
+
+// AddSink opens the sink and starts writing the entries of the logger and the loggers sharing its outputs to it,
+// e.g. to attach a debugging sink at runtime. The sink can be targeted by the name with Logger.To.
+// The name must differ from the configured outputs, sinks and groups
+func (l *Logger) AddSink(name string, sink SinkConfig) error {
+	if l == nil || l.out.dynamic == nil {
+		return errors.New("logger doesn't support adding sinks")
+	}
+	return l.out.dynamic.add(name, sink)
+}
+
+// RemoveSink detaches the sink added with AddSink, syncs and closes it.
+// Entries being written concurrently with the removal can fail to be written to the sink
+func (l *Logger) RemoveSink(name string) error {
+	if l == nil || l.out.dynamic == nil {
+		return errors.New("logger doesn't support removing sinks")
+	}
+	return l.out.dynamic.remove(name)
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAddRemoveSink(t *testing.T) {
+	out := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, Outputs: []zapcore.WriteSyncer{out}, Sinks: map[string]SinkConfig{"app": {Output: &bufferSyncer{}}}})
+	child := log.WithField("user", "bob")
+
+	child.Info("before adding")
+	debug := &bufferSyncer{}
+	if err := log.AddSink("debug", SinkConfig{Output: debug, Encoding: EncodingJSON}); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("while added")
+	log.To("debug").Info("targeted")
+	log.To("app").Info("not targeted")
+
+	if err := log.RemoveSink("debug"); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("after removing")
+
+	got := string(debug.Bytes())
+	if !strings.Contains(got, `"msg":"while added","user":"bob"`) || !strings.Contains(got, `"msg":"targeted"`) {
+		t.Errorf("want the entries logged while the sink was added, got %s", got)
+	}
+	for _, msg := range []string{"before adding", "not targeted", "after removing"} {
+		if strings.Contains(got, msg) {
+			t.Errorf("unexpected %q entry in %s", msg, got)
+		}
+	}
+	if got := string(out.Bytes()); !strings.Contains(got, "while added") || strings.Contains(got, "targeted") {
+		t.Errorf("want the configured output unchanged, got %s", got)
+	}
+
+	if err := log.AddSink("app", SinkConfig{Output: &bufferSyncer{}}); err == nil {
+		t.Error("want a duplicate sink error")
+	}
+	if err := log.RemoveSink("debug"); err == nil {
+		t.Error("want an unknown sink error")
+	}
+	if err := NewNoop().AddSink("debug", SinkConfig{Output: &bufferSyncer{}}); err == nil {
+		t.Error("want an unsupported error")
+	}
+}
+
+func TestAddSinkConcurrently(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.WithField("i", i).Info("message")
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if err := log.AddSink("tail", SinkConfig{Output: &bufferSyncer{}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := log.RemoveSink("tail"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultEnricherCacheSize = 1024
+
+// Enricher adds fields to entries at write time, e.g. parsing the user agent (see UserAgentEnricher)
+// or resolving the geo location of an address. Fields contain both the logger and the entry fields (see KeysEnricher).
+// Enrichers implementing io.Closer are closed by Logger.Shutdown
+type Enricher interface {
+	Enrich(e Entry) map[string]interface{}
+}
+
+// KeysEnricher is an Enricher reading only the fields with the keys, so entry fields are encoded for enrichers
+// only if they all implement it and only the fields they read. Enrichers of NewCachedEnricher implement it
+type KeysEnricher interface {
+	Enricher
+	Keys() []string
+}
+
+// EnricherFunc is an Enricher function
+type EnricherFunc func(e Entry) map[string]interface{}
+
+func (f EnricherFunc) Enrich(e Entry) map[string]interface{} { return f(e) }
+
+// CachedEnricherConfig configures NewCachedEnricher
+type CachedEnricherConfig struct {
+	// Key is the field whose value the enriched fields depend on, e.g. "user_agent" or "client_ip".
+	// Entries without it aren't enriched
+	Key string
+	// Size is the maximum number of cached values, the cache is cleared once it's full. Defaults to 1024
+	Size int
+	// Async enriches cache misses in the background, writing the entries without the fields,
+	// so slow lookups (e.g. network ones) never delay logging
+	Async bool
+}
+
+// cachedEnricher caches the fields of an Enricher by the value of the key field
+type cachedEnricher struct {
+	enricher Enricher
+	cfg      CachedEnricherConfig
+
+	mu      sync.Mutex
+	cache   map[string]map[string]interface{}
+	pending map[string]bool
+
+	queue     chan Entry
+	closed    bool
+	closeOnce sync.Once
+	stopped   chan struct{}
+}
+
+// NewCachedEnricher caches the fields added by the enricher by the value of the key field.
+// An async enricher must be closed to stop its goroutine, which Logger.Shutdown does for Config.Enrichers
+func NewCachedEnricher(enricher Enricher, cfg CachedEnricherConfig) Enricher {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultEnricherCacheSize
+	}
+	e := &cachedEnricher{enricher: enricher, cfg: cfg, cache: map[string]map[string]interface{}{}}
+	if cfg.Async {
+		e.pending = map[string]bool{}
+		e.queue = make(chan Entry, cfg.Size)
+		e.stopped = make(chan struct{})
+		go e.run()
+	}
+	return e
+}
+
+func (e *cachedEnricher) Enrich(ent Entry) map[string]interface{} {
+	v, ok := ent.Fields[e.cfg.Key]
+	if !ok {
+		return nil
+	}
+	key := fmt.Sprint(v)
+
+	e.mu.Lock()
+	fields, cached := e.cache[key]
+	if cached || !e.cfg.Async {
+		e.mu.Unlock()
+		if !cached {
+			fields = e.enricher.Enrich(ent)
+			e.store(key, fields)
+		}
+		return fields
+	}
+
+	// Misses are queued once, the entries logged meanwhile aren't enriched
+	if !e.pending[key] && !e.closed {
+		select {
+		case e.queue <- ent:
+			e.pending[key] = true
+		default:
+		}
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *cachedEnricher) store(key string, fields map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.cache) >= e.cfg.Size {
+		e.cache = map[string]map[string]interface{}{}
+	}
+	e.cache[key] = fields
+	delete(e.pending, key)
+}
+
+func (e *cachedEnricher) run() {
+	defer close(e.stopped)
+	for ent := range e.queue {
+		e.store(fmt.Sprint(ent.Fields[e.cfg.Key]), e.enricher.Enrich(ent))
+	}
+}
+
+// Keys returns the key field, the only field the enriched fields depend on
+func (e *cachedEnricher) Keys() []string {
+	return []string{e.cfg.Key}
+}
+
+// Close stops the goroutine of an async enricher
+func (e *cachedEnricher) Close() error {
+	if e.queue == nil {
+		return nil
+	}
+	e.closeOnce.Do(func() {
+		e.mu.Lock()
+		e.closed = true
+		close(e.queue)
+		e.mu.Unlock()
+		<-e.stopped
+	})
+	return nil
+}
+
+// enrichCore adds the fields of Config.Enrichers to entries
+type enrichCore struct {
+	core      zapcore.Core
+	fields    []zapcore.Field // visible context fields read by the enrichers
+	enrichers []Enricher
+	// keys are the keys of the fields read by the enrichers, nil if some of them read all the fields
+	keys map[string]bool
+}
+
+func newEnrichCore(core zapcore.Core, enrichers []Enricher) *enrichCore {
+	keys := map[string]bool{}
+	for _, enricher := range enrichers {
+		keysEnricher, ok := enricher.(KeysEnricher)
+		if !ok {
+			keys = nil
+			break
+		}
+		for _, key := range keysEnricher.Keys() {
+			keys[key] = true
+		}
+	}
+	return &enrichCore{core: core, enrichers: enrichers, keys: keys}
+}
+
+// read reports whether the enrichers read the field
+func (c *enrichCore) read(f zapcore.Field) bool {
+	return f.Type != zapcore.SkipType && (c.keys == nil || c.keys[f.Key])
+}
+
+func (c *enrichCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *enrichCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.core = c.core.With(fields)
+	clone.fields = c.fields[:len(c.fields):len(c.fields)]
+	for _, f := range fields {
+		if c.read(f) {
+			clone.fields = append(clone.fields, f)
+		}
+	}
+	return &clone
+}
+
+func (c *enrichCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *enrichCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	e := entryOf(ent, c.fieldsMap(fields))
+	added := map[string]interface{}{}
+	for _, enricher := range c.enrichers {
+		for key, value := range enricher.Enrich(e) {
+			added[key] = value
+		}
+	}
+
+	// Limiting the capacity makes append copy the fields, which the caller can reuse
+	fields = fields[:len(fields):len(fields)]
+	for _, key := range sortedKeys(added) {
+		fields = append(fields, zap.Any(key, added[key]))
+	}
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// fieldsMap encodes the context and entry fields read by the enrichers
+func (c *enrichCore) fieldsMap(fields []zapcore.Field) map[string]interface{} {
+	if c.keys == nil {
+		return fieldsMap(c.fields, fields)
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range c.fields {
+		c.fields[i].AddTo(enc)
+	}
+	for i := range fields {
+		if c.keys[fields[i].Key] {
+			fields[i].AddTo(enc)
+		}
+	}
+	return enc.Fields
+}
+
+func (c *enrichCore) Sync() error {
+	return c.core.Sync()
+}
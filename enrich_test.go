@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEnrichers(t *testing.T) {
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Observe:       true,
+		Enrichers: []Enricher{
+			UserAgentEnricher("user_agent"),
+			EnricherFunc(func(e Entry) map[string]interface{} {
+				return map[string]interface{}{"region": "eu", "msg_len": len(e.Message)}
+			}),
+		},
+	})
+
+	ua := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	log.WithField("user_agent", ua).Info("request")
+	log.Info("no agent")
+
+	entries := log.ObservedLogs().AllUntimed()
+	fields := entries[0].ContextMap()
+	for key, want := range map[string]interface{}{
+		"user_agent.browser":         "Safari",
+		"user_agent.browser_version": "17.0",
+		"user_agent.os":              "iOS",
+		"user_agent.device":          "mobile",
+		"region":                     "eu",
+		"msg_len":                    int64(7),
+	} {
+		if fields[key] != want {
+			t.Errorf("want %s=%v, got %v", key, want, fields[key])
+		}
+	}
+	if fields := entries[1].ContextMap(); fields["region"] != "eu" || fields["user_agent.device"] != nil {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestKeysEnricherFields(t *testing.T) {
+	var seen map[string]interface{}
+	enricher := NewCachedEnricher(EnricherFunc(func(e Entry) map[string]interface{} {
+		seen = e.Fields
+		return map[string]interface{}{"country": "NL"}
+	}), CachedEnricherConfig{Key: "ip"})
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Enrichers: []Enricher{enricher}})
+
+	encoded := atomic.NewInt64(0)
+	log.WithField("user", countedObject{encoded}).WithField("ip", "203.0.113.1").
+		Event(InfoLevel).Any("payload", countedObject{encoded}).Msg("request")
+
+	if len(seen) != 1 || seen["ip"] != "203.0.113.1" {
+		t.Errorf("want only the key field, got %v", seen)
+	}
+	if fields := log.ObservedLogs().AllUntimed()[0].ContextMap(); fields["country"] != "NL" {
+		t.Errorf("want the enriched field, got %v", fields)
+	}
+	if n := encoded.Load(); n != 2 {
+		t.Errorf("want the objects encoded by the observer only, got %d encodings", n)
+	}
+}
+
+// countedObject counts its encodings
+type countedObject struct{ n *atomic.Int64 }
+
+func (o countedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	o.n.Inc()
+	enc.AddString("name", "value")
+	return nil
+}
+
+func TestCachedEnricherAsync(t *testing.T) {
+	calls := atomic.NewInt64(0)
+	enricher := NewCachedEnricher(EnricherFunc(func(e Entry) map[string]interface{} {
+		calls.Inc()
+		return map[string]interface{}{"country": "NL"}
+	}), CachedEnricherConfig{Key: "ip", Async: true})
+	defer enricher.(interface{ Close() error }).Close()
+
+	e := Entry{Fields: map[string]interface{}{"ip": "203.0.113.1"}}
+	if fields := enricher.Enrich(e); fields != nil {
+		t.Errorf("want a miss enriched in the background, got %v", fields)
+	}
+	deadline := time.Now().Add(time.Second)
+	for enricher.Enrich(e) == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if fields := enricher.Enrich(e); fields["country"] != "NL" || calls.Load() != 1 {
+		t.Errorf("want the cached fields after 1 call, got %v after %d", fields, calls.Load())
+	}
+}
+
+func TestParseUserAgent(t *testing.T) {
+	for ua, want := range map[string]UserAgent{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91": {
+			Browser: "Edge", BrowserVersion: "120.0.2210.91", OS: "Windows", Device: "desktop",
+		},
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36": {
+			Browser: "Chrome", BrowserVersion: "120.0.6099.144", OS: "Android", Device: "mobile",
+		},
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": {Browser: "Googlebot", Device: "bot"},
+		"curl/8.4.0": {Browser: "curl", BrowserVersion: "8.4.0", Device: "desktop"},
+	} {
+		if got := ParseUserAgent(ua); got != want {
+			t.Errorf("want %+v for %s, got %+v", want, ua, got)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var stderr = zapcore.Lock(os.Stderr)
+
+// Entry is a pre-built log entry
+type Entry struct {
+	// Time is the entry timestamp. The current time is used if it's zero
+	Time       time.Time
+	Level      zapcore.Level
+	LoggerName string
+	Message    string
+	Fields     map[string]interface{}
+	// Caller is the call site of the logging call if the caller is added. Its PC identifies the call site,
+	// so hooks and transformers can implement per-call-site logic (e.g. once per call site) without parsing File.
+	// LogBatch writes it if it's defined
+	Caller zapcore.EntryCaller
+}
+
+// entryOf returns the Entry of a written entry with the fields
+func entryOf(ent zapcore.Entry, fields map[string]interface{}) Entry {
+	return Entry{
+		Time:       ent.Time,
+		Level:      ent.Level,
+		LoggerName: ent.LoggerName,
+		Message:    ent.Message,
+		Fields:     fields,
+		Caller:     ent.Caller,
+	}
+}
+
+// CallerPackage returns the import path of the package of the call site, e.g. "github.com/org/app/db",
+// or an empty string if the caller isn't defined
+func (e Entry) CallerPackage() string {
+	if !e.Caller.Defined {
+		return ""
+	}
+	function := e.Caller.Function
+	if function == "" {
+		if fn := runtime.FuncForPC(e.Caller.PC); fn != nil {
+			function = fn.Name()
+		}
+	}
+	return packageOf(function)
+}
+
+// LogBatch writes the entries in one pass. It's intended for importers and other
+// sources of pre-built entries, so caller isn't added (Entry.Caller is written as is) and Fatal/Panic entries
+// don't terminate the program.
+// The entries are encoded into a buffer per output, which is written once at the end of the batch
+// (outputs added with AddSink and custom Cores receive the entries one by one).
+// Entries logged by other goroutines meanwhile are written directly.
+// With Config.DeadLetterFile the entries are written one by one, so the failed ones are stored.
+// Entries below the current level are skipped. Write errors are reported to stderr
+func (l *Logger) LogBatch(entries []Entry) {
+	if l == nil {
+		nopLogger.LogBatch(entries)
+		return
+	}
+	if l.out.deadLetter != nil {
+		l.writeEntries(entries)
+		return
+	}
+	batch := &entryBatch{bufs: map[*batchWriter][]byte{}}
+	defer batch.end()
+	l.withFields(batch.field()).writeEntries(entries)
+}
+
+// writeEntries writes the pre-built entries, see LogBatch
+func (l *Logger) writeEntries(entries []Entry) {
+	core := l.base().Core()
+	now := time.Now()
+	for _, e := range entries {
+		ent := zapcore.Entry{
+			Level:      e.Level,
+			Time:       e.Time,
+			LoggerName: e.LoggerName,
+			Message:    e.Message,
+			Caller:     e.Caller,
+		}
+		if ent.Time.IsZero() {
+			ent.Time = now
+		}
+
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.ErrorOutput = stderr
+			ce.Write(mapToFields(e.Fields)...)
+		}
+	}
+}
+
+// batchWriter is an opened output, whose entries of LogBatch are encoded into the buffers of the batch, see batchCore
+type batchWriter struct {
+	zapcore.WriteSyncer
+	name string
+}
+
+// entryBatch buffers the encoded entries of a LogBatch call per output until it ends.
+// Only the entries of the batch are buffered, so entries of other goroutines are written directly
+type entryBatch struct {
+	mu      sync.Mutex
+	ended   bool
+	outputs []*batchWriter // in the order of the first entries
+	bufs    map[*batchWriter][]byte
+}
+
+// field returns the hidden field making the output cores encode the entries into the batch
+func (b *entryBatch) field() zap.Field {
+	return zap.Field{Key: "batch", Type: zapcore.SkipType, Interface: b}
+}
+
+// add buffers the encoded entry, it reports false if the batch has ended, e.g. for entries written by Async
+func (b *entryBatch) add(out *batchWriter, p []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ended {
+		return false
+	}
+	if _, ok := b.bufs[out]; !ok {
+		b.outputs = append(b.outputs, out)
+	}
+	b.bufs[out] = append(b.bufs[out], p...)
+	return true
+}
+
+// end writes the buffered entries to the outputs, reporting write errors to stderr
+func (b *entryBatch) end() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ended = true
+	for _, out := range b.outputs {
+		if _, err := out.Write(b.bufs[out]); err != nil {
+			fmt.Fprintf(stderr, "%v failed to write batch to %s: %v\n", time.Now().UTC(), out.name, err)
+		}
+	}
+}
+
+// batchCore writes entries to an opened output like the core of zapcore.NewCore,
+// except that the entries of a LogBatch call are encoded into the buffer of the batch
+type batchCore struct {
+	enc   zapcore.Encoder
+	out   *batchWriter
+	batch *entryBatch
+}
+
+func (c *batchCore) Enabled(lvl zapcore.Level) bool {
+	// The level is checked by levelCore
+	return zapcore.DebugLevel.Enabled(lvl)
+}
+
+func (c *batchCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &batchCore{enc: c.enc.Clone(), out: c.out, batch: c.batch}
+	for _, f := range fields {
+		if b, ok := f.Interface.(*entryBatch); ok && f.Type == zapcore.SkipType {
+			clone.batch = b
+			continue
+		}
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *batchCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *batchCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	if c.batch != nil && c.batch.add(c.out, buf.Bytes()) {
+		return nil
+	}
+	if _, err := c.out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// Like zapcore.NewCore, the output is synced before the program terminates
+		_ = c.Sync()
+	}
+	return nil
+}
+
+func (c *batchCore) Sync() error {
+	return c.out.Sync()
+}
+
+func mapToFields(fields map[string]interface{}) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for k, v := range fields {
+		zapFields = append(zapFields, zap.Any(k, v))
+	}
+	return zapFields
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogBatch(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Files: []string{filename}})
+	log.SetLevel("info")
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	log.LogBatch([]Entry{
+		{Time: ts, Level: zapcore.InfoLevel, Message: "first", Fields: map[string]interface{}{"a": 1}},
+		{Time: ts, Level: zapcore.DebugLevel, Message: "skipped"},
+		{Time: ts, Level: zapcore.FatalLevel, Message: "second"},
+	})
+
+	checkFileLogs(t, filename, [][]string{
+		{"2020-01-02 03:04:05", "INFO", `first	{"a": 1}`},
+		{"2020-01-02 03:04:05", "FATAL", "second"},
+	})
+}
+
+func TestLogBatchSingleWrite(t *testing.T) {
+	ws := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Outputs: []zapcore.WriteSyncer{ws}})
+
+	log.LogBatch([]Entry{
+		{Level: zapcore.InfoLevel, Message: "first"},
+		{Level: zapcore.InfoLevel, Message: "second"},
+		{Level: zapcore.InfoLevel, Message: "third"},
+	})
+	if n := bytes.Count(ws.Bytes(), []byte("\n")); n != 3 {
+		t.Errorf("want 3 lines, got %d", n)
+	}
+	if ws.writes != 1 {
+		t.Errorf("want the batch written at once, got %d writes", ws.writes)
+	}
+
+	log.Info("after")
+	if ws.writes != 2 {
+		t.Errorf("want entries after the batch written directly, got %d writes", ws.writes)
+	}
+}
+
+func TestLogBatchOtherGoroutines(t *testing.T) {
+	ws := &bufferSyncer{}
+	var log *Logger
+	var written []byte
+	log = newLogger(t, Config{
+		DisableStdOut: true,
+		DisableColor:  true,
+		Outputs:       []zapcore.WriteSyncer{ws},
+		Enrichers: []Enricher{EnricherFunc(func(e Entry) map[string]interface{} {
+			if e.Message != "batched" {
+				return nil
+			}
+			// Entries of other goroutines, e.g. panics terminating the program, aren't held by the batch
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				defer func() { _ = recover() }()
+				log.Panic("boom")
+			}()
+			<-done
+			written = ws.Bytes()
+			return nil
+		})},
+	})
+
+	log.LogBatch([]Entry{{Level: zapcore.InfoLevel, Message: "batched"}})
+	if !bytes.Contains(written, []byte("boom")) || bytes.Contains(written, []byte("batched")) {
+		t.Errorf("want only the panic written during the batch, got %q", written)
+	}
+	if !bytes.Contains(ws.Bytes(), []byte("batched")) {
+		t.Errorf("want the batch written at the end, got %q", ws.Bytes())
+	}
+}
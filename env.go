@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Environment variables read by InitGlobalFromEnv
+const (
+	// EnvLevel is the level name, info by default
+	EnvLevel = "LOG_LEVEL"
+	// EnvEncoding is the encoding: console (default), json or pretty
+	EnvEncoding = "LOG_ENCODING"
+	// EnvOutput is "stdout" (default), "stderr", a file path or any URL supported by zap.Open
+	EnvOutput = "LOG_OUTPUT"
+	// EnvNoColor disables colors when set to any value, see https://no-color.org
+	EnvNoColor = "NO_COLOR"
+)
+
+// ConfigFromEnv returns the configuration and the level of a console logger from the environment, see EnvLevel
+func ConfigFromEnv() (Config, string, error) {
+	lvl := os.Getenv(EnvLevel)
+	if lvl == "" {
+		lvl = "info"
+	}
+	if _, err := parseLevel(lvl); err != nil {
+		return Config{}, "", errors.Wrapf(err, "failed to parse %s", EnvLevel)
+	}
+
+	cfg := Config{Encoding: Encoding(os.Getenv(EnvEncoding))}
+	if !cfg.Encoding.valid() {
+		return Config{}, "", errors.Errorf("unknown %s %q", EnvEncoding, cfg.Encoding)
+	}
+	if _, ok := os.LookupEnv(EnvNoColor); ok {
+		cfg.DisableColor = true
+	}
+	if output := os.Getenv(EnvOutput); output != "" && output != "stdout" {
+		cfg.DisableStdOut = true
+		cfg.Sinks = map[string]SinkConfig{"env": {Path: output}}
+	}
+	return cfg, lvl, nil
+}
+
+// InitGlobalFromEnv replaces the noop global logger (see L) with a logger configured from the environment
+// and returns it. It's opt-in, so libraries stay silent by default; building with the logger_env tag
+// calls it on initialization, keeping the noop logger if the environment is invalid
+func InitGlobalFromEnv() (*Logger, error) {
+	cfg, lvl, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	log, err := New(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to New")
+	}
+	log.SetLevel(lvl)
+	SetGlobal(log)
+	return log, nil
+}
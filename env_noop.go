@@ -0,0 +1,6 @@
+//go:build !logger_env
+
+package logger
+
+// envDefault makes the global logger configured from the environment on initialization, see InitGlobalFromEnv
+const envDefault = false
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitGlobalFromEnv(t *testing.T) {
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(EnvLevel, "warn")
+	t.Setenv(EnvEncoding, "json")
+	t.Setenv(EnvOutput, filename)
+
+	log, err := InitGlobalFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if L() != log {
+		t.Error("want the global logger replaced")
+	}
+	L().Info("skipped")
+	L().Warn("written")
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(readFile(t, filename)), []byte("\n"))
+	if len(lines) != 1 || !bytes.Contains(lines[0], []byte(`"msg":"written"`)) {
+		t.Errorf("unexpected output: %s", bytes.Join(lines, []byte("\n")))
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	t.Setenv(EnvLevel, "verbose")
+	if _, _, err := ConfigFromEnv(); err == nil {
+		t.Error("want an invalid level error")
+	}
+
+	t.Setenv(EnvLevel, "")
+	t.Setenv(EnvEncoding, "xml")
+	if _, _, err := ConfigFromEnv(); err == nil {
+		t.Error("want an unknown encoding error")
+	}
+}
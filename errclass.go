@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// ErrorClassifier returns the code and category of the error for the error.code and error.category fields.
+// Empty values are omitted, false means the classifier doesn't know the error
+type ErrorClassifier func(err error) (code, category string, ok bool)
+
+var (
+	classifiersMu sync.Mutex
+	classifiers   atomic.Value // []ErrorClassifier
+)
+
+// RegisterErrorClassifier adds a classifier used by Logger.WithError and Logger.Errorr for errors
+// not implementing Code() string and Category() string themselves.
+// It's intended to be called on initialization, e.g. mapping driver errors:
+//
+//	logger.RegisterErrorClassifier(func(err error) (string, string, bool) {
+//		var pgErr *pgconn.PgError
+//		if errors.As(err, &pgErr) {
+//			return pgErr.Code, "database", true
+//		}
+//		return "", "", false
+//	})
+func RegisterErrorClassifier(fn ErrorClassifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+
+	current, _ := classifiers.Load().([]ErrorClassifier)
+	classifiers.Store(append(append([]ErrorClassifier(nil), current...), fn))
+}
+
+type errorCoder interface{ Code() string }
+
+type errorCategorizer interface{ Category() string }
+
+// classifyError returns the code and category of the error or any error it wraps,
+// falling back to the registered classifiers
+func classifyError(err error) (code, category string) {
+	var coder errorCoder
+	if errors.As(err, &coder) {
+		code = coder.Code()
+	}
+	var categorizer errorCategorizer
+	if errors.As(err, &categorizer) {
+		category = categorizer.Category()
+	}
+	if code != "" && category != "" {
+		return code, category
+	}
+
+	current, _ := classifiers.Load().([]ErrorClassifier)
+	for _, classify := range current {
+		c, cat, ok := classify(err)
+		if !ok {
+			continue
+		}
+		if code == "" {
+			code = c
+		}
+		if category == "" {
+			category = cat
+		}
+		break
+	}
+	return code, category
+}
+
+// errorFields returns the error field with the error.code and error.category fields if the error is classified
+func errorFields(err error) []zap.Field {
+	fields := []zap.Field{zap.Error(err)}
+	if err == nil {
+		return fields
+	}
+	code, category := classifyError(err)
+	if code != "" {
+		fields = append(fields, zap.String("error.code", code))
+	}
+	if category != "" {
+		fields = append(fields, zap.String("error.category", category))
+	}
+	return fields
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type codedError struct{ code, category string }
+
+func (e codedError) Error() string    { return "coded" }
+func (e codedError) Code() string     { return e.code }
+func (e codedError) Category() string { return e.category }
+
+type throttledError struct{}
+
+func (throttledError) Error() string { return "throttled" }
+
+func TestWithErrorClassification(t *testing.T) {
+	RegisterErrorClassifier(func(err error) (string, string, bool) {
+		if errors.As(err, &throttledError{}) {
+			return "THROTTLED", "upstream", true
+		}
+		return "", "", false
+	})
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	log.WithError(errors.Wrap(codedError{"E42", "validation"}, "failed to parse")).Info("a")
+	log.WithError(errors.Wrap(throttledError{}, "failed to call")).Info("b")
+	log.WithError(errors.New("plain")).Info("c")
+	_ = log.Errorr(codedError{code: "E1"}, "failed to save")
+
+	tests := []struct {
+		code, category interface{}
+	}{
+		{"E42", "validation"},
+		{"THROTTLED", "upstream"},
+		{nil, nil},
+		{"E1", nil},
+	}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != len(tests) {
+		t.Fatalf("want %d entries, got %d", len(tests), len(entries))
+	}
+	for i, tt := range tests {
+		fields := entries[i].ContextMap()
+		if fields["error.code"] != tt.code || fields["error.category"] != tt.category || fields["error"] == nil {
+			t.Errorf("%s: unexpected fields: %v", entries[i].Message, fields)
+		}
+	}
+}
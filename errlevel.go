@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrorLevelMapper returns the level entries with the error are logged at. False keeps the entry level
+type ErrorLevelMapper func(err error) (zapcore.Level, bool)
+
+// ExpectedErrors returns a mapper logging entries with the errors (matched with errors.Is) at the level,
+// e.g. ExpectedErrors(DebugLevel, context.Canceled, io.EOF, sql.ErrNoRows)
+func ExpectedErrors(lvl zapcore.Level, targets ...error) ErrorLevelMapper {
+	return func(err error) (zapcore.Level, bool) {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return lvl, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// errorLevelMapper is a hidden field value carrying a mapper added with Logger.WithErrorLevelMapper
+type errorLevelMapper struct {
+	mapper ErrorLevelMapper
+	levels []zapcore.Level // nil if the mapper can return any level
+}
+
+// WithErrorLevelMapper returns a logger changing the level of entries with error fields (see WithError) by the mapper,
+// so expected errors don't show up as errors at every call site. Entry fields are checked before the logger fields.
+// Mappers are tried in order of adding, the first one returning true wins.
+// Levels are the levels the mapper can return, e.g. the level of ExpectedErrors. Since a mapper can raise
+// the level of any entry with an error, entries below the logger level are skipped before their fields are known
+// only if none of the levels is enabled, so without levels all the entries of the logger are built.
+// It's supported by loggers created with New
+func (l *Logger) WithErrorLevelMapper(mapper ErrorLevelMapper, levels ...zapcore.Level) *Logger {
+	m := errorLevelMapper{mapper: mapper, levels: append([]zapcore.Level(nil), levels...)}
+	return l.withFields(zap.Field{Key: "error_level_mapper", Type: zapcore.SkipType, Interface: m})
+}
+
+// errorLevelCore changes entry levels by the error level mappers before the level is checked
+type errorLevelCore struct {
+	core    zapcore.Core
+	mappers []ErrorLevelMapper
+	levels  []zapcore.Level // the levels the mappers can return
+	// anyLevel is set if a mapper can return any level
+	anyLevel bool
+	errs     []error // errors of the logger fields
+}
+
+func (c *errorLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl) || c.canRaise()
+}
+
+// canRaise reports whether a mapper can change the level of an entry to an enabled one
+func (c *errorLevelCore) canRaise() bool {
+	if c.anyLevel {
+		return true
+	}
+	for _, lvl := range c.levels {
+		if c.core.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *errorLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	rest := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if m, ok := f.Interface.(errorLevelMapper); ok && f.Type == zapcore.SkipType {
+			clone.mappers = append(clone.mappers[:len(clone.mappers):len(clone.mappers)], m.mapper)
+			if len(m.levels) == 0 {
+				clone.anyLevel = true
+			} else {
+				clone.levels = append(clone.levels[:len(clone.levels):len(clone.levels)], m.levels...)
+			}
+			continue
+		}
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			clone.errs = append(clone.errs[:len(clone.errs):len(clone.errs)], err)
+		}
+		rest = append(rest, f)
+	}
+	clone.core = c.core.With(rest)
+	return &clone
+}
+
+func (c *errorLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if len(c.mappers) == 0 {
+		return c.core.Check(ent, ce)
+	}
+	// Entry fields are known only when writing
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write is called only for entries of loggers with mappers
+func (c *errorLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if lvl, ok := c.mapLevel(fields); ok {
+		ent.Level = lvl
+	}
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *errorLevelCore) mapLevel(fields []zapcore.Field) (zapcore.Level, bool) {
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			if lvl, ok := c.mapError(err); ok {
+				return lvl, true
+			}
+		}
+	}
+	for _, err := range c.errs {
+		if lvl, ok := c.mapError(err); ok {
+			return lvl, true
+		}
+	}
+	return 0, false
+}
+
+func (c *errorLevelCore) mapError(err error) (zapcore.Level, bool) {
+	if err == nil {
+		return 0, false
+	}
+	for _, mapper := range c.mappers {
+		if lvl, ok := mapper(err); ok {
+			return lvl, true
+		}
+	}
+	return 0, false
+}
+
+func (c *errorLevelCore) Sync() error {
+	return c.core.Sync()
+}
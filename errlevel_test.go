@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithErrorLevelMapper(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("info")
+
+	l := log.
+		WithErrorLevelMapper(ExpectedErrors(DebugLevel, context.Canceled)).
+		WithErrorLevelMapper(ExpectedErrors(InfoLevel, io.EOF, context.Canceled))
+
+	l.WithError(errors.Wrap(context.Canceled, "request")).Error("canceled")
+	l.WithError(io.EOF).Error("eof")
+	l.ErrorFields("eof field", zap.Error(io.EOF))
+	l.WithError(io.EOF).ErrorFields("logger error", zap.Error(errors.New("unexpected")))
+	l.Error("no error")
+	log.WithError(io.EOF).Error("without mapper")
+
+	want := []struct {
+		msg string
+		lvl zapcore.Level
+	}{
+		{"eof", InfoLevel},
+		{"eof field", InfoLevel},
+		{"logger error", InfoLevel},
+		{"no error", ErrorLevel},
+		{"without mapper", ErrorLevel},
+	}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %+v", len(want), entries)
+	}
+	for i, e := range entries {
+		if e.Message != want[i].msg || e.Level != want[i].lvl {
+			t.Errorf("want %q at %s, got %q at %s", want[i].msg, want[i].lvl, e.Message, e.Level)
+		}
+	}
+}
+
+func TestErrorLevelMapperEnabled(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("info")
+
+	lowering := log.WithErrorLevelMapper(ExpectedErrors(DebugLevel, io.EOF), DebugLevel)
+	if lowering.base().Core().Enabled(DebugLevel) {
+		t.Error("want debug disabled for a mapper lowering levels to debug")
+	}
+	raising := log.WithErrorLevelMapper(ExpectedErrors(ErrorLevel, io.ErrUnexpectedEOF), ErrorLevel)
+	if !raising.base().Core().Enabled(DebugLevel) {
+		t.Error("want debug enabled for a mapper raising levels to error")
+	}
+	undeclared := log.WithErrorLevelMapper(ExpectedErrors(DebugLevel, io.EOF))
+	if !undeclared.base().Core().Enabled(DebugLevel) {
+		t.Error("want debug enabled for a mapper without levels")
+	}
+
+	raising.WithError(io.ErrUnexpectedEOF).Debug("raised")
+	lowering.WithError(io.EOF).Debug("lowered")
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 || entries[0].Message != "raised" || entries[0].Level != ErrorLevel {
+		t.Errorf("want the raised entry only, got %+v", entries)
+	}
+}
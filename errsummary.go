@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxErrorSummaryKeys bounds the number of templates counted per interval
+const maxErrorSummaryKeys = 1000
+
+// ErrorSummaryConfig configures periodic summaries of repeated errors
+type ErrorSummaryConfig struct {
+	// Interval between summaries. Zero disables summaries
+	Interval time.Duration
+	// Level is the minimal level of counted entries. Defaults to error
+	Level zapcore.Level
+	// SuppressAbove drops entries of a template logged more than SuppressAbove times during the interval.
+	// They are still counted in the summary. Zero disables suppression
+	SuppressAbove int
+}
+
+// errorStat counts entries of a message template during the current interval
+type errorStat struct {
+	level      zapcore.Level
+	loggerName string
+	message    string
+	count      int
+	suppressed int
+}
+
+// errorSummary counts entries by logger name and message template.
+// Digits in messages are ignored, so messages with variable numbers (IDs, durations) are grouped
+type errorSummary struct {
+	cfg   ErrorSummaryConfig
+	core  zapcore.Core // core to write summaries to
+	mu    sync.Mutex
+	stats map[string]*errorStat
+}
+
+func newErrorSummary(core zapcore.Core, cfg ErrorSummaryConfig) *errorSummary {
+	if cfg.Level == zapcore.DebugLevel {
+		cfg.Level = zapcore.ErrorLevel
+	}
+	return &errorSummary{cfg: cfg, core: core, stats: map[string]*errorStat{}}
+}
+
+// count counts the entry and reports whether it should be written
+func (s *errorSummary) count(ent zapcore.Entry) bool {
+	if ent.Level < s.cfg.Level || ent.Level > zapcore.ErrorLevel {
+		return true
+	}
+	key := ent.LoggerName + "\x00" + messageTemplate(ent.Message)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stat, ok := s.stats[key]
+	if !ok {
+		if len(s.stats) >= maxErrorSummaryKeys {
+			return true
+		}
+		stat = &errorStat{level: ent.Level, loggerName: ent.LoggerName, message: ent.Message}
+		s.stats[key] = stat
+	}
+	stat.count++
+	if s.cfg.SuppressAbove > 0 && stat.count > s.cfg.SuppressAbove {
+		stat.suppressed++
+		return false
+	}
+	return true
+}
+
+// emit writes a summary entry for every template logged more than once during the interval and resets the counters
+func (s *errorSummary) emit() {
+	s.mu.Lock()
+	stats := s.stats
+	s.stats = map[string]*errorStat{}
+	s.mu.Unlock()
+
+	keys := make([]string, 0, len(stats))
+	for key, stat := range stats {
+		if stat.count > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		stat := stats[key]
+		ent := zapcore.Entry{
+			Level:      stat.level,
+			Time:       time.Now(),
+			LoggerName: stat.loggerName,
+			Message:    fmt.Sprintf("%q occurred %d times in last %s", stat.message, stat.count, s.cfg.Interval),
+		}
+		if ce := s.core.Check(ent, nil); ce != nil {
+			ce.ErrorOutput = stderr
+			ce.Write(
+				zap.String("error_template", messageTemplate(stat.message)),
+				zap.Int("count", stat.count),
+				zap.Int("suppressed", stat.suppressed),
+				zap.Duration("interval", s.cfg.Interval),
+			)
+		}
+	}
+}
+
+// messageTemplate replaces digit sequences with "#"
+func messageTemplate(msg string) string {
+	var b strings.Builder
+	digits := false
+	for _, r := range msg {
+		if unicode.IsDigit(r) {
+			if !digits {
+				b.WriteByte('#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// errorSummaryCore counts entries passing through it, see errorSummary
+type errorSummaryCore struct {
+	zapcore.Core
+	summary *errorSummary
+}
+
+func (c *errorSummaryCore) With(fields []zapcore.Field) zapcore.Core {
+	return &errorSummaryCore{Core: c.Core.With(fields), summary: c.summary}
+}
+
+func (c *errorSummaryCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(ent.Level) || !c.summary.count(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorSummary(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, ErrorSummary: ErrorSummaryConfig{Interval: time.Hour, SuppressAbove: 2}})
+
+	for i := 0; i < 5; i++ {
+		log.Errorf("failed to get user %d", i)
+	}
+	log.Error("once")
+	log.Warn("warning")
+
+	core := log.base().Core().(*gateCore).Core.(*errorLevelCore).core.(*levelCore).core.(*fatalCauseCore).core.(*transformCore).core.(*errorSummaryCore)
+	core.summary.emit()
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 5 {
+		t.Fatalf("want 2 errors, once, warning and summary, got %d", len(entries))
+	}
+	summary := entries[4]
+	if summary.Message != `"failed to get user 0" occurred 5 times in last 1h0m0s` {
+		t.Errorf("unexpected summary: %s", summary.Message)
+	}
+	if fields := summary.ContextMap(); fields["count"] != int64(5) || fields["suppressed"] != int64(3) || fields["error_template"] != "failed to get user #" {
+		t.Errorf("unexpected summary fields: %v", fields)
+	}
+
+	core.summary.emit()
+	if n := log.ObservedLogs().Len(); n != 5 {
+		t.Errorf("want counters reset, got %d entries", n)
+	}
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Event is a log entry being built, an allocation-conscious alternative to WithField chains:
+//
+//	l.Event(logger.InfoLevel).Str("user", u).Int("n", 3).Err(err).Msg("created")
+//
+// Events are pooled, so an Event must not be used after Msg/Msgf.
+// Event is nil if its level is disabled, all the methods are noop then
+type Event struct {
+	base   *zap.Logger
+	level  zapcore.Level
+	fields []zap.Field
+}
+
+// maxPooledFields limits the capacity of fields kept in pooled events
+const maxPooledFields = 64
+
+var eventPool = sync.Pool{
+	New: func() interface{} {
+		return &Event{fields: make([]zap.Field, 0, 8)}
+	},
+}
+
+// Event starts a new entry of the given level
+func (l *Logger) Event(lvl zapcore.Level) *Event {
+	if !l.base().Core().Enabled(lvl) {
+		return nil
+	}
+
+	e := eventPool.Get().(*Event)
+	e.base = l.base()
+	e.level = lvl
+	return e
+}
+
+func (e *Event) Str(key, val string) *Event                   { return e.Field(zap.String(key, val)) }
+func (e *Event) Int(key string, val int) *Event               { return e.Field(zap.Int(key, val)) }
+func (e *Event) Int64(key string, val int64) *Event           { return e.Field(zap.Int64(key, val)) }
+func (e *Event) Uint64(key string, val uint64) *Event         { return e.Field(zap.Uint64(key, val)) }
+func (e *Event) Float64(key string, val float64) *Event       { return e.Field(zap.Float64(key, val)) }
+func (e *Event) Bool(key string, val bool) *Event             { return e.Field(zap.Bool(key, val)) }
+func (e *Event) Dur(key string, val time.Duration) *Event     { return e.Field(zap.Duration(key, val)) }
+func (e *Event) Time(key string, val time.Time) *Event        { return e.Field(zap.Time(key, val)) }
+func (e *Event) Any(key string, val interface{}) *Event       { return e.Field(zap.Any(key, val)) }
+func (e *Event) Strs(key string, val []string) *Event         { return e.Field(zap.Strings(key, val)) }
+func (e *Event) Stringer(key string, val fmt.Stringer) *Event { return e.Field(zap.Stringer(key, val)) }
+func (e *Event) Object(key string, val zapcore.ObjectMarshaler) *Event {
+	return e.Field(zap.Object(key, val))
+}
+
+// Err adds the error as the "error" field. Nil errors are skipped
+func (e *Event) Err(err error) *Event {
+	if err == nil {
+		return e
+	}
+	return e.Field(zap.Error(err))
+}
+
+// Field adds an arbitrary zap field
+func (e *Event) Field(f zap.Field) *Event {
+	if e != nil {
+		e.fields = append(e.fields, f)
+	}
+	return e
+}
+
+// Msg writes the entry and releases the event
+func (e *Event) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	if ce := e.base.Check(e.level, msg); ce != nil {
+		ce.Write(e.fields...)
+	}
+	e.release()
+}
+
+// Msgf writes the entry with a formatted message and releases the event
+func (e *Event) Msgf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	if ce := e.base.Check(e.level, fmt.Sprintf(format, args...)); ce != nil {
+		ce.Write(e.fields...)
+	}
+	e.release()
+}
+
+func (e *Event) release() {
+	if cap(e.fields) > maxPooledFields {
+		return
+	}
+	for i := range e.fields {
+		e.fields[i] = zap.Field{} // don't retain field values in the pool
+	}
+	e.fields = e.fields[:0]
+	e.base = nil
+	eventPool.Put(e)
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEvent(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}})
+	log.SetLevel("info")
+
+	log.Event(DebugLevel).Str("skipped", "true").Msg("debug")
+	log.Event(InfoLevel).Str("user", "u").Int("n", 3).Dur("d", time.Second).Err(nil).Msg("created")
+	log.WithField("a", 1).Event(ErrorLevel).Err(errors.New("some error")).Msgf("failed %d", 2)
+
+	checkFileLogs(t, filename, [][]string{
+		{"event_test.go", `created	{"user": "u", "n": 3, "d": "1s"}`},
+		{"event_test.go", `failed 2	{"a": 1, "error": "some error"`},
+	})
+}
+
+func BenchmarkEvent(b *testing.B) {
+	log := NewWith(zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), DebugLevel,
+	)), DebugLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Event(InfoLevel).Str("user", "u").Int("n", i).Err(io.EOF).Msg("created")
+	}
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultOutputQueueSize is the per-output queue size used if Config.OutputQueueSize isn't set
+const defaultOutputQueueSize = 1024
+
+var (
+	// errOutputClosed is returned by writes racing with Logger.Shutdown after the output queues are closed
+	errOutputClosed = errors.New("output is closed")
+	// errEntryDropped is returned by non-blocking writes to a full queue, dropCore counts them
+	errEntryDropped = errors.New("output queue is full, the entry is dropped")
+)
+
+// fanOutWriter is a zapcore.WriteSyncer dispatching writes to several outputs concurrently.
+// Every output has its own queue and goroutine, so a slow output delays the caller
+// only when its queue is full.
+// Write errors are collected and returned by the next Sync call.
+// In the non-blocking mode writes to a full queue are dropped instead, failing with errEntryDropped
+type fanOutWriter struct {
+	workers     []*outputWorker
+	nonBlocking bool
+
+	// mu guards the queues from being closed while writes and syncs are sent to them
+	mu     sync.RWMutex
+	closed bool
+}
+
+type outputWorker struct {
+	ws    zapcore.WriteSyncer
+	queue chan outputOp
+	done  chan struct{}
+	err   error // accessed only by the worker goroutine
+}
+
+// outputOp is either a write of data or a sync request if reply isn't nil
+type outputOp struct {
+	data  []byte
+	reply chan error
+}
+
+func newFanOutWriter(queueSize int, nonBlocking bool, syncers ...zapcore.WriteSyncer) *fanOutWriter {
+	if queueSize <= 0 {
+		queueSize = defaultOutputQueueSize
+	}
+
+	w := &fanOutWriter{nonBlocking: nonBlocking}
+	for _, ws := range syncers {
+		worker := &outputWorker{
+			ws:    ws,
+			queue: make(chan outputOp, queueSize),
+			done:  make(chan struct{}),
+		}
+		go worker.run()
+		w.workers = append(w.workers, worker)
+	}
+	return w
+}
+
+func (w *fanOutWriter) Write(p []byte) (int, error) {
+	// zap reuses the buffer after Write returns
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errOutputClosed
+	}
+	dropped := false
+	for _, worker := range w.workers {
+		if !w.nonBlocking {
+			worker.queue <- outputOp{data: data}
+			continue
+		}
+		select {
+		case worker.queue <- outputOp{data: data}:
+		default:
+			dropped = true
+		}
+	}
+	if dropped {
+		return 0, errEntryDropped
+	}
+	return len(p), nil
+}
+
+// Sync waits until all the queued entries are written and syncs every output
+func (w *fanOutWriter) Sync() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	replies := make([]chan error, len(w.workers))
+	for i, worker := range w.workers {
+		replies[i] = make(chan error, 1)
+		worker.queue <- outputOp{reply: replies[i]}
+	}
+	w.mu.RUnlock()
+
+	var err error
+	for _, reply := range replies {
+		err = multierr.Append(err, <-reply)
+	}
+	return err
+}
+
+// Close drains the queues and stops the workers. The outputs aren't closed, later writes fail with errOutputClosed
+func (w *fanOutWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	for _, worker := range w.workers {
+		close(worker.queue)
+	}
+	w.mu.Unlock()
+
+	for _, worker := range w.workers {
+		<-worker.done
+	}
+	return nil
+}
+
+func (w *outputWorker) run() {
+	defer close(w.done)
+
+	for op := range w.queue {
+		if op.reply != nil {
+			op.reply <- multierr.Append(w.err, w.ws.Sync())
+			w.err = nil
+			continue
+		}
+
+		if _, err := w.ws.Write(op.data); err != nil {
+			w.err = multierr.Append(w.err, err)
+		}
+	}
+}
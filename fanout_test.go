@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFanOutWriter(t *testing.T) {
+	fast := &bufferSyncer{}
+	slow := &bufferSyncer{delay: 50 * time.Millisecond}
+	failing := &bufferSyncer{err: errors.New("write failed")}
+
+	w := newFanOutWriter(10, false, fast, slow, failing)
+	defer w.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("writes are blocked by the slow output for %s", elapsed)
+	}
+
+	if err := w.Sync(); err == nil {
+		t.Error("want write error on Sync, got nil")
+	}
+	for _, ws := range []*bufferSyncer{fast, slow} {
+		if n := bytes.Count(ws.Bytes(), []byte("\n")); n != 5 {
+			t.Errorf("want 5 lines, got %d", n)
+		}
+	}
+}
+
+// bufferSyncer is a zapcore.WriteSyncer for tests
+type bufferSyncer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	delay  time.Duration
+	err    error
+	writes int
+
+	started   chan struct{} // closed on the first write if not nil
+	startOnce sync.Once
+}
+
+var _ zapcore.WriteSyncer = &bufferSyncer{}
+
+func (s *bufferSyncer) Write(p []byte) (int, error) {
+	if s.started != nil {
+		s.startOnce.Do(func() { close(s.started) })
+	}
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writes++
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.buf.Write(p)
+}
+
+func (s *bufferSyncer) Sync() error { return nil }
+
+func (s *bufferSyncer) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *bufferSyncer) Bytes() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte(nil), s.buf.Bytes()...)
+}
+
+func TestFanOutWriterClosed(t *testing.T) {
+	w := newFanOutWriter(1, false, &bufferSyncer{})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("line\n")); err != errOutputClosed {
+		t.Errorf("want errOutputClosed, got %v", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Errorf("want nil Sync error after Close, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fatalCauseCore logs the cause of DPanic, Panic and Fatal entries with errors before them:
+// a "fatal cause" entry at Error level with the unwrap chain of the error and the stack of the call site.
+// Error entries go through all the outputs like the others, so the cause isn't lost
+// if the terminating entry is truncated by the exit. It wraps the core below the level core,
+// so the cause is written even if Error level is disabled
+type fatalCauseCore struct {
+	core zapcore.Core
+	errs []error // errors of the logger fields
+}
+
+func (c *fatalCauseCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *fatalCauseCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			clone.errs = append(clone.errs[:len(clone.errs):len(clone.errs)], err)
+		}
+	}
+	clone.core = c.core.With(fields)
+	return &clone
+}
+
+func (c *fatalCauseCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < zapcore.DPanicLevel {
+		return c.core.Check(ent, ce)
+	}
+	// The cause is written before the entry, so the deferred write order is kept
+	return c.core.Check(ent, ce.AddCore(ent, c))
+}
+
+// Write logs the cause of DPanic, Panic and Fatal entries, the entries themselves are written by the wrapped core
+func (c *fatalCauseCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.cause(fields)
+	if err == nil {
+		return nil
+	}
+	cause := ent
+	cause.Level, cause.Message, cause.Stack = ErrorLevel, "fatal cause", ""
+	if ce := c.core.Check(cause, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(
+			zap.Error(err),
+			zap.Strings("error.chain", errorChain(err)),
+			zap.String("stack", callSiteStack(ent.Caller)),
+		)
+	}
+	return nil
+}
+
+func (c *fatalCauseCore) Sync() error {
+	return c.core.Sync()
+}
+
+// cause returns the first error of the entry fields or the logger fields
+func (c *fatalCauseCore) cause(fields []zapcore.Field) error {
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType && err != nil {
+			return err
+		}
+	}
+	for _, err := range c.errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorChain returns the type and message of every error unwrapped from err.
+// Layers repeating the message of the previous one, like stack wrappers, are skipped
+func errorChain(err error) []string {
+	var chain []string
+	var prev string
+	for ; err != nil; err = errors.Unwrap(err) {
+		msg := err.Error()
+		if len(chain) != 0 && msg == prev {
+			continue
+		}
+		chain = append(chain, fmt.Sprintf("%T: %s", err, msg))
+		prev = msg
+	}
+	return chain
+}
+
+// callSiteStack returns the stack of the calling goroutine without runtime frames, starting at the caller if it's on it
+func callSiteStack(caller zapcore.EntryCaller) string {
+	s := stack(1)
+	if !caller.Defined {
+		return s
+	}
+	frame := caller.Function + "\n\t" + caller.File + ":" + strconv.Itoa(caller.Line)
+	if i := strings.Index(s, frame); i >= 0 {
+		return s[i:]
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFatalCause(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	root := errors.New("connection refused")
+	func() {
+		defer func() { _ = recover() }()
+		log.WithError(errors.Wrap(root, "failed to open db")).Panic("can't start")
+	}()
+	func() {
+		defer func() { _ = recover() }()
+		log.Panic("no error")
+	}()
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+	cause := entries[0]
+	if cause.Message != "fatal cause" || cause.Level != zapcore.ErrorLevel {
+		t.Fatalf("unexpected cause: %+v", cause)
+	}
+	chain, _ := cause.ContextMap()["error.chain"].([]interface{})
+	if len(chain) != 2 || !strings.HasSuffix(chain[0].(string), ": failed to open db: connection refused") ||
+		!strings.HasSuffix(chain[1].(string), ": connection refused") {
+		t.Errorf("unexpected chain: %v", chain)
+	}
+	if s, _ := cause.ContextMap()["stack"].(string); !strings.HasPrefix(s, "github.com/kiteggrad/logger.TestFatalCause") {
+		t.Errorf("want the stack from the call site, got %s", s)
+	}
+	if entries[1].Message != "can't start" || entries[1].Level != zapcore.PanicLevel {
+		t.Errorf("unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Message != "no error" {
+		t.Errorf("want no cause without errors, got %+v", entries[2])
+	}
+}
+
+func TestFatalCauseAboveErrorLevel(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("dpanic")
+
+	func() {
+		defer func() { _ = recover() }()
+		log.WithError(errors.New("connection refused")).base().DPanic("can't start")
+	}()
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 || entries[0].Message != "fatal cause" || entries[1].Level != zapcore.DPanicLevel {
+		t.Fatalf("want the cause of the dpanic entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+const fdScheme = "fd://"
+
+// openFD opens an "fd://<n>" output: a file descriptor inherited from a supervisor
+// (e.g. a pipe or a socket passed by systemd or runit)
+func openFD(path string) (*os.File, zapcore.WriteSyncer, error) {
+	fd, err := strconv.ParseUint(strings.TrimPrefix(path, fdScheme), 10, 32)
+	if err != nil {
+		return nil, nil, errors.Errorf("invalid file descriptor in %s", path)
+	}
+
+	file := os.NewFile(uintptr(fd), path)
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to stat %s", path)
+	}
+	// fsync fails on pipes and sockets
+	if !info.Mode().IsRegular() {
+		return file, unsyncedWriter{file}, nil
+	}
+	return file, file, nil
+}
+
+// unsyncedWriter is a zapcore.WriteSyncer for outputs that can't be synced
+type unsyncedWriter struct {
+	*os.File
+}
+
+func (unsyncedWriter) Sync() error { return nil }
@@ -0,0 +1,48 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestFDOutput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// The logger owns and closes the passed descriptor, like one inherited from a supervisor
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{"fd://" + strconv.Itoa(fd)}})
+	log.Info("to pipe")
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown with a pipe: %s", err)
+	}
+	w.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "to pipe") {
+		t.Errorf("want the entry in the pipe, got %q", data)
+	}
+}
+
+func TestInvalidFDOutput(t *testing.T) {
+	if _, err := New(Config{DisableStdOut: true, Files: []string{"fd://x"}}); err == nil {
+		t.Error("want an error")
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// fieldList is an immutable list of logger fields shared between clones.
+// Adding fields creates a node pointing to the parent one, so no fields are copied
+type fieldList struct {
+	parent *fieldList
+	fields []zap.Field
+	len    int
+}
+
+func (f *fieldList) add(fields []zap.Field) *fieldList {
+	if len(fields) == 0 {
+		return f
+	}
+	node := &fieldList{parent: f, fields: fields, len: len(fields)}
+	if f != nil {
+		node.len += f.len
+	}
+	return node
+}
+
+// all returns the fields in the order of adding
+func (f *fieldList) all() []zap.Field {
+	if f == nil {
+		return nil
+	}
+	all := make([]zap.Field, f.len)
+	end := f.len
+	for node := f; node != nil; node = node.parent {
+		end -= len(node.fields)
+		copy(all[end:], node.fields)
+	}
+	return all
+}
+
+// appliedLogger is the root logger with the fields applied, built on the first use
+type appliedLogger struct {
+	once  sync.Once
+	ready atomic.Bool
+	base  *zap.Logger
+	sugar *zap.SugaredLogger
+
+	accessOnce sync.Once
+	access     *zap.Logger // the logger of access entries, see Logger.checkAccess
+}
+
+// base returns the zap logger with the logger fields. A nil logger is a noop one
+func (l *Logger) base() *zap.Logger {
+	if l == nil {
+		return nopLogger.base()
+	}
+	l.applied.once.Do(func() {
+		l.applied.base = l.root.With(l.fields.all()...)
+		l.applied.sugar = l.applied.base.Sugar()
+		l.applied.ready.Store(true)
+	})
+	return l.applied.base
+}
+
+// sugar returns the sugared zap logger with the logger fields
+func (l *Logger) sugar() *zap.SugaredLogger {
+	if l == nil {
+		return nopLogger.sugar()
+	}
+	l.base()
+	return l.applied.sugar
+}
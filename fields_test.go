@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newBenchLogger(b *testing.B) *Logger {
+	b.Helper()
+
+	log, err := New(Config{DisableStdOut: true, Encoding: EncodingJSON, Outputs: []zapcore.WriteSyncer{zapcore.AddSync(io.Discard)}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return log
+}
+
+func TestFieldList(t *testing.T) {
+	var list *fieldList
+	parent := list.add([]zap.Field{zap.Int("a", 1), zap.Int("b", 2)})
+	left, right := parent.add([]zap.Field{zap.Int("c", 3)}), parent.add([]zap.Field{zap.Int("d", 4)})
+
+	for list, want := range map[*fieldList]string{left: "abc", right: "abd", parent: "ab"} {
+		got := ""
+		for _, f := range list.all() {
+			got += f.Key
+		}
+		if got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+}
+
+func TestWithFieldsAfterApplied(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	parent := log.WithField("a", 1)
+	parent.Info("parent")
+	parent.WithField("b", 2).Info("child")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if fields := entries[1].ContextMap(); len(fields) != 2 || fields["a"] != int64(1) || fields["b"] != int64(2) {
+		t.Errorf("unexpected child fields: %v", fields)
+	}
+}
+
+func BenchmarkWithFieldsChain(b *testing.B) {
+	log := newBenchLogger(b)
+	fields := map[string]interface{}{"a": 1, "b": "2"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := log
+		for depth := 0; depth < 10; depth++ {
+			l = l.WithFields(fields)
+		}
+		l.Info("message")
+	}
+}
+
+func BenchmarkWithFieldShared(b *testing.B) {
+	log := newBenchLogger(b).WithField("request_id", "42").WithField("user_id", 1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("message")
+	}
+}
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// fileIndexSuffix is appended to the path of a file to get the path of its index, see Config.FileIndex
+const fileIndexSuffix = ".idx"
+
+// FileIndexBlock describes the records written to a file during a minute, see Config.FileIndex.
+// A minute can be split into several blocks, e.g. by Sync calls
+type FileIndexBlock struct {
+	Minute  time.Time      `json:"minute"`
+	Offset  int64          `json:"offset"`
+	Size    int64          `json:"size"`
+	Records int            `json:"records"`
+	Levels  map[string]int `json:"levels,omitempty"`
+	SHA256  string         `json:"sha256"`
+}
+
+// ReadFileIndex reads the index of the file written with Config.FileIndex
+func ReadFileIndex(path string) ([]FileIndexBlock, error) {
+	file, err := os.Open(path + fileIndexSuffix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file index")
+	}
+	defer file.Close()
+
+	var blocks []FileIndexBlock
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var block FileIndexBlock
+		if err := json.Unmarshal(scanner.Bytes(), &block); err != nil {
+			return blocks, errors.Wrapf(err, "failed to parse block %d", len(blocks))
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, errors.Wrap(scanner.Err(), "failed to read file index")
+}
+
+// SeekFileIndex returns the offset of the first block of the minute containing t or later ones,
+// so readers can skip older records. It's the file size if there are no such blocks
+func SeekFileIndex(blocks []FileIndexBlock, t time.Time) int64 {
+	minute := t.Truncate(time.Minute)
+	var end int64
+	for _, block := range blocks {
+		if !block.Minute.Before(minute) {
+			return block.Offset
+		}
+		end = block.Offset + block.Size
+	}
+	return end
+}
+
+// VerifyFileIndex checks the file against its index, detecting truncated and corrupted blocks
+func VerifyFileIndex(path string) error {
+	blocks, err := ReadFileIndex(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat file")
+	}
+
+	for _, block := range blocks {
+		if block.Offset+block.Size > info.Size() {
+			err = multierr.Append(err, errors.Errorf("block of %s at offset %d is truncated: file size is %d",
+				block.Minute.Format(time.RFC3339), block.Offset, info.Size()))
+			continue
+		}
+		h := sha256.New()
+		if _, readErr := io.Copy(h, io.NewSectionReader(file, block.Offset, block.Size)); readErr != nil {
+			return errors.Wrap(readErr, "failed to read file")
+		}
+		if hex.EncodeToString(h.Sum(nil)) != block.SHA256 {
+			err = multierr.Append(err, errors.Errorf("block of %s at offset %d is corrupted: checksum mismatch",
+				block.Minute.Format(time.RFC3339), block.Offset))
+		}
+	}
+	return err
+}
+
+// fileIndex maintains the index of a file written by a fileWriter
+type fileIndex struct {
+	file   *os.File
+	offset int64 // the end of the indexed records
+	block  FileIndexBlock
+	hash   hash.Hash
+}
+
+// openFileIndex opens the index of the file with the size, appending to an existing one
+func openFileIndex(path string, size int64, mode os.FileMode) (*fileIndex, error) {
+	file, err := os.OpenFile(path+fileIndexSuffix, os.O_WRONLY|os.O_APPEND|os.O_CREATE, mode)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file index")
+	}
+	return &fileIndex{file: file, offset: size, hash: sha256.New()}, nil
+}
+
+// add indexes a record written at t, flushing the block of the previous minute
+func (x *fileIndex) add(t time.Time, record []byte) error {
+	minute := t.Truncate(time.Minute)
+	var err error
+	if x.block.Records != 0 && !x.block.Minute.Equal(minute) {
+		err = x.flush()
+	}
+	if x.block.Records == 0 {
+		x.block = FileIndexBlock{Minute: minute, Offset: x.offset, Levels: make(map[string]int)}
+		x.hash.Reset()
+	}
+
+	x.block.Size += int64(len(record))
+	x.block.Records++
+	if lvl, ok := recordLevel(record); ok {
+		x.block.Levels[lvl]++
+	}
+	_, _ = x.hash.Write(record)
+	x.offset += int64(len(record))
+	return err
+}
+
+// flush writes the current block to the index
+func (x *fileIndex) flush() error {
+	if x.block.Records == 0 {
+		return nil
+	}
+	x.block.SHA256 = hex.EncodeToString(x.hash.Sum(nil))
+	line, err := json.Marshal(x.block)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal file index block")
+	}
+	x.block = FileIndexBlock{}
+	_, err = x.file.Write(append(line, '\n'))
+	return errors.Wrap(err, "failed to write file index")
+}
+
+func (x *fileIndex) close() error {
+	return multierr.Append(x.flush(), x.file.Close())
+}
+
+// recordLevels are the level names of the JSON and console encodings
+var recordLevels = [][]byte{
+	[]byte("debug"), []byte("info"), []byte("warn"), []byte("error"), []byte("dpanic"), []byte("panic"), []byte("fatal"),
+	[]byte("DEBUG"), []byte("INFO"), []byte("WARN"), []byte("ERROR"), []byte("DPANIC"), []byte("PANIC"), []byte("FATAL"),
+}
+
+// recordLevel finds the level of an encoded record. The level is near the start in all the encodings,
+// so only the record head is searched for the earliest standalone level name
+func recordLevel(record []byte) (string, bool) {
+	head := record
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	found, at := "", len(head)
+	for _, name := range recordLevels {
+		i := bytes.Index(head, name)
+		if i < 0 || i >= at || !wordBoundary(head, i-1) || !wordBoundary(head, i+len(name)) {
+			continue
+		}
+		found, at = string(bytes.ToLower(name)), i
+	}
+	return found, found != ""
+}
+
+// wordBoundary reports whether b[i] isn't a letter. The end of a color escape sequence like "\x1b[31m" is a boundary
+func wordBoundary(b []byte, i int) bool {
+	if i < 0 || i >= len(b) {
+		return true
+	}
+	if b[i] == 'm' && i > 0 && b[i-1] >= '0' && b[i-1] <= '9' {
+		return true
+	}
+	return !(b[i] >= 'a' && b[i] <= 'z' || b[i] >= 'A' && b[i] <= 'Z')
+}
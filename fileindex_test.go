@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileIndex(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+
+	w, err := openFileWriter(filename, fileOptions{index: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 2, 10, 30, 15, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	mustWrite(t, w, "{\"level\":\"info\",\"msg\":\"first\"}\n")
+	mustWrite(t, w, "2024-06-02 10:30:16\t\x1b[33mWARN\x1b[0m\tsecond\n")
+	now = now.Add(time.Minute)
+	mustWrite(t, w, "{\"level\":\"error\",\"msg\":\"third\"}\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := ReadFileIndex(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].Records != 2 || blocks[0].Levels["info"] != 1 || blocks[0].Levels["warn"] != 1 ||
+		blocks[1].Levels["error"] != 1 || blocks[1].Offset != blocks[0].Size {
+		t.Fatalf("unexpected blocks: %+v", blocks)
+	}
+	if offset := SeekFileIndex(blocks, now.Add(10*time.Second)); offset != blocks[1].Offset {
+		t.Errorf("want the offset of the second block, got %d", offset)
+	}
+	if err := VerifyFileIndex(filename); err != nil {
+		t.Errorf("want a valid file, got %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := strings.Replace(string(data), "first", "fir5t", 1)
+	if err := os.WriteFile(filename, []byte(corrupted), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFileIndex(filename); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("want a corrupted block, got %v", err)
+	}
+	if err := os.Truncate(filename, blocks[1].Offset+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFileIndex(filename); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("want a truncated block, got %v", err)
+	}
+}
+
+func TestFileIndexRotation(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, FileIndex: true})
+	log.Info("before rotation")
+	if err := log.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	log.Warn("after rotation")
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := filepath.Glob(filepath.Join(filepath.Dir(filename), "app.*.log"))
+	if err != nil || len(rotated) != 1 {
+		t.Fatalf("want a rotated file, got %v %v", rotated, err)
+	}
+	for path, level := range map[string]string{rotated[0]: "info", filename: "warn"} {
+		blocks, err := ReadFileIndex(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks) != 1 || blocks[0].Levels[level] != 1 {
+			t.Errorf("%s: unexpected blocks %+v", path, blocks)
+		}
+		if err := VerifyFileIndex(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+func TestRecordLevel(t *testing.T) {
+	tests := map[string]string{
+		`{"level":"dpanic","msg":"x"}`:                     "dpanic",
+		"2024-06-02 10:30:15\tINFO\tmsg with error":        "info",
+		"2024-06-02 10:30:15 \x1b[31mERROR\x1b[0m warning": "error",
+		"no level here":          "",
+		"2024-06-02 informative": "",
+	}
+	for record, want := range tests {
+		if got, _ := recordLevel([]byte(record)); got != want {
+			t.Errorf("%q: want %q, got %q", record, want, got)
+		}
+	}
+}
+
+func TestFileIndexErrorKeepsWrite(t *testing.T) {
+	errOut := captureStderr(t)
+	filename := createTempFiles(t, "app.log")[0]
+
+	w, err := openFileWriter(filename, fileOptions{index: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	now := time.Date(2024, 6, 2, 10, 30, 15, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	mustWrite(t, w, "first\n")
+	// The block of the previous minute fails to be flushed
+	if err := w.index.file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if n, err := w.Write([]byte("second\n")); n != len("second\n") || err != nil {
+		t.Fatalf("want the record written, got %d, %v", n, err)
+	}
+
+	checkFileLogs(t, filename, [][]string{{"first"}, {"second"}})
+	if !strings.Contains(string(errOut.Bytes()), "failed to index") {
+		t.Errorf("want the index error reported, got %q", errOut.Bytes())
+	}
+	if err := w.Sync(); err == nil {
+		t.Error("want the index error returned by Sync")
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// errorFingerprint returns a stable hash of the error type chain and the error message template,
+// so errors differing only in numbers (IDs, counts, durations) get the same fingerprint
+func errorFingerprint(err error) string {
+	h := fnv.New64a()
+	for e := err; e != nil; e = unwrapError(e) {
+		fmt.Fprintf(h, "%T\n", e)
+	}
+	_, _ = h.Write([]byte(messageTemplate(err.Error())))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func unwrapError(err error) error {
+	u, ok := err.(interface{ Unwrap() error })
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+// fingerprintFields returns a "<key>.fingerprint" field for every error field
+func fingerprintFields(fields []zapcore.Field) []zapcore.Field {
+	var fingerprints []zapcore.Field
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType && err != nil {
+			fingerprints = append(fingerprints, zap.String(f.Key+".fingerprint", errorFingerprint(err)))
+		}
+	}
+	return fingerprints
+}
+
+// fingerprintCore adds fingerprints of the error fields, see Config.ErrorFingerprint
+type fingerprintCore struct {
+	core zapcore.Core
+}
+
+func (c *fingerprintCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *fingerprintCore) With(fields []zapcore.Field) zapcore.Core {
+	return &fingerprintCore{core: c.core.With(append(fields[:len(fields):len(fields)], fingerprintFields(fields)...))}
+}
+
+func (c *fingerprintCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// Entry fields are known only when writing
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *fingerprintCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(append(fields[:len(fields):len(fields)], fingerprintFields(fields)...)...)
+	}
+	return nil
+}
+
+func (c *fingerprintCore) Sync() error {
+	return c.core.Sync()
+}
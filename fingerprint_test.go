@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrorFingerprint(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, ErrorFingerprint: true})
+
+	log.WithError(errors.New("user 42 not found")).Error("failed")
+	log.WithError(errors.New("user 7 not found")).Error("failed")
+	log.Errorr(fmt.Errorf("query: %w", errors.New("user 1 not found")), "failed")
+	log.Info("no error")
+
+	entries := log.ObservedLogs().AllUntimed()
+	fingerprints := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		fingerprints = append(fingerprints, e.ContextMap()["error.fingerprint"])
+	}
+	if fingerprints[0] == nil || fingerprints[0] != fingerprints[1] {
+		t.Errorf("want equal fingerprints of errors differing in numbers, got %v", fingerprints)
+	}
+	if fingerprints[2] == nil || fingerprints[2] == fingerprints[0] {
+		t.Errorf("want a different fingerprint of a wrapped error, got %v", fingerprints)
+	}
+	if fingerprints[3] != nil {
+		t.Errorf("want no fingerprint without error, got %v", fingerprints[3])
+	}
+}
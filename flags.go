@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"flag"
+
+	"github.com/pkg/errors"
+)
+
+// Command line flags registered by Config.RegisterFlags
+const (
+	// FlagLevel sets Config.Level
+	FlagLevel = "log-level"
+	// FlagFormat sets Config.Encoding: console, json, pretty or canonical
+	FlagFormat = "log-format"
+	// FlagFile writes entries to the file instead of stdout, any URL supported by zap.Open is accepted too
+	FlagFile = "log-file"
+)
+
+// fileFlagSink is the name of the sink added by FlagFile
+const fileFlagSink = "file"
+
+// RegisterFlags binds the --log-level, --log-format and --log-file flags to the config fields,
+// so CLIs get consistent logging flags. The current values are used as defaults:
+//
+//	cfg := logger.DefaultConfig()
+//	cfg.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//	log, err := logger.New(cfg)
+func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(levelFlag{cfg}, FlagLevel, "logging level: debug, info, warn, error")
+	fs.Var(formatFlag{cfg}, FlagFormat, "logging format: console, json, pretty, canonical")
+	fs.Var(fileFlag{cfg}, FlagFile, "file to write logs to instead of stdout")
+}
+
+// PFlagSet is implemented by *pflag.FlagSet of github.com/spf13/pflag, used by cobra
+type PFlagSet interface {
+	AddGoFlagSet(fs *flag.FlagSet)
+}
+
+// RegisterPFlags binds the flags of RegisterFlags to a pflag set, e.g. cmd.PersistentFlags() of cobra
+func (cfg *Config) RegisterPFlags(fs PFlagSet) {
+	goFlags := flag.NewFlagSet("logger", flag.ContinueOnError)
+	cfg.RegisterFlags(goFlags)
+	fs.AddGoFlagSet(goFlags)
+}
+
+// levelFlag is the flag.Value of FlagLevel. The flag package calls String of zero values, so cfg can be nil
+type levelFlag struct{ cfg *Config }
+
+func (f levelFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return f.cfg.Level
+}
+
+func (f levelFlag) Set(value string) error {
+	if _, err := parseLevel(value); err != nil {
+		return err
+	}
+	f.cfg.Level = value
+	return nil
+}
+
+type formatFlag struct{ cfg *Config }
+
+func (f formatFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return string(f.cfg.Encoding)
+}
+
+func (f formatFlag) Set(value string) error {
+	if !Encoding(value).valid() {
+		return errors.Errorf("unknown encoding %q", value)
+	}
+	f.cfg.Encoding = Encoding(value)
+	return nil
+}
+
+type fileFlag struct{ cfg *Config }
+
+func (f fileFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return f.cfg.Sinks[fileFlagSink].Path
+}
+
+// Set replaces stdout with the file like EnvOutput does
+func (f fileFlag) Set(value string) error {
+	if value == "" {
+		return errors.New("empty file path")
+	}
+	sinks := make(map[string]SinkConfig, len(f.cfg.Sinks)+1)
+	for name, sink := range f.cfg.Sinks {
+		sinks[name] = sink
+	}
+	sinks[fileFlagSink] = SinkConfig{Path: value}
+	f.cfg.Sinks = sinks
+	f.cfg.DisableStdOut = true
+	return nil
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+
+	cfg := DefaultConfig()
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"--log-level", "warn", "--log-format=json", "--log-file", filename}); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("skipped")
+	log.Warn("written")
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(readFile(t, filename)), []byte("\n"))
+	if len(lines) != 1 || !bytes.Contains(lines[0], []byte(`"msg":"written"`)) {
+		t.Errorf("unexpected output: %s", bytes.Join(lines, []byte("\n")))
+	}
+}
+
+func TestRegisterFlagsInvalid(t *testing.T) {
+	for _, args := range [][]string{{"--log-level", "verbose"}, {"--log-format", "xml"}, {"--log-file="}} {
+		var cfg Config
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		cfg.RegisterFlags(fs)
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("want an error for %v", args)
+		}
+	}
+}
+
+type pflagSet struct{ *flag.FlagSet }
+
+func (fs pflagSet) AddGoFlagSet(goFlags *flag.FlagSet) {
+	goFlags.VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+}
+
+func TestRegisterPFlags(t *testing.T) {
+	cfg := Config{Level: "info"}
+	fs := pflagSet{flag.NewFlagSet("app", flag.ContinueOnError)}
+	cfg.RegisterPFlags(fs)
+	if f := fs.Lookup(FlagLevel); f == nil || f.DefValue != "info" {
+		t.Fatalf("unexpected flag: %+v", f)
+	}
+	if err := fs.Parse([]string{"--log-level=error"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "error" {
+		t.Errorf("want the level set, got %q", cfg.Level)
+	}
+}
@@ -0,0 +1,6 @@
+package logger
+
+import "github.com/pkg/errors"
+
+// ErrFileLockUnsupported is returned by New if Config.FileLock is set on a platform without flock
+var ErrFileLockUnsupported = errors.New("file locking isn't supported on this platform")
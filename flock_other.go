@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package logger
+
+import "os"
+
+const fileLockSupported = false
+
+func lockFile(*os.File) error {
+	return ErrFileLockUnsupported
+}
+
+func unlockFile(*os.File) error {
+	return ErrFileLockUnsupported
+}
@@ -1,11 +1,67 @@
 package logger
 
-var globalLogger = NewNoop()
+import (
+	"sync"
 
+	"go.uber.org/atomic"
+)
+
+var (
+	globalLogger atomic.Value // *Logger
+	globalSet    atomic.Bool
+
+	stderrFallback     atomic.Bool
+	stderrFallbackOnce sync.Once
+	stderrLogger       *Logger
+)
+
+// nopLogger backs the methods of nil loggers, so a nil *Logger is a noop one
+var nopLogger = NewNoop()
+
+func init() {
+	globalLogger.Store(NewNoop())
+	if envDefault {
+		_, _ = InitGlobalFromEnv()
+	}
+}
+
+// SetGlobal replaces the logger returned by L. It's safe to call concurrently with L.
+// Passing nil restores the default: the noop logger or the stderr one (see UseStderrFallback)
 func SetGlobal(l *Logger) {
-	globalLogger = l
+	wasSet := globalSet.Swap(l != nil)
+	if l == nil {
+		globalLogger.Store(defaultGlobal())
+		return
+	}
+	globalLogger.Store(l)
+	if !wasSet {
+		reportNoopCalls(l)
+	}
+}
+
+// UseStderrFallback makes L return a minimal logger writing Info and higher entries to stderr
+// until SetGlobal is called, instead of the noop one silently dropping them.
+// The logger is built on the first use
+func UseStderrFallback() {
+	stderrFallback.Store(true)
 }
 
+// L returns the global logger, a noop one until SetGlobal is called (see UseStderrFallback)
 func L() *Logger {
-	return globalLogger
+	if !globalSet.Load() && stderrFallback.Load() {
+		return fallbackLogger()
+	}
+	return globalLogger.Load().(*Logger)
+}
+
+func fallbackLogger() *Logger {
+	stderrFallbackOnce.Do(func() {
+		log, err := New(Config{DisableStdOut: true, Sinks: map[string]SinkConfig{"stderr": {Path: "stderr"}}})
+		if err != nil {
+			log = NewNoop()
+		}
+		log.SetLevel("info")
+		stderrLogger = log
+	})
+	return stderrLogger
 }
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	noopTracer       atomic.Value // *noopTraceCore
+	noopTraceReports atomic.Bool
+)
+
+// TraceNoopGlobal makes the global logger count the entries logged before SetGlobal by call site,
+// helping to find code logging into the void (see NoopGlobalCalls). With report set, the next SetGlobal
+// logs the call sites with the new logger at Warn level. It's a debugging aid to call on the start of main
+func TraceNoopGlobal(report bool) {
+	tracer := &noopTraceCore{calls: make(map[string]int)}
+	noopTracer.Store(tracer)
+	noopTraceReports.Store(report)
+	if !globalSet.Load() {
+		globalLogger.Store(tracer.logger())
+	}
+}
+
+// NoopGlobalCalls returns the number of entries logged into the noop global logger by call site ("file:line")
+// since TraceNoopGlobal was called
+func NoopGlobalCalls() map[string]int {
+	tracer, _ := noopTracer.Load().(*noopTraceCore)
+	if tracer == nil {
+		return nil
+	}
+	return tracer.snapshot()
+}
+
+// defaultGlobal returns the global logger used until SetGlobal is called
+func defaultGlobal() *Logger {
+	if tracer, _ := noopTracer.Load().(*noopTraceCore); tracer != nil {
+		return tracer.logger()
+	}
+	return NewNoop()
+}
+
+// reportNoopCalls logs the call sites traced before the logger was set, once
+func reportNoopCalls(l *Logger) {
+	if !noopTraceReports.CAS(true, false) {
+		return
+	}
+	calls := NoopGlobalCalls()
+	if len(calls) == 0 {
+		return
+	}
+	total := 0
+	for _, n := range calls {
+		total += n
+	}
+	fields := []zap.Field{zap.Int("count", total), zap.Any("call_sites", calls)}
+	l.base().WithOptions(zap.WithCaller(false)).Warn("entries logged before the global logger was set", fields...)
+}
+
+// noopTraceCore discards entries counting them by call site
+type noopTraceCore struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *noopTraceCore) logger() *Logger {
+	return &Logger{
+		root:        zap.New(c, zap.AddCaller(), zap.AddCallerSkip(1)),
+		applied:     &appliedLogger{},
+		level:       zap.NewAtomicLevel(),
+		out:         &outputs{},
+		wrapperSkip: 1,
+	}
+}
+
+func (c *noopTraceCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *noopTraceCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *noopTraceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// The caller is known only when writing
+	return ce.AddCore(ent, c)
+}
+
+func (c *noopTraceCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	site := "unknown"
+	if ent.Caller.Defined {
+		site = ent.Caller.TrimmedPath()
+	}
+	c.mu.Lock()
+	c.calls[site]++
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *noopTraceCore) Sync() error {
+	return nil
+}
+
+func (c *noopTraceCore) snapshot() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	calls := make(map[string]int, len(c.calls))
+	for site, n := range c.calls {
+		calls[site] = n
+	}
+	return calls
+}
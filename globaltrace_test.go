@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTraceNoopGlobal(t *testing.T) {
+	t.Cleanup(func() {
+		noopTracer.Store((*noopTraceCore)(nil))
+		SetGlobal(nil)
+	})
+
+	TraceNoopGlobal(true)
+	for i := 0; i < 3; i++ {
+		L().Info("into the void")
+	}
+	L().WithField("k", "v").Warnf("into the void %d", 4)
+
+	calls := NoopGlobalCalls()
+	if len(calls) != 2 {
+		t.Fatalf("want 2 call sites, got %v", calls)
+	}
+	for site, n := range calls {
+		if !strings.Contains(site, "globaltrace_test.go:") || (n != 3 && n != 1) {
+			t.Errorf("unexpected call site %s: %d", site, n)
+		}
+	}
+
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	SetGlobal(log)
+	SetGlobal(nil)
+	SetGlobal(log)
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("want a single report, got %d entries", len(entries))
+	}
+	if entries[0].ContextMap()["count"] != int64(4) {
+		t.Errorf("unexpected report: %+v", entries[0])
+	}
+}
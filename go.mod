@@ -4,10 +4,8 @@ go 1.18
 
 require (
 	github.com/pkg/errors v0.9.1
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.22.0
 )
 
-require (
-	go.uber.org/atomic v1.7.0 // indirect
-	go.uber.org/multierr v1.6.0 // indirect
-)
+require go.uber.org/atomic v1.7.0
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WorkerGroup runs functions in goroutines like errgroup.Group. Every function gets a child logger
+// with the "worker" index field, also stored in its context (see FromContext).
+// Panics are recovered, logged with the panic fields and returned by Wait as errors
+type WorkerGroup struct {
+	log    *Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	mu      sync.Mutex
+	workers int
+	errOnce sync.Once
+	err     error
+}
+
+// Group returns a worker group and a context canceled when a function returns an error or panics,
+// or when Wait returns
+func Group(ctx context.Context, l *Logger) (*WorkerGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &WorkerGroup{log: l, ctx: ctx, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of running functions, Go blocks until a function returns.
+// Non-positive values remove the limit. It must not be called while functions are running
+func (g *WorkerGroup) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs the function in a new goroutine
+func (g *WorkerGroup) Go(fn func(ctx context.Context, log *Logger) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	worker := g.workers
+	g.workers++
+	g.mu.Unlock()
+
+	log := g.log.WithField("worker", worker)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		defer func() {
+			if v := recover(); v != nil {
+				log.logRecovered(v, 0)
+				g.fail(fmt.Errorf("worker %d panicked: %v", worker, v))
+			}
+		}()
+
+		if err := fn(ToContext(g.ctx, log), log); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait waits for all the functions and returns the first error
+func (g *WorkerGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+func (g *WorkerGroup) fail(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestGroup(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	g, ctx := Group(context.Background(), log)
+	g.SetLimit(2)
+	g.Go(func(ctx context.Context, log *Logger) error {
+		FromContext(ctx).Info("from context")
+		return nil
+	})
+	g.Go(func(ctx context.Context, log *Logger) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Go(func(ctx context.Context, log *Logger) error {
+		panic(errors.New("boom"))
+	})
+
+	err := g.Wait()
+	if err == nil || err.Error() != "worker 2 panicked: boom" {
+		t.Errorf("want the panic error, got %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("want the context canceled")
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Message != "from context" || e.ContextMap()["worker"] != int64(0) {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.Message != "recovered from panic" || e.ContextMap()["worker"] != int64(2) ||
+		!strings.HasSuffix(e.Caller.File, "group_test.go") {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
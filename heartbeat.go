@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type HeartbeatConfig struct {
+	// Interval between heartbeat entries. Zero disables heartbeats
+	Interval time.Duration
+	// Level of heartbeat entries. Defaults to info
+	Level zapcore.Level
+	// Message of heartbeat entries. Defaults to "heartbeat"
+	Message string
+	// Fields are added to every heartbeat entry
+	Fields map[string]interface{}
+}
+
+// startHeartbeat periodically logs heartbeat entries until the returned stop function is called
+func startHeartbeat(base *zap.Logger, cfg HeartbeatConfig) (stop func() error) {
+	if cfg.Message == "" {
+		cfg.Message = "heartbeat"
+	}
+	base = base.WithOptions(zap.WithCaller(false)).With(mapToFields(cfg.Fields)...)
+
+	return runEvery(cfg.Interval, func() {
+		if ce := base.Check(cfg.Level, cfg.Message); ce != nil {
+			ce.Write()
+		}
+	})
+}
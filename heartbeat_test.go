@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		DisableColor:  true,
+		Files:         []string{filename},
+		Heartbeat: HeartbeatConfig{
+			Interval: 10 * time.Millisecond,
+			Level:    WarnLevel,
+			Fields:   map[string]interface{}{"service": "api"},
+		},
+	})
+
+	time.Sleep(35 * time.Millisecond)
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(readFile(t, filename)), []byte("\n"))
+	if len(lines) < 2 {
+		t.Errorf("want at least 2 heartbeats, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !bytes.Contains(line, []byte(`WARN	heartbeat	{"service": "api"}`)) {
+			t.Errorf("unexpected heartbeat: %s", line)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// HTTPConfig configures Logger.HTTPMiddleware
+type HTTPConfig struct {
+	// RequestIDHeader is the header with the request ID added to the request logger. Defaults to "X-Request-ID"
+	RequestIDHeader string
+	// DebugHeader is the header switching the request logger to debug level, e.g. "X-Debug-Log".
+	// The request entries are tagged with debug_forced=true. Disabled if empty
+	DebugHeader string
+	// DebugQueryParam is the query parameter working like DebugHeader, e.g. "debug_log". Disabled if empty
+	DebugQueryParam string
+	// DebugToken is the value DebugHeader or DebugQueryParam must have to take effect.
+	// Any non-empty value is accepted if it's empty, which is insecure for public endpoints
+	DebugToken string
+	// DebugPercent switches the request logger to debug level for a deterministic percentage (0-100) of requests
+	// by the hash of the request ID, see Logger.WithDebugSample. Requests without an ID aren't sampled
+	DebugPercent int
+	// DisableAccessLog disables the entry logged on request completion
+	DisableAccessLog bool
+	// Bodies captures request and response bodies, adding them to the access log of failed requests.
+	// It's disabled by default
+	Bodies BodyCaptureConfig
+	// DisableRecovery disables recovering panics of handlers
+	DisableRecovery bool
+	// Route returns the route pattern of the request added to the access log as the "route" field,
+	// e.g. chi.RouteContext(r.Context()).RoutePattern(). It's called after the handler, once routers have matched the route
+	Route func(r *http.Request) string
+}
+
+// HTTPMiddleware returns a net/http middleware that:
+//   - stores a request logger with the method, path and request ID fields in the request context (see FromContext);
+//   - switches it to debug level for requests with the debug header or query parameter, or a percentage of requests;
+//   - logs completed requests with the status, size, latency and user agent (see WithAccessLog to sample them),
+//     optionally with the bodies of failed requests (see BodyCaptureConfig);
+//   - recovers panics of the handler, logging them and responding with 500.
+func (l *Logger) HTTPMiddleware(cfg HTTPConfig) func(http.Handler) http.Handler {
+	if cfg.RequestIDHeader == "" {
+		cfg.RequestIDHeader = "X-Request-ID"
+	}
+	bodies := newBodyCapturer(cfg.Bodies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			reqFields := []zap.Field{zap.String("method", r.Method), zap.String("path", r.URL.Path)}
+			id := r.Header.Get(cfg.RequestIDHeader)
+			if id != "" {
+				reqFields = append(reqFields, zap.String("request_id", id))
+			}
+			reqLog := l.withFields(reqFields...)
+			// Access entries are logged by the middleware logger with the request fields as entry fields,
+			// so its access logger is built once. Requests at debug level log them by the request logger
+			accessLog, accessFields := l, reqFields
+			if cfg.debugForced(r) {
+				reqLog = reqLog.WithMinLevel("debug").withFields(zap.Bool("debug_forced", true))
+				accessLog, accessFields = reqLog, nil
+			} else if sampled := reqLog.WithDebugSample(id, cfg.DebugPercent); sampled != reqLog {
+				reqLog = sampled
+				accessLog, accessFields = reqLog, nil
+			}
+
+			rw := &responseWriter{ResponseWriter: w, bodies: bodies}
+			requestBody := bodies.captureRequest(r)
+			r = r.WithContext(ToContext(r.Context(), reqLog))
+			// Deferred before the recovery, so the recovered response status is logged
+			if !cfg.DisableAccessLog {
+				defer func() {
+					var extra []zap.Field
+					if ua := r.UserAgent(); ua != "" {
+						extra = append(extra, zap.String("user_agent", ua))
+					}
+					if bodies != nil && rw.status() >= http.StatusBadRequest {
+						extra = append(extra, bodies.fields(requestBody, rw.body)...)
+					}
+					accessLog.logRequest(rw, start, cfg.route(r), accessFields, extra...)
+				}()
+			}
+			if !cfg.DisableRecovery {
+				defer func() {
+					v := recover()
+					if v == nil {
+						return
+					}
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					reqLog.logRecovered(v, 0)
+					if !rw.wroteHeader {
+						rw.WriteHeader(http.StatusInternalServerError)
+					}
+				}()
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// debugForced reports whether the request has the debug header or query parameter with a valid value
+func (cfg HTTPConfig) debugForced(r *http.Request) bool {
+	var value string
+	if cfg.DebugHeader != "" {
+		value = r.Header.Get(cfg.DebugHeader)
+	}
+	if value == "" && cfg.DebugQueryParam != "" {
+		value = r.URL.Query().Get(cfg.DebugQueryParam)
+	}
+	if value == "" {
+		return false
+	}
+	return cfg.DebugToken == "" || subtle.ConstantTimeCompare([]byte(value), []byte(cfg.DebugToken)) == 1
+}
+
+func (cfg HTTPConfig) route(r *http.Request) string {
+	if cfg.Route == nil {
+		return ""
+	}
+	return cfg.Route(r)
+}
+
+// logRequest logs the completed request with the request fields at Error level for 5xx responses and at Info level otherwise
+func (l *Logger) logRequest(rw *responseWriter, start time.Time, route string, reqFields []zap.Field, extra ...zap.Field) {
+	lvl := InfoLevel
+	if rw.status() >= http.StatusInternalServerError {
+		lvl = ErrorLevel
+	}
+
+	ce := l.checkAccess(lvl, "request completed")
+	if ce == nil {
+		return
+	}
+	fields := append(reqFields[:len(reqFields):len(reqFields)],
+		zap.Int("status", rw.status()),
+		zap.Int64("size", rw.size),
+		zap.Duration("latency", time.Since(start)),
+	)
+	if route != "" {
+		fields = append(fields, zap.String("route", route))
+	}
+	ce.Write(append(fields, extra...)...)
+}
+
+// responseWriter records the status and size of a response, and its body if bodies is set
+type responseWriter struct {
+	http.ResponseWriter
+	code        int
+	size        int64
+	wroteHeader bool
+
+	bodies      *bodyCapturer
+	body        *capturedBody
+	bodyChecked bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.bodies != nil && !w.bodyChecked {
+		w.bodyChecked = true
+		contentType := w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(p)
+		}
+		if w.bodies.allowed(contentType) {
+			w.body = &capturedBody{max: w.bodies.cfg.MaxSize}
+		}
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	if w.body != nil {
+		w.body.write(p[:n])
+	}
+	return n, err
+}
+
+func (w *responseWriter) status() int {
+	if !w.wroteHeader {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// Flush implements http.Flusher if the wrapped writer does
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the wrapped writer does, e.g. for websocket upgrades.
+// Hijacked requests are logged with the 101 status unless a status is written before
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer doesn't implement http.Hijacker")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.code, w.wroteHeader = http.StatusSwitchingProtocols, true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
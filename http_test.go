@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("info")
+
+	handler := log.HTTPMiddleware(HTTPConfig{DebugHeader: "X-Debug-Log", DebugToken: "secret"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Debug("details")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	for _, token := range []string{"", "wrong", "secret"} {
+		r := httptest.NewRequest(http.MethodPost, "/users", nil)
+		r.Header.Set("X-Request-ID", "42")
+		r.Header.Set("User-Agent", "curl/8.0")
+		r.Header.Set("X-Debug-Log", token)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 4 {
+		t.Fatalf("want 3 access entries and a debug one, got %d", len(entries))
+	}
+	access := entries[0].ContextMap()
+	if entries[0].Message != "request completed" || access["status"] != int64(201) || access["size"] != int64(2) ||
+		access["method"] != "POST" || access["path"] != "/users" || access["request_id"] != "42" || access["user_agent"] != "curl/8.0" {
+		t.Errorf("unexpected access entry: %+v", entries[0])
+	}
+	if _, ok := access["debug_forced"]; ok {
+		t.Error("want debug not forced")
+	}
+	if entries[2].Message != "details" || entries[2].ContextMap()["debug_forced"] != true {
+		t.Errorf("unexpected debug entry: %+v", entries[2])
+	}
+}
+
+func TestHTTPMiddlewareRecovery(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	handler := log.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("want 500, got %d", w.Code)
+	}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want panic and access entries, got %d", len(entries))
+	}
+	if entries[0].Message != "recovered from panic" || !strings.HasSuffix(entries[0].Caller.File, "http_test.go") {
+		t.Errorf("unexpected panic entry: %+v", entries[0])
+	}
+	if entries[1].Level != ErrorLevel || entries[1].ContextMap()["status"] != int64(500) {
+		t.Errorf("unexpected access entry: %+v", entries[1])
+	}
+}
+
+func TestHTTPMiddlewareRoute(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	// Like chi, the router stores a mutable route context before the middlewares and fills it while routing
+	type routeKey struct{}
+	middleware := log.HTTPMiddleware(HTTPConfig{Route: func(r *http.Request) string {
+		return *r.Context().Value(routeKey{}).(*string)
+	}})
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*r.Context().Value(routeKey{}).(*string) = "/users/{id}"
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	r = r.WithContext(context.WithValue(r.Context(), routeKey{}, new(string)))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 || entries[0].ContextMap()["route"] != "/users/{id}" || entries[0].ContextMap()["path"] != "/users/42" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	handler := log.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = rw.Flush()
+	}))
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	r, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("want 101, got %d", resp.StatusCode)
+	}
+
+	<-done
+	entries := log.ObservedLogs().FilterMessage("request completed").AllUntimed()
+	if len(entries) != 1 || entries[0].ContextMap()["status"] != int64(http.StatusSwitchingProtocols) {
+		t.Errorf("want an access entry with the 101 status, got %+v", entries)
+	}
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const defaultBodyCaptureSize = 4096
+
+// defaultBodyContentTypes are the media types captured by default. Types ending with "/" match as prefixes
+var defaultBodyContentTypes = []string{"application/json", "application/problem+json", "application/x-www-form-urlencoded", "text/"}
+
+// defaultRedactKeys are the JSON keys and form parameters redacted by default
+var defaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token", "id_token",
+	"api_key", "apikey", "authorization", "card_number", "cvv",
+}
+
+// BodyCaptureConfig configures capturing request and response bodies by Logger.HTTPMiddleware.
+// Bodies are added to the access log of failed requests (4xx and 5xx) as the request_body and response_body fields
+type BodyCaptureConfig struct {
+	Enabled bool
+	// MaxSize is the number of bytes captured per body. Defaults to 4096.
+	// Truncated bodies are marked with the request_body_truncated and response_body_truncated fields
+	MaxSize int
+	// ContentTypes is the allowlist of captured media types, entries ending with "/" match as prefixes.
+	// Defaults to JSON, form and text types
+	ContentTypes []string
+	// RedactKeys are the JSON keys and form parameters whose values are replaced with "[REDACTED]", case-insensitive.
+	// Defaults to common credential names like "password" and "token"
+	RedactKeys []string
+	// Redact is applied to the captured bodies after RedactKeys, e.g. to mask personal data
+	Redact func(body []byte) []byte
+}
+
+// bodyCapturer captures bodies of a single middleware
+type bodyCapturer struct {
+	cfg          BodyCaptureConfig
+	contentTypes []string
+	jsonKeys     *regexp.Regexp
+	formKeys     *regexp.Regexp
+}
+
+func newBodyCapturer(cfg BodyCaptureConfig) *bodyCapturer {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultBodyCaptureSize
+	}
+	c := &bodyCapturer{cfg: cfg, contentTypes: cfg.ContentTypes}
+	if len(c.contentTypes) == 0 {
+		c.contentTypes = defaultBodyContentTypes
+	}
+
+	keys := cfg.RedactKeys
+	if len(keys) == 0 {
+		keys = defaultRedactKeys
+	}
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	alternation := strings.Join(quoted, "|")
+	c.jsonKeys = regexp.MustCompile(`(?i)("(?:` + alternation + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	c.formKeys = regexp.MustCompile(`(?i)((?:^|&)(?:` + alternation + `)=)[^&]*`)
+	return c
+}
+
+// allowed reports whether the media type of the Content-Type header value is in the allowlist
+func (c *bodyCapturer) allowed(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return false
+	}
+	for _, allowed := range c.contentTypes {
+		if mediaType == allowed || strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// captureRequest wraps the request body to capture what the handler reads
+func (c *bodyCapturer) captureRequest(r *http.Request) *capturedBody {
+	if c == nil || r.Body == nil || r.Body == http.NoBody || !c.allowed(r.Header.Get("Content-Type")) {
+		return nil
+	}
+	body := &capturedBody{max: c.cfg.MaxSize}
+	r.Body = &captureReader{ReadCloser: r.Body, body: body}
+	return body
+}
+
+// fields returns the fields of the captured bodies
+func (c *bodyCapturer) fields(request, response *capturedBody) []zap.Field {
+	var fields []zap.Field
+	for _, captured := range []struct {
+		key  string
+		body *capturedBody
+	}{{"request_body", request}, {"response_body", response}} {
+		if captured.body == nil || len(captured.body.data) == 0 {
+			continue
+		}
+		fields = append(fields, zap.String(captured.key, string(c.redact(captured.body.data))))
+		if captured.body.truncated {
+			fields = append(fields, zap.Bool(captured.key+"_truncated", true))
+		}
+	}
+	return fields
+}
+
+func (c *bodyCapturer) redact(body []byte) []byte {
+	body = c.jsonKeys.ReplaceAll(body, []byte(`$1"[REDACTED]"`))
+	body = c.formKeys.ReplaceAll(body, []byte(`$1[REDACTED]`))
+	if c.cfg.Redact != nil {
+		body = c.cfg.Redact(body)
+	}
+	return body
+}
+
+// capturedBody is the beginning of a body up to max bytes
+type capturedBody struct {
+	max       int
+	data      []byte
+	truncated bool
+}
+
+func (b *capturedBody) write(p []byte) {
+	if room := b.max - len(b.data); len(p) > room {
+		b.truncated = true
+		if room <= 0 {
+			return
+		}
+		p = p[:room]
+	}
+	b.data = append(b.data, p...)
+}
+
+// captureReader captures the data read from the request body
+type captureReader struct {
+	io.ReadCloser
+	body *capturedBody
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.body.write(p[:n])
+	return n, err
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddlewareBodies(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	handler := log.HTTPMiddleware(HTTPConfig{Bodies: BodyCaptureConfig{Enabled: true, MaxSize: 64}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/ok" {
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":"invalid card","token":"abc"}`))
+	}))
+
+	for _, path := range []string{"/ok", "/pay"} {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"card_number": 4242424242424242, "password":"p\"w", "amount":`+strings.Repeat("9", 64)+`}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		r.Header.Set("User-Agent", "curl/8.0")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 access entries, got %d", len(entries))
+	}
+	if _, ok := entries[0].ContextMap()["request_body"]; ok {
+		t.Errorf("want bodies of successful requests skipped, got %v", entries[0].ContextMap())
+	}
+
+	fields := entries[1].ContextMap()
+	if fields["user_agent"] != "curl/8.0" {
+		t.Errorf("want the user agent with the bodies, got %v", fields)
+	}
+	if want := `{"card_number": "[REDACTED]", "password":"[REDACTED]", "amount":99`; fields["request_body"] != want || fields["request_body_truncated"] != true {
+		t.Errorf("want request body %s, got %v", want, fields)
+	}
+	if want := `{"error":"invalid card","token":"[REDACTED]"}`; fields["response_body"] != want {
+		t.Errorf("want response body %s, got %v", want, fields["response_body"])
+	}
+	if _, ok := fields["response_body_truncated"]; ok {
+		t.Error("want the response body not truncated")
+	}
+}
+
+func TestBodyCapturerFilters(t *testing.T) {
+	c := newBodyCapturer(BodyCaptureConfig{Enabled: true, ContentTypes: []string{"application/json", "text/"}})
+	for contentType, want := range map[string]bool{
+		"application/json":         true,
+		"Text/Plain; charset=utf8": true,
+		"application/octet-stream": false,
+		"":                         false,
+	} {
+		if c.allowed(contentType) != want {
+			t.Errorf("want %q allowed %v", contentType, want)
+		}
+	}
+
+	if got := string(c.redact([]byte("user=bob&password=secret&token=x"))); got != "user=bob&password=[REDACTED]&token=[REDACTED]" {
+		t.Errorf("unexpected redacted form: %s", got)
+	}
+	if newBodyCapturer(BodyCaptureConfig{}) != nil {
+		t.Error("want capturing disabled by default")
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// byteSize is a number of bytes rendered like "1.4 MiB" by console encodings and as a number by JSON
+type byteSize int64
+
+// BytesSize returns a field with a number of bytes rendered like "1.4 MiB" by console encodings
+// and as a raw number by EncodingJSON
+func BytesSize(key string, n int64) zap.Field {
+	return zap.Field{Key: key, Type: zapcore.ReflectType, Interface: byteSize(n)}
+}
+
+// WithBytesSize returns a logger with a BytesSize field
+func (l *Logger) WithBytesSize(key string, n int64) *Logger {
+	return l.withFields(BytesSize(key, n))
+}
+
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+
+	value, exp := float64(n)/unit, 0
+	for abs /= unit; abs >= unit && exp < 5; abs /= unit {
+		value /= unit
+		exp++
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + "KMGTPE"[exp:exp+1] + "iB"
+}
+
+// humanizeDuration rounds the duration to 3 significant digits, e.g. "1.23s" or "35.1ms".
+// Durations of a minute and longer are rounded to seconds
+func humanizeDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs >= time.Minute {
+		return d.Round(time.Second).String()
+	}
+
+	precision := time.Duration(1)
+	for ; abs >= 1000; abs /= 10 {
+		precision *= 10
+	}
+	return d.Round(precision).String()
+}
+
+func humanDurationEncoder(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(humanizeDuration(d))
+}
+
+// humanizingEncoder renders byteSize fields humanized by a console encoder
+type humanizingEncoder struct {
+	zapcore.Encoder
+}
+
+func (e humanizingEncoder) Clone() zapcore.Encoder {
+	return humanizingEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e humanizingEncoder) AddReflected(key string, value interface{}) error {
+	if n, ok := value.(byteSize); ok {
+		e.Encoder.AddString(key, humanizeBytes(int64(n)))
+		return nil
+	}
+	return e.Encoder.AddReflected(key, value)
+}
+
+func (e humanizingEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	// Entry fields are added to a clone of the wrapped encoder, so they're replaced beforehand.
+	// The fields are copied, since they can be shared with other cores
+	var humanized []zapcore.Field
+	for i, f := range fields {
+		n, ok := f.Interface.(byteSize)
+		if !ok || f.Type != zapcore.ReflectType {
+			continue
+		}
+		if humanized == nil {
+			humanized = append([]zapcore.Field(nil), fields...)
+		}
+		humanized[i] = zap.String(f.Key, humanizeBytes(int64(n)))
+	}
+	if humanized == nil {
+		humanized = fields
+	}
+	return e.Encoder.EncodeEntry(ent, humanized)
+}
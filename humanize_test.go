@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:                      "0 B",
+		512:                    "512 B",
+		1536:                   "1.5 KiB",
+		1468006:                "1.4 MiB",
+		5 * 1024 * 1024 * 1024: "5.0 GiB",
+		-2048:                  "-2.0 KiB",
+	} {
+		if got := humanizeBytes(n); got != want {
+			t.Errorf("%d: want %s, got %s", n, want, got)
+		}
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		1234567 * time.Microsecond: "1.23s",
+		35123 * time.Microsecond:   "35.1ms",
+		999 * time.Nanosecond:      "999ns",
+		95500 * time.Millisecond:   "1m36s",
+	} {
+		if got := humanizeDuration(d); got != want {
+			t.Errorf("%s: want %s, got %s", d, want, got)
+		}
+	}
+}
+
+func TestHumanizedFields(t *testing.T) {
+	files := createTempFiles(t, "console.log", "json.log", "pretty.log")
+	for i, encoding := range []Encoding{EncodingConsole, EncodingJSON, EncodingPretty} {
+		log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Encoding: encoding, HumanizeDurations: true, Files: files[i : i+1]})
+		log.WithBytesSize("size", 1468006).WithField("took", 1234567*time.Microsecond).Info("done")
+		log.InfoFields("done", BytesSize("size", 2048))
+	}
+
+	checkFileLogs(t, files[0], [][]string{{`"size": "1.4 MiB"`, `"took": "1.23s"`}, {`"size": "2.0 KiB"`}})
+	checkFileLogs(t, files[1], [][]string{{`"size":1468006`, `"took":"1.234567s"`}, {`"size":2048`}})
+	checkFileLogs(t, files[2], [][]string{{`size="1.4 MiB"`, "took=1.23s"}, {`size="2.0 KiB"`}})
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Interface is the method set of *Logger.
+// Depend on it instead of *Logger to be able to substitute the logger (e.g. with loggermock.Logger).
+// Like logrus.FieldLogger, the With* methods return the concrete *Logger
+type Interface interface {
+	Named(name string) *Logger
+	WithCallerSkip(skip int) *Logger
+	WithField(key string, value interface{}) *Logger
+	WithError(err error) *Logger
+	WithFields(fields map[string]interface{}) *Logger
+	WithTime(t time.Time) *Logger
+	To(targets ...string) *Logger
+	Ctx(ctx context.Context) *Logger
+	CheckCtx(ctx context.Context) *Logger
+	WithMinLevel(lvl string) *Logger
+	Use(transformer ...Transformer) *Logger
+	WithErrorLevelMapper(mapper ErrorLevelMapper, levels ...zapcore.Level) *Logger
+	WithAccessLog(cfg AccessLogConfig) *Logger
+
+	SetLevel(lvl string)
+	TemporarilySetLevel(lvl string) (restore func())
+	ApplyLevels(cfg LevelConfig) error
+	Sync() error
+	Shutdown(ctx context.Context) error
+	Rotate() error
+	AddSink(name string, sink SinkConfig) error
+	RemoveSink(name string) error
+	RedriveDeadLetters() (int, error)
+
+	LogBatch(entries []Entry)
+	LogStartup(cfgSummary map[string]interface{})
+	Deprecated(name, hint string)
+	Event(lvl zapcore.Level) *Event
+
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Debugln(args ...interface{})
+
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Infoln(args ...interface{})
+
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Warnln(args ...interface{})
+
+	Warning(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Warningln(args ...interface{})
+
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Errorln(args ...interface{})
+	Errorr(err error, msg string) error
+
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Fatalln(args ...interface{})
+
+	Panic(args ...interface{})
+	Panicf(format string, args ...interface{})
+	Panicln(args ...interface{})
+	RecoverAndLog()
+
+	Print(args ...interface{})
+	Printf(format string, args ...interface{})
+	Println(args ...interface{})
+
+	DebugFields(msg string, fields ...zap.Field)
+	InfoFields(msg string, fields ...zap.Field)
+	WarnFields(msg string, fields ...zap.Field)
+	ErrorFields(msg string, fields ...zap.Field)
+	FatalFields(msg string, fields ...zap.Field)
+	PanicFields(msg string, fields ...zap.Field)
+}
+
+var _ Interface = (*Logger)(nil)
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// JobInfo describes a background job run, e.g. an asynq task
+type JobInfo struct {
+	ID    string
+	Type  string
+	Queue string
+	// Retry is the number of the retries done before this run, MaxRetry is the limit
+	Retry    int
+	MaxRetry int
+}
+
+// JobRun logs a single job run, see Logger.StartJob
+type JobRun struct {
+	log   *Logger
+	info  JobInfo
+	start time.Time
+}
+
+// StartJob logs the job start and returns a context with the job logger (see FromContext)
+// with the job.id, job.type, job.queue and job.retry fields.
+// A *Logger can be passed as the logger of asynq and machinery as is, while an asynq middleware is built as:
+//
+//	func(next asynq.Handler) asynq.Handler {
+//		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+//			id, _ := asynq.GetTaskID(ctx)
+//			queue, _ := asynq.GetQueueName(ctx)
+//			retry, _ := asynq.GetRetryCount(ctx)
+//			maxRetry, _ := asynq.GetMaxRetry(ctx)
+//			ctx, job := log.StartJob(ctx, logger.JobInfo{ID: id, Type: t.Type(), Queue: queue, Retry: retry, MaxRetry: maxRetry})
+//			defer func() { job.Finish(err) }()
+//			defer job.Recover(&err)
+//			return next.ProcessTask(ctx, t)
+//		})
+//	}
+func (l *Logger) StartJob(ctx context.Context, info JobInfo) (context.Context, *JobRun) {
+	fields := []zap.Field{zap.String("job.type", info.Type)}
+	if info.ID != "" {
+		fields = append(fields, zap.String("job.id", info.ID))
+	}
+	if info.Queue != "" {
+		fields = append(fields, zap.String("job.queue", info.Queue))
+	}
+	fields = append(fields, zap.Int("job.retry", info.Retry))
+
+	run := &JobRun{log: l.withFields(fields...), info: info, start: time.Now()}
+	run.log.base().WithOptions(zap.WithCaller(false)).Info("job started")
+	return ToContext(ctx, run.log), run
+}
+
+// Finish logs the job completion with the duration. Failures are logged at Warn level if the job will be retried
+// and at Error level otherwise
+func (r *JobRun) Finish(err error) {
+	log := r.log.base().WithOptions(zap.WithCaller(false))
+	duration := zap.Duration("duration", time.Since(r.start))
+	switch {
+	case err == nil:
+		log.Info("job finished", duration)
+	case r.info.Retry < r.info.MaxRetry:
+		log.Warn("job failed, will be retried", duration, zap.Error(err))
+	default:
+		log.Error("job failed", duration, zap.Error(err))
+	}
+}
+
+// Recover recovers a panic of the job, logs it and sets the error. It must be deferred directly:
+//
+//	defer job.Recover(&err)
+func (r *JobRun) Recover(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	r.log.logRecovered(v, 0)
+	*err = errors.Errorf("job panicked: %v", v)
+}
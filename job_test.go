@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// asynqLogger is the Logger interface of asynq
+type asynqLogger interface {
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+	Fatal(args ...interface{})
+}
+
+var _ asynqLogger = (*Logger)(nil)
+
+// runJob imitates a job framework middleware around the handler
+func runJob(log *Logger, info JobInfo, handler func(ctx context.Context) error) (err error) {
+	ctx, job := log.StartJob(context.Background(), info)
+	defer func() { job.Finish(err) }()
+	defer job.Recover(&err)
+	return handler(ctx)
+}
+
+func TestStartJob(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	_ = runJob(log, JobInfo{ID: "1", Type: "email:send", Queue: "default"}, func(ctx context.Context) error {
+		FromContext(ctx).Info("sending")
+		return nil
+	})
+	_ = runJob(log, JobInfo{ID: "2", Type: "email:send", Retry: 1, MaxRetry: 3}, func(ctx context.Context) error {
+		return errors.New("smtp is down")
+	})
+	err := runJob(log, JobInfo{ID: "3", Type: "email:send", Retry: 3, MaxRetry: 3}, func(ctx context.Context) error {
+		panic("boom")
+	})
+	if err == nil || err.Error() != "job panicked: boom" {
+		t.Errorf("want the panic error, got %v", err)
+	}
+
+	want := []struct {
+		msg string
+		lvl zapcore.Level
+	}{
+		{"job started", InfoLevel},
+		{"sending", InfoLevel},
+		{"job finished", InfoLevel},
+		{"job started", InfoLevel},
+		{"job failed, will be retried", WarnLevel},
+		{"job started", InfoLevel},
+		{"recovered from panic", ErrorLevel},
+		{"job failed", ErrorLevel},
+	}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %d", len(want), len(entries))
+	}
+	for i, e := range entries {
+		if e.Message != want[i].msg || e.Level != want[i].lvl {
+			t.Errorf("want %q at %s, got %q at %s", want[i].msg, want[i].lvl, e.Message, e.Level)
+		}
+	}
+	if fields := entries[1].ContextMap(); fields["job.id"] != "1" || fields["job.type"] != "email:send" || fields["job.queue"] != "default" || fields["job.retry"] != int64(0) {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if !strings.HasSuffix(entries[6].Caller.File, "job_test.go") {
+		t.Errorf("want the panic caller, got %s", entries[6].Caller.File)
+	}
+}
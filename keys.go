@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// KeyCase is a case field keys are converted to
+type KeyCase string
+
+const (
+	// KeyCaseAsIs keeps keys as they are
+	KeyCaseAsIs KeyCase = ""
+	// KeyCaseSnake converts keys to snake_case, e.g. "userID" to "user_id"
+	KeyCaseSnake KeyCase = "snake"
+	// KeyCaseCamel converts keys to camelCase, e.g. "user_id" to "userId"
+	KeyCaseCamel KeyCase = "camel"
+)
+
+func (c KeyCase) valid() bool {
+	switch c {
+	case KeyCaseAsIs, KeyCaseSnake, KeyCaseCamel:
+		return true
+	default:
+		return false
+	}
+}
+
+// KeyPolicy normalizes keys of all the fields, so output conforms to a schema regardless of call sites.
+// Dots are kept, so namespaced keys like "error.code" are converted part by part
+type KeyPolicy struct {
+	// StripPrefixes are removed from the beginning of keys (the first matching one), e.g. "app_"
+	StripPrefixes []string
+	// Case converts keys to the case
+	Case KeyCase
+	// Reserved are keys colliding with the entry keys of the encoding.
+	// Defaults to the EncodingJSON keys: ts, level, logger, caller, msg and stacktrace
+	Reserved []string
+	// ReservedPrefix is added to reserved keys. Defaults to "field_"
+	ReservedPrefix string
+}
+
+func (p KeyPolicy) enabled() bool {
+	return len(p.StripPrefixes) != 0 || p.Case != KeyCaseAsIs || len(p.Reserved) != 0 || p.ReservedPrefix != ""
+}
+
+// keyNormalizer applies a KeyPolicy
+type keyNormalizer struct {
+	policy   KeyPolicy
+	reserved map[string]bool
+}
+
+func newKeyNormalizer(policy KeyPolicy) *keyNormalizer {
+	if policy.Reserved == nil {
+		policy.Reserved = []string{"ts", "level", "logger", "caller", "msg", "stacktrace"}
+	}
+	if policy.ReservedPrefix == "" {
+		policy.ReservedPrefix = "field_"
+	}
+
+	n := &keyNormalizer{policy: policy, reserved: make(map[string]bool, len(policy.Reserved))}
+	for _, key := range policy.Reserved {
+		n.reserved[key] = true
+	}
+	return n
+}
+
+func (n *keyNormalizer) normalize(key string) string {
+	for _, prefix := range n.policy.StripPrefixes {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			key = key[len(prefix):]
+			break
+		}
+	}
+
+	switch n.policy.Case {
+	case KeyCaseSnake:
+		key = mapKeyParts(key, snakeCase)
+	case KeyCaseCamel:
+		key = mapKeyParts(key, camelCase)
+	}
+
+	if n.reserved[key] {
+		key = n.policy.ReservedPrefix + key
+	}
+	return key
+}
+
+// fields returns the fields with normalized keys. The slice is copied only if any key changes
+func (n *keyNormalizer) fields(fields []zapcore.Field) []zapcore.Field {
+	var normalized []zapcore.Field
+	for i, f := range fields {
+		if f.Type == zapcore.SkipType {
+			continue
+		}
+		key := n.normalize(f.Key)
+		if key == f.Key {
+			continue
+		}
+		if normalized == nil {
+			normalized = append([]zapcore.Field(nil), fields...)
+		}
+		normalized[i].Key = key
+	}
+	if normalized == nil {
+		return fields
+	}
+	return normalized
+}
+
+func mapKeyParts(key string, convert func(string) string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = convert(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// keyWords splits a key into lowercase words by separators and case changes, e.g. "HTTPStatus_code" to [http status code]
+func keyWords(key string) []string {
+	runes := []rune(key)
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) != 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+func snakeCase(key string) string {
+	return strings.Join(keyWords(key), "_")
+}
+
+func camelCase(key string) string {
+	words := keyWords(key)
+	for i := 1; i < len(words); i++ {
+		runes := []rune(words[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, "")
+}
+
+// keyCore normalizes keys of the logger and entry fields.
+// Entry fields aren't passed to Check, so writes are deferred to Write
+type keyCore struct {
+	core       zapcore.Core
+	normalizer *keyNormalizer
+}
+
+func (c *keyCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *keyCore) With(fields []zapcore.Field) zapcore.Core {
+	return &keyCore{core: c.core.With(c.normalizer.fields(fields)), normalizer: c.normalizer}
+}
+
+func (c *keyCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *keyCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(c.normalizer.fields(fields)...)
+	}
+	return nil
+}
+
+func (c *keyCore) Sync() error {
+	return c.core.Sync()
+}
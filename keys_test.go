@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestKeyCase(t *testing.T) {
+	for key, want := range map[string][2]string{
+		"userID":         {"user_id", "userId"},
+		"HTTPStatusCode": {"http_status_code", "httpStatusCode"},
+		"user-name":      {"user_name", "userName"},
+		"error.code_v2":  {"error.code_v2", "error.codeV2"},
+		"already_snake":  {"already_snake", "alreadySnake"},
+	} {
+		if got := mapKeyParts(key, snakeCase); got != want[0] {
+			t.Errorf("snake %s: want %s, got %s", key, want[0], got)
+		}
+		if got := mapKeyParts(key, camelCase); got != want[1] {
+			t.Errorf("camel %s: want %s, got %s", key, want[1], got)
+		}
+	}
+}
+
+func TestKeyPolicy(t *testing.T) {
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Observe:       true,
+		Keys:          KeyPolicy{StripPrefixes: []string{"app_"}, Case: KeyCaseSnake},
+	})
+
+	log.WithField("app_userID", 1).WithFields(map[string]interface{}{"msg": "x"}).InfoFields("hi", zap.Int("requestCount", 2))
+
+	fields := log.ObservedLogs().All()[0].ContextMap()
+	for _, key := range []string{"user_id", "field_msg", "request_count"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("want %s in %v", key, fields)
+		}
+	}
+}
+
+func TestUnknownKeyCase(t *testing.T) {
+	if _, err := New(Config{DisableStdOut: true, Keys: KeyPolicy{Case: "kebab"}}); err == nil {
+		t.Error("want an error")
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// KVStoreLogger adapts a logger to the logger interfaces of embedded key-value stores:
+// badger.Logger, pebble.Logger (and pebble.LoggerAndTracer) and bbolt.Logger:
+//
+//	opts := badger.DefaultOptions(dir).WithLogger(logger.NewKVStoreLogger(log, "badger"))
+//	db, err := pebble.Open(dir, &pebble.Options{Logger: logger.NewKVStoreLogger(log, "pebble")})
+//	db, err := bolt.Open(path, 0o600, &bolt.Options{Logger: logger.NewKVStoreLogger(log, "bbolt")})
+//
+// The stores report routine work (compactions, GC, flushes) as info messages, so they're logged at Debug level.
+// Entries get the "kvstore" field with the store name, trailing newlines are trimmed.
+// *Logger implements the interfaces too, use it directly to keep the store levels
+type KVStoreLogger struct {
+	log *Logger
+}
+
+// NewKVStoreLogger creates a logger of the named store
+func NewKVStoreLogger(l *Logger, store string) *KVStoreLogger {
+	return &KVStoreLogger{log: l.withFields(zap.String("kvstore", store)).withOptions(zap.WithCaller(false))}
+}
+
+func (k *KVStoreLogger) Debug(args ...interface{})                 { k.write(DebugLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Debugf(format string, args ...interface{}) { k.logf(DebugLevel, format, args) }
+func (k *KVStoreLogger) Info(args ...interface{})                  { k.write(DebugLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Infof(format string, args ...interface{})  { k.logf(DebugLevel, format, args) }
+func (k *KVStoreLogger) Warning(args ...interface{})               { k.write(WarnLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Warningf(format string, args ...interface{}) {
+	k.logf(WarnLevel, format, args)
+}
+func (k *KVStoreLogger) Error(args ...interface{})                 { k.write(ErrorLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Errorf(format string, args ...interface{}) { k.logf(ErrorLevel, format, args) }
+func (k *KVStoreLogger) Fatal(args ...interface{})                 { k.write(FatalLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Fatalf(format string, args ...interface{}) { k.logf(FatalLevel, format, args) }
+func (k *KVStoreLogger) Panic(args ...interface{})                 { k.write(PanicLevel, fmt.Sprint(args...)) }
+func (k *KVStoreLogger) Panicf(format string, args ...interface{}) { k.logf(PanicLevel, format, args) }
+
+// Eventf logs a pebble trace event at Debug level
+func (k *KVStoreLogger) Eventf(ctx context.Context, format string, args ...interface{}) {
+	k.logf(DebugLevel, format, args)
+}
+
+// IsTracingEnabled reports whether pebble trace events are logged
+func (k *KVStoreLogger) IsTracingEnabled(ctx context.Context) bool {
+	return k.log.base().Core().Enabled(DebugLevel)
+}
+
+func (k *KVStoreLogger) logf(lvl zapcore.Level, format string, args []interface{}) {
+	if !k.log.base().Core().Enabled(lvl) {
+		return
+	}
+	k.write(lvl, fmt.Sprintf(format, args...))
+}
+
+func (k *KVStoreLogger) write(lvl zapcore.Level, msg string) {
+	if ce := k.log.base().Check(lvl, strings.TrimRight(msg, "\n")); ce != nil {
+		ce.Write()
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// badgerLogger is the badger.Logger interface
+type badgerLogger interface {
+	Errorf(string, ...interface{})
+	Warningf(string, ...interface{})
+	Infof(string, ...interface{})
+	Debugf(string, ...interface{})
+}
+
+// pebbleLogger is the pebble.LoggerAndTracer interface
+type pebbleLogger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Eventf(ctx context.Context, format string, args ...interface{})
+	IsTracingEnabled(ctx context.Context) bool
+}
+
+// bboltLogger is the bbolt.Logger interface
+type bboltLogger interface {
+	Debug(v ...interface{})
+	Debugf(format string, v ...interface{})
+	Error(v ...interface{})
+	Errorf(format string, v ...interface{})
+	Info(v ...interface{})
+	Infof(format string, v ...interface{})
+	Warning(v ...interface{})
+	Warningf(format string, v ...interface{})
+	Fatal(v ...interface{})
+	Fatalf(format string, v ...interface{})
+	Panic(v ...interface{})
+	Panicf(format string, v ...interface{})
+}
+
+var (
+	_ badgerLogger = (*KVStoreLogger)(nil)
+	_ pebbleLogger = (*KVStoreLogger)(nil)
+	_ bboltLogger  = (*KVStoreLogger)(nil)
+	_ badgerLogger = (*Logger)(nil)
+	_ bboltLogger  = (*Logger)(nil)
+)
+
+func TestKVStoreLogger(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	kv := NewKVStoreLogger(log, "badger")
+	kv.Infof("[Compactor: %d] Running compaction\n", 0)
+	kv.Warningf("Block cache might be too small\n")
+	kv.Errorf("Failure while flushing memtable: %v\n", "disk full")
+	if !kv.IsTracingEnabled(context.Background()) {
+		t.Error("want tracing enabled at Debug level")
+	}
+
+	want := []struct {
+		msg string
+		lvl zapcore.Level
+	}{
+		{"[Compactor: 0] Running compaction", DebugLevel},
+		{"Block cache might be too small", WarnLevel},
+		{"Failure while flushing memtable: disk full", ErrorLevel},
+	}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %d", len(want), len(entries))
+	}
+	for i, e := range entries {
+		if e.Message != want[i].msg || e.Level != want[i].lvl || e.ContextMap()["kvstore"] != "badger" {
+			t.Errorf("unexpected entry: %+v", e)
+		}
+	}
+}
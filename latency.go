@@ -0,0 +1,237 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultLatencyBuckets are the histogram bucket upper bounds of LatencyConfig
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// LatencyConfig configures LatencyHistograms
+type LatencyConfig struct {
+	// Field is the duration field to observe. Defaults to "latency" written by HTTPMiddleware and StartRPC
+	Field string
+	// KeyField groups histograms by the value of the field (e.g. "http.route" or "rpc.method"),
+	// entries without it are grouped by the message. Histograms are grouped by the message if it's empty
+	KeyField string
+	// Buckets are the bucket upper bounds in ascending order. Defaults to DefaultLatencyBuckets
+	Buckets []time.Duration
+	// Name is the metric name served by LatencyHistograms.Handler. Defaults to "log_latency_seconds"
+	Name string
+	// MaxKeys caps the number of histograms, since keys like messages with formatted values aren't bounded.
+	// Observations of new keys over the cap go to the extra LatencyOtherKey histogram. Defaults to 100
+	MaxKeys int
+}
+
+// LatencyOtherKey is the key of the histogram of observations over LatencyConfig.MaxKeys
+const LatencyOtherKey = "other"
+
+// LatencyHistograms derives latency histograms from the duration field of entries, grouped by message or a key field,
+// so latency metrics don't need separate instrumentation. Its core is added with Config.Cores:
+//
+//	latency := logger.NewLatencyHistograms(logger.LatencyConfig{KeyField: "rpc.method"})
+//	log, err := logger.New(logger.Config{Cores: []zapcore.Core{latency.Core()}})
+//	mux.Handle("/metrics/latency", latency.Handler())
+//	expvar.Publish("latency", latency)
+type LatencyHistograms struct {
+	cfg LatencyConfig
+
+	mu    sync.Mutex
+	hists map[string]*LatencyHistogram
+}
+
+// LatencyHistogram is a snapshot of a histogram of LatencyHistograms
+type LatencyHistogram struct {
+	Key     string          `json:"key"`
+	Buckets []time.Duration `json:"buckets"`
+	// Counts are the numbers of observations in every bucket (not cumulative),
+	// the last one counts observations over the last bucket bound
+	Counts []uint64      `json:"counts"`
+	Count  uint64        `json:"count"`
+	Sum    time.Duration `json:"sum"`
+}
+
+// NewLatencyHistograms creates empty histograms
+func NewLatencyHistograms(cfg LatencyConfig) *LatencyHistograms {
+	if cfg.Field == "" {
+		cfg.Field = "latency"
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultLatencyBuckets
+	}
+	if cfg.Name == "" {
+		cfg.Name = "log_latency_seconds"
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = 100
+	}
+	return &LatencyHistograms{cfg: cfg, hists: make(map[string]*LatencyHistogram)}
+}
+
+// Core returns a core observing entries of all levels with the duration field
+func (h *LatencyHistograms) Core() zapcore.Core {
+	return &latencyCore{hists: h}
+}
+
+func (h *LatencyHistograms) observe(key string, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist, ok := h.hists[key]
+	if !ok && len(h.hists) >= h.cfg.MaxKeys {
+		key = LatencyOtherKey
+		hist, ok = h.hists[key]
+	}
+	if !ok {
+		hist = &LatencyHistogram{Key: key, Buckets: h.cfg.Buckets, Counts: make([]uint64, len(h.cfg.Buckets)+1)}
+		h.hists[key] = hist
+	}
+	hist.Counts[sort.Search(len(hist.Buckets), func(i int) bool { return d <= hist.Buckets[i] })]++
+	hist.Count++
+	hist.Sum += d
+}
+
+// Snapshot returns copies of the histograms sorted by key
+func (h *LatencyHistograms) Snapshot() []LatencyHistogram {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := make([]LatencyHistogram, 0, len(h.hists))
+	for _, key := range sortedKeys(h.hists) {
+		hist := *h.hists[key]
+		hist.Counts = append([]uint64(nil), hist.Counts...)
+		snapshot = append(snapshot, hist)
+	}
+	return snapshot
+}
+
+// String returns the JSON snapshot, so the histograms can be published with expvar.Publish
+func (h *LatencyHistograms) String() string {
+	data, err := json.Marshal(h.Snapshot())
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// Handler returns an HTTP handler serving the histograms in the Prometheus text format with the "key" label
+func (h *LatencyHistograms) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = h.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes the histograms in the Prometheus text format
+func (h *LatencyHistograms) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+	name := h.cfg.Name
+	fmt.Fprintf(&b, "# TYPE %s histogram\n", name)
+	for _, hist := range h.Snapshot() {
+		key := promLabelValue(hist.Key)
+		var cumulative uint64
+		for i, bound := range hist.Buckets {
+			cumulative += hist.Counts[i]
+			fmt.Fprintf(&b, "%s_bucket{key=\"%s\",le=\"%s\"} %d\n", name, key, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{key=\"%s\",le=\"+Inf\"} %d\n", name, key, hist.Count)
+		fmt.Fprintf(&b, "%s_sum{key=\"%s\"} %s\n", name, key, strconv.FormatFloat(hist.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{key=\"%s\"} %d\n", name, key, hist.Count)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Stats returns a collector of the count and the estimated median and 99th percentile of every histogram
+// as the latency.<key>.* fields, see Logger.LogStatsEvery
+func (h *LatencyHistograms) Stats() StatsCollector {
+	return func() map[string]interface{} {
+		stats := make(map[string]interface{})
+		for _, hist := range h.Snapshot() {
+			prefix := "latency." + hist.Key + "."
+			stats[prefix+"count"] = hist.Count
+			stats[prefix+"p50"] = hist.Quantile(0.5)
+			stats[prefix+"p99"] = hist.Quantile(0.99)
+		}
+		return stats
+	}
+}
+
+// Quantile estimates the quantile as the upper bound of the bucket containing it.
+// It's the last bucket bound for observations over it
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.Count) + 0.5)
+	if rank == 0 {
+		rank = 1
+	}
+	var cumulative uint64
+	for i, bound := range h.Buckets {
+		if cumulative += h.Counts[i]; cumulative >= rank {
+			return bound
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+// promLabelValue escapes a Prometheus label value
+func promLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// latencyCore observes the duration field of entries in LatencyHistograms
+type latencyCore struct {
+	hists  *LatencyHistograms
+	fields []zapcore.Field
+}
+
+func (c *latencyCore) Enabled(zapcore.Level) bool { return true }
+
+func (c *latencyCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *latencyCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *latencyCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	cfg := c.hists.cfg
+	var latency time.Duration
+	var found bool
+	key := ent.Message
+	for _, group := range [][]zapcore.Field{c.fields, fields} {
+		for _, f := range group {
+			switch {
+			case f.Key == cfg.Field && f.Type == zapcore.DurationType:
+				latency, found = time.Duration(f.Integer), true
+			case f.Key == cfg.KeyField && cfg.KeyField != "" && f.Type == zapcore.StringType:
+				key = f.String
+			}
+		}
+	}
+	if found {
+		c.hists.observe(key, latency)
+	}
+	return nil
+}
+
+func (c *latencyCore) Sync() error { return nil }
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLatencyHistograms(t *testing.T) {
+	latency := NewLatencyHistograms(LatencyConfig{KeyField: "route", Buckets: []time.Duration{10 * time.Millisecond, 100 * time.Millisecond}})
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{latency.Core()}})
+
+	users := log.WithField("route", "/users")
+	users.WithField("latency", 5*time.Millisecond).Info("request completed")
+	users.WithField("latency", 50*time.Millisecond).Info("request completed")
+	users.WithField("latency", time.Second).Warn("request completed")
+	log.WithField("latency", 20*time.Millisecond).Info("query")
+	log.WithField("latency", "not a duration").Info("query")
+	log.Info("without latency")
+
+	snapshot := latency.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Key != "/users" || snapshot[1].Key != "query" {
+		t.Fatalf("unexpected histograms: %+v", snapshot)
+	}
+	users0 := snapshot[0]
+	if users0.Count != 3 || users0.Sum != 1055*time.Millisecond || users0.Counts[0] != 1 || users0.Counts[1] != 1 || users0.Counts[2] != 1 {
+		t.Errorf("unexpected histogram: %+v", users0)
+	}
+	if p50 := users0.Quantile(0.5); p50 != 100*time.Millisecond {
+		t.Errorf("want p50 in the second bucket, got %s", p50)
+	}
+
+	rec := httptest.NewRecorder()
+	latency.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`log_latency_seconds_bucket{key="/users",le="0.01"} 1`,
+		`log_latency_seconds_bucket{key="/users",le="0.1"} 2`,
+		`log_latency_seconds_bucket{key="/users",le="+Inf"} 3`,
+		`log_latency_seconds_sum{key="/users"} 1.055`,
+		`log_latency_seconds_count{key="query"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("want %q in:\n%s", want, body)
+		}
+	}
+
+	if stats := latency.Stats()(); stats["latency.query.count"] != uint64(1) || stats["latency./users.p99"] != 100*time.Millisecond {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+	if !strings.Contains(latency.String(), `"key":"query"`) {
+		t.Errorf("unexpected expvar value: %s", latency.String())
+	}
+}
+
+func TestLatencyHistogramsMaxKeys(t *testing.T) {
+	latency := NewLatencyHistograms(LatencyConfig{MaxKeys: 2})
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{latency.Core()}})
+
+	for _, msg := range []string{"first", "second", "user 1 loaded", "user 2 loaded", "first"} {
+		log.WithField("latency", time.Millisecond).Info(msg)
+	}
+
+	snapshot := latency.Snapshot()
+	if len(snapshot) != 3 || snapshot[0].Key != "first" || snapshot[0].Count != 2 ||
+		snapshot[1].Key != LatencyOtherKey || snapshot[1].Count != 2 || snapshot[2].Key != "second" {
+		t.Errorf("want the keys over the cap in the other histogram, got %+v", snapshot)
+	}
+}
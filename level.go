@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// parseLevel parses a level name like SetLevel does, "trace" is treated as debug
+func parseLevel(lvl string) (zapcore.Level, error) {
+	if lvl == "trace" || lvl == "TRACE" {
+		// zap doesn't have a trace level. See TODO for more info
+		lvl = "debug"
+	}
+
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(lvl)); err != nil {
+		return zapLevel, errors.Wrapf(err, "failed to parse level %q", lvl)
+	}
+	return zapLevel, nil
+}
+
+// levelOverrides is an immutable set of level overrides by logger name and caller package
+type levelOverrides struct {
+	packages map[string]zapcore.Level
+	names    map[string]zapcore.Level
+	min      zapcore.Level // the minimum level among overrides
+
+	callers sync.Map // caller PC -> packageLevel cache
+}
+
+type packageLevel struct {
+	level zapcore.Level
+	found bool
+}
+
+func newLevelOverrides(packages, names map[string]string) (*levelOverrides, error) {
+	o := &levelOverrides{
+		packages: make(map[string]zapcore.Level, len(packages)),
+		names:    make(map[string]zapcore.Level, len(names)),
+		min:      zapcore.FatalLevel,
+	}
+	for pkg, name := range packages {
+		lvl, err := parseLevel(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level of package %s", pkg)
+		}
+		o.packages[strings.TrimSuffix(pkg, "/")] = lvl
+	}
+	for loggerName, name := range names {
+		lvl, err := parseLevel(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level of logger %s", loggerName)
+		}
+		o.names[loggerName] = lvl
+	}
+
+	for _, levels := range []map[string]zapcore.Level{o.packages, o.names} {
+		for _, lvl := range levels {
+			if lvl < o.min {
+				o.min = lvl
+			}
+		}
+	}
+	return o, nil
+}
+
+// withNames returns overrides with the same packages and the new names
+func (o *levelOverrides) withNames(names map[string]string) (*levelOverrides, error) {
+	packages := make(map[string]string, len(o.packages))
+	for pkg, lvl := range o.packages {
+		packages[pkg] = lvl.String()
+	}
+	return newLevelOverrides(packages, names)
+}
+
+func (o *levelOverrides) empty() bool {
+	return o == nil || len(o.packages) == 0 && len(o.names) == 0
+}
+
+// nameLevel returns the level of the longest matching logger name.
+// Names match hierarchically, i.e. "db" matches "db" and "db.pool" loggers
+func (o *levelOverrides) nameLevel(name string) (zapcore.Level, bool) {
+	if len(o.names) == 0 || name == "" {
+		return 0, false
+	}
+	for {
+		if lvl, ok := o.names[name]; ok {
+			return lvl, true
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			return 0, false
+		}
+		name = name[:dot]
+	}
+}
+
+// callerLevel returns the level of the longest package prefix matching the caller
+func (o *levelOverrides) callerLevel(caller zapcore.EntryCaller) (zapcore.Level, bool) {
+	if len(o.packages) == 0 || !caller.Defined {
+		return 0, false
+	}
+	if cached, ok := o.callers.Load(caller.PC); ok {
+		pl := cached.(packageLevel)
+		return pl.level, pl.found
+	}
+
+	function := caller.Function
+	if function == "" {
+		if fn := runtime.FuncForPC(caller.PC); fn != nil {
+			function = fn.Name()
+		}
+	}
+	pkg := packageOf(function)
+
+	var pl packageLevel
+	matched := -1
+	for prefix, lvl := range o.packages {
+		if len(prefix) > matched && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) {
+			pl = packageLevel{level: lvl, found: true}
+			matched = len(prefix)
+		}
+	}
+
+	o.callers.Store(caller.PC, pl)
+	return pl.level, pl.found
+}
+
+// packageOf returns the import path of a function name like "github.com/org/app/db.(*Repo).Get"
+func packageOf(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
+
+// levelCore decides whether entries are enabled, applying level overrides on top of the global level.
+// Logger name overrides take precedence over caller package ones.
+// Overrides by caller package can't be applied in Check, since zap adds the caller later,
+// so the decision is deferred to Write when there are any
+type levelCore struct {
+	core      zapcore.Core
+	level     zap.AtomicLevel
+	overrides *atomic.Value // *levelOverrides
+	// min is the level set by Logger.WithMinLevel. It takes precedence over the global level and overrides
+	min    zapcore.Level
+	hasMin bool
+}
+
+func newLevelCore(core zapcore.Core, level zap.AtomicLevel, overrides *atomic.Value) *levelCore {
+	return &levelCore{core: core, level: level, overrides: overrides}
+}
+
+func (c *levelCore) loadOverrides() *levelOverrides {
+	return c.overrides.Load().(*levelOverrides)
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	if c.hasMin {
+		return lvl >= c.min
+	}
+	if c.level.Enabled(lvl) {
+		return true
+	}
+	overrides := c.loadOverrides()
+	return !overrides.empty() && lvl >= overrides.min
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	rest := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if min, ok := f.Interface.(minLevel); ok && f.Type == zapcore.SkipType {
+			clone.min, clone.hasMin = zapcore.Level(min), true
+			continue
+		}
+		rest = append(rest, f)
+	}
+	clone.core = c.core.With(rest)
+	return &clone
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.hasMin {
+		if ent.Level < c.min {
+			return ce
+		}
+		return c.core.Check(ent, ce)
+	}
+
+	overrides := c.loadOverrides()
+
+	lvl, ok := overrides.nameLevel(ent.LoggerName)
+	if !ok && len(overrides.packages) != 0 {
+		if c.Enabled(ent.Level) {
+			return ce.AddCore(ent, c)
+		}
+		return ce
+	}
+	if !ok {
+		lvl = c.level.Level()
+	}
+
+	if ent.Level < lvl {
+		return ce
+	}
+	return c.core.Check(ent, ce)
+}
+
+// Write is called only for entries deferred by Check
+func (c *levelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	lvl, ok := c.loadOverrides().callerLevel(ent.Caller)
+	if !ok {
+		lvl = c.level.Level()
+	}
+	if ent.Level < lvl {
+		return nil
+	}
+
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *levelCore) Sync() error {
+	return c.core.Sync()
+}
+
+// minLevel is a hidden field value carrying the level set by Logger.WithMinLevel
+type minLevel zapcore.Level
+
+func minLevelField(lvl zapcore.Level) zap.Field {
+	return zap.Field{Key: "min_level", Type: zapcore.SkipType, Interface: minLevel(lvl)}
+}
+
+// WithMinLevel returns a logger with its own level, independent of the global level and overrides,
+// so a single request or code block can be traced verbosely. Invalid levels are ignored
+func (l *Logger) WithMinLevel(lvl string) *Logger {
+	zapLevel, err := parseLevel(lvl)
+	if err != nil {
+		return l
+	}
+	return l.withFields(minLevelField(zapLevel))
+}
+
+// TemporarilySetLevel sets the global level until the returned restore function is called, e.g.
+//
+//	defer l.TemporarilySetLevel("debug")()
+//
+// Invalid levels are ignored. Overlapping temporary changes must be restored in reverse order
+func (l *Logger) TemporarilySetLevel(lvl string) (restore func()) {
+	if l == nil {
+		return func() {}
+	}
+	previous := l.level.Level()
+	l.SetLevel(lvl)
+	return func() { l.level.SetLevel(previous) }
+}
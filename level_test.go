@@ -0,0 +1,71 @@
+package logger
+
+import "testing"
+
+func TestPackageLevels(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Files:         []string{filename},
+		PackageLevels: map[string]string{
+			"github.com/kiteggrad":              "error",
+			"github.com/kiteggrad/logger":       "warn",
+			"github.com/kiteggrad/logger/other": "debug",
+		},
+	})
+
+	log.Info("skipped")
+	log.Warn("warn")
+
+	log.SetLevel("fatal")
+	log.Debug("skipped")
+	log.WithField("a", 1).Error("error")
+
+	checkFileLogs(t, filename, [][]string{
+		{"warn"},
+		{`error	{"a": 1}`},
+	})
+}
+
+func TestPackageOf(t *testing.T) {
+	for function, want := range map[string]string{
+		"github.com/org/app/db.(*Repo).Get": "github.com/org/app/db",
+		"github.com/org/app/db.Get.func1":   "github.com/org/app/db",
+		"main.main":                         "main",
+		"runtime.goexit":                    "runtime",
+	} {
+		if got := packageOf(function); got != want {
+			t.Errorf("packageOf(%s): want %s, got %s", function, want, got)
+		}
+	}
+}
+
+func TestWithMinLevel(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("warn")
+
+	traced := log.WithMinLevel("debug").WithField("a", 1)
+	traced.Debug("traced")
+	log.Debug("skipped")
+	log.WithMinLevel("error").Warn("skipped")
+	log.WithMinLevel("invalid").Info("skipped")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 || entries[0].Message != "traced" || entries[0].ContextMap()["a"] != int64(1) {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestTemporarilySetLevel(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true})
+	log.SetLevel("info")
+
+	restore := log.TemporarilySetLevel("debug")
+	if lvl := log.level.Level(); lvl != DebugLevel {
+		t.Errorf("want debug, got %s", lvl)
+	}
+	restore()
+	if lvl := log.level.Level(); lvl != InfoLevel {
+		t.Errorf("want info, got %s", lvl)
+	}
+}
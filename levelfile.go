@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultLevelFileInterval is the LevelFile polling interval used if Config.LevelFileInterval isn't set
+const defaultLevelFileInterval = 5 * time.Second
+
+// levelFileWatcher applies the level from a file whenever the file content changes
+type levelFileWatcher struct {
+	path  string
+	level zap.AtomicLevel
+	last  string
+}
+
+// watchLevelFile applies the level from the file and keeps polling it until the returned stop function is called.
+// Missing files and invalid levels are ignored, keeping the current level
+func watchLevelFile(path string, interval time.Duration, level zap.AtomicLevel) (stop func() error) {
+	if interval <= 0 {
+		interval = defaultLevelFileInterval
+	}
+
+	w := &levelFileWatcher{path: path, level: level}
+	w.apply()
+
+	return runEvery(interval, w.apply)
+}
+
+func (w *levelFileWatcher) apply() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == w.last {
+		return
+	}
+	w.last = content
+
+	if lvl, err := parseLevel(content); err == nil {
+		w.level.SetLevel(lvl)
+	}
+}
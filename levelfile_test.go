@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLevelFile(t *testing.T) {
+	files := createTempFiles(t, "1.log", "level")
+	filename, levelFile := files[0], files[1]
+
+	if err := os.WriteFile(levelFile, []byte("error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := newLogger(t, Config{
+		DisableStdOut:     true,
+		Files:             []string{filename},
+		LevelFile:         levelFile,
+		LevelFileInterval: 5 * time.Millisecond,
+	})
+	defer log.Shutdown(context.Background())
+
+	log.Info("skipped")
+
+	if err := os.WriteFile(levelFile, []byte("info"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	log.Debug("skipped")
+	log.Info("info")
+
+	checkFileLogs(t, filename, [][]string{{"info"}})
+}
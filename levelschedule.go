@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelScheduleInterval is the interval of checking Config.LevelSchedule windows
+const levelScheduleInterval = 10 * time.Second
+
+// LevelWindow sets the global level for Duration after the times matching Cron, e.g. debug during
+// a maintenance window: {Cron: "0 2 * * SAT", Duration: 2 * time.Hour, Level: "debug"}
+type LevelWindow struct {
+	// Cron is a standard 5-field cron expression (minute, hour, day of month, month, day of week)
+	// in the local time zone, or one of @yearly, @monthly, @weekly, @daily and @hourly
+	Cron     string
+	Duration time.Duration
+	Level    string
+}
+
+// levelScheduler applies Config.LevelSchedule. The level set before a window is restored when it ends
+type levelScheduler struct {
+	windows []scheduledLevel
+	level   zap.AtomicLevel
+	now     func() time.Time
+
+	active int // the index of the active window, -1 if there's none
+	saved  zapcore.Level
+}
+
+type scheduledLevel struct {
+	cron     *cronSchedule
+	duration time.Duration
+	level    zapcore.Level
+}
+
+func newLevelScheduler(windows []LevelWindow, level zap.AtomicLevel) (*levelScheduler, error) {
+	s := &levelScheduler{level: level, now: time.Now, active: -1}
+	for _, w := range windows {
+		cron, err := parseCron(w.Cron)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level window %q", w.Cron)
+		}
+		if w.Duration <= 0 {
+			return nil, errors.Errorf("level window %q must have positive Duration", w.Cron)
+		}
+		lvl, err := parseLevel(w.Level)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level window %q", w.Cron)
+		}
+		s.windows = append(s.windows, scheduledLevel{cron: cron, duration: w.Duration, level: lvl})
+	}
+	return s, nil
+}
+
+// start applies the schedule and keeps checking it until the returned stop function is called
+func (s *levelScheduler) start() (stop func() error) {
+	s.apply()
+	return runEvery(levelScheduleInterval, s.apply)
+}
+
+func (s *levelScheduler) apply() {
+	now := s.now()
+	active := -1
+	for i, w := range s.windows {
+		if w.activeAt(now) {
+			active = i
+			break
+		}
+	}
+	if active == s.active {
+		return
+	}
+
+	if s.active < 0 {
+		s.saved = s.level.Level()
+	}
+	if active >= 0 {
+		s.level.SetLevel(s.windows[active].level)
+	} else {
+		s.level.SetLevel(s.saved)
+	}
+	s.active = active
+}
+
+// activeAt reports whether a window started at a minute matching the schedule covers the time
+func (w scheduledLevel) activeAt(t time.Time) bool {
+	for start := t.Truncate(time.Minute); start.Add(w.duration).After(t); start = start.Add(-time.Minute) {
+		if w.cron.matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// cronSchedule is a parsed cron expression with a bit set per field
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday are set for fields starting with "*". If both day fields are restricted,
+	// times matching either of them match, as in cron
+	anyDay, anyWeekday bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+var (
+	cronMonths   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+func parseCron(expr string) (*cronSchedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	s := &cronSchedule{anyDay: strings.HasPrefix(fields[2], "*"), anyWeekday: strings.HasPrefix(fields[4], "*")}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, err
+	}
+	if s.weekdays, err = parseCronField(fields[4], 0, 7, cronWeekdays); err != nil {
+		return nil, err
+	}
+	// Sunday is both 0 and 7
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses a comma-separated list of "*", values and ranges with optional steps, e.g. "1-5,*/15".
+// Names are values starting with the first value of the range, e.g. month names
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid cron step %q", part)
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowPart, min, max, names); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseCronValue(highPart, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = max
+			}
+			if low > high {
+				return 0, errors.Errorf("invalid cron range %q", rangePart)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(value, name) {
+			return min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < min || v > max {
+		return 0, errors.Errorf("invalid cron value %q, must be in %d-%d", value, min, max)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 || s.hours&(1<<uint(t.Hour())) == 0 || s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseCron(t *testing.T) {
+	for expr, times := range map[string]map[string]bool{
+		"0 2 * * SAT": {
+			"2024-06-01 02:00": true, // Saturday
+			"2024-06-01 02:01": false,
+			"2024-06-02 02:00": false,
+		},
+		"*/15 9-17 * * 1-5": {
+			"2024-06-03 09:45": true,
+			"2024-06-03 18:00": false,
+			"2024-06-03 10:10": false,
+		},
+		"30 0 1,15 * 7": {
+			"2024-06-15 00:30": true, // day of month
+			"2024-06-02 00:30": true, // Sunday
+			"2024-06-03 00:30": false,
+		},
+		"@monthly": {"2024-07-01 00:00": true, "2024-07-02 00:00": false},
+	} {
+		cron, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", expr, err)
+		}
+		for value, want := range times {
+			tm, _ := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+			if cron.matches(tm) != want {
+				t.Errorf("want %q matching %s %v", expr, value, want)
+			}
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * FOO *", "5-1 * * * *", "*/0 * * * *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("want an error for %q", expr)
+		}
+	}
+}
+
+func TestLevelSchedule(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	s, err := newLevelScheduler([]LevelWindow{{Cron: "0 2 * * *", Duration: time.Hour, Level: "debug"}}, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		at   string
+		want zapcore.Level
+	}{
+		{"2024-06-01 01:59", zapcore.InfoLevel},
+		{"2024-06-01 02:00", zapcore.DebugLevel},
+		{"2024-06-01 02:59", zapcore.DebugLevel},
+		{"2024-06-01 03:00", zapcore.InfoLevel},
+	} {
+		now, _ := time.ParseInLocation("2006-01-02 15:04", step.at, time.Local)
+		s.now = func() time.Time { return now }
+		s.apply()
+		if level.Level() != step.want {
+			t.Errorf("want %s at %s, got %s", step.want, step.at, level.Level())
+		}
+	}
+
+	if _, err := New(Config{DisableStdOut: true, LevelSchedule: []LevelWindow{{Cron: "@daily", Level: "debug"}}}); err == nil {
+		t.Error("want an error for a window without duration")
+	}
+}
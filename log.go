@@ -1,20 +1,62 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // zap doesn't have predefined const to do nothing on fatal. So, define it ourself
 const doNothingOnFatal zapcore.CheckWriteAction = 100
 
-// Logger is a wrapper for *zap.SugaredLogger compatible with logrus.FieldLogger
+// defaultStartupMessage is the message of Logger.LogStartup entries if Config.StartupMessage isn't set
+const defaultStartupMessage = "started"
+
+// Level aliases, so zapcore doesn't have to be imported to use levels
+const (
+	DebugLevel = zapcore.DebugLevel
+	InfoLevel  = zapcore.InfoLevel
+	WarnLevel  = zapcore.WarnLevel
+	ErrorLevel = zapcore.ErrorLevel
+	PanicLevel = zapcore.PanicLevel
+	FatalLevel = zapcore.FatalLevel
+)
+
+// Logger is a wrapper for *zap.SugaredLogger compatible with logrus.FieldLogger.
+// A logger and the loggers derived from it are safe for concurrent use, including level changes and reloads.
+// A nil *Logger is a noop one, like a missing optional dependency
 type Logger struct {
-	zap   *zap.SugaredLogger
-	level zap.AtomicLevel
+	// root is the zap logger the fields are applied to. It's done lazily on the first use,
+	// so building a chain of loggers with fields doesn't re-encode the fields at every step
+	root    *zap.Logger
+	fields  *fieldList
+	applied *appliedLogger
+	// name is the full logger name and described are all its fields, including the applied ones, see Registry
+	name      string
+	described *fieldList
+
+	level     zap.AtomicLevel
+	overrides *atomic.Value // *levelOverrides shared with levelCore, nil if there is no levelCore
+	out       *outputs
+	// wrapperSkip is the caller skip added to account for the Logger methods
+	wrapperSkip int
+	// templates adds the templates and args of formatted messages as fields, see Config.PreserveTemplates
+	templates bool
+	// access configures access entries, see WithAccessLog. Nil logs them like other entries
+	access *accessLog
+	// spanCtx is the context added by Ctx for the SpanRecorder
+	spanCtx context.Context
+	// startupMessage is the message of LogStartup entries, see Config.StartupMessage
+	startupMessage string
 }
 
 type Config struct {
@@ -22,56 +64,467 @@ type Config struct {
 	DisableStdOut bool
 	// DisableColor disables colored output
 	DisableColor bool
-	// Files is a list of file paths to write logging output to
+	// ColorMessages colors the message text of console and pretty encodings by level:
+	// debug messages are dimmed, warnings are yellow and errors are red
+	ColorMessages bool
+	// Level is the initial global level, "debug" by default. It can be changed later with Logger.SetLevel
+	Level string
+	// Encoding is the format of entries written to stdout, Files, Outputs and Sinks without their own encoding. Defaults to EncodingConsole
+	Encoding Encoding
+	// HumanizeDurations rounds durations to 3 significant digits (e.g. "1.23s", "35.1ms") in console encodings
+	HumanizeDurations bool
+	// TimeLayout is the time layout of console and pretty encodings. Defaults to "2006-01-02 15:04:05".
+	// The formatted time is reused within a second unless the layout has sub-second precision
+	TimeLayout string
+	// Files is a list of file paths to write logging output to.
+	// Besides plain paths, any URL supported by zap.Open is accepted (e.g. "stderr"),
+	// as well as "fd://<n>" for a file descriptor inherited from a supervisor (systemd, runit).
+	// Plain paths can contain time layouts in braces if PathTemplates is set.
+	//
+	// Deprecated: use Sinks, which can be targeted by name and configured individually
 	Files []string
+	// Sinks are named outputs. Entries are written to all of them unless targeted with Logger.To,
+	// bound to a logger name with LoggerSinks, or the sink is Exclusive
+	Sinks map[string]SinkConfig
+	// LoggerSinks binds logger names (see Logger.Named) to sink or group names they write to by default,
+	// e.g. {"http.access": {"access"}}. Child loggers inherit the binding of the closest parent name
+	LoggerSinks map[string][]string
+	// Rotation rotates plain file paths from Files on wall-clock boundaries.
+	// Files can be also rotated manually with Logger.Rotate
+	Rotation RotationPeriod
+	// FileLock locks plain files with flock for every write, so records of several processes
+	// sharing a file (e.g. prefork workers) never interleave.
+	// It's supported on Unix only, New fails with ErrFileLockUnsupported elsewhere.
+	// Without it records are still appended with a single write call
+	FileLock bool
+	// MaxRecordSize truncates records written to plain files to the size in bytes,
+	// keeping appends of several processes sharing a file atomic. Zero disables truncation
+	MaxRecordSize int
+	// FileMode is the permissions of plain files, e.g. 0600. It's also applied to existing files.
+	// Defaults to 0666 before umask for new files
+	FileMode os.FileMode
+	// DirMode is the permissions of missing parent directories of plain files, which are created if it's set, e.g. 0750
+	DirMode os.FileMode
+	// FileOwner is the "user[:group]" owner (names or IDs) set for plain files when running as root
+	FileOwner string
+	// PathTemplates makes plain file paths with time layouts in braces, e.g. "/var/log/app/{2006/01/02}/app.log",
+	// templates: a new file is started, creating the directories, once the path changes (hourly or daily, see Rotation).
+	// Without it braces are literal characters of the path
+	PathTemplates bool
+	// ExpandPathTokens replaces "%H" with the hostname and "%P" with the process ID in plain file paths,
+	// e.g. "/var/log/app-%H-%P.log", so several instances sharing a directory don't write to the same file.
+	// "%%" is a literal "%"
+	ExpandPathTokens bool
+	// FileIndex maintains a "<path>.idx" sidecar index of plain files with offsets, per level counts
+	// and checksums of the records written every minute, see ReadFileIndex and VerifyFileIndex.
+	// It isn't supported for files shared by several processes
+	FileIndex bool
+	// FileSync fsyncs plain files, so acknowledged records survive power failures (e.g. audit logs):
+	// after every entry, with concurrent entries sharing a single fsync, or every FileSyncInterval.
+	// Entries are acknowledged once the logging call returns, so FileSyncEveryEntry isn't effective with Async.
+	// The directories of created and rotated files are fsynced too. Periodic fsync errors are reported to stderr
+	FileSync FileSyncMode
+	// FileSyncInterval is the fsync interval of FileSyncPeriodic. Defaults to 1s
+	FileSyncInterval time.Duration
+	// ConcurrentOutputs writes to every output from its own goroutine through a per-output queue,
+	// so a slow output (e.g. a network one) doesn't delay the others and the caller.
+	// Write errors are reported by the next Sync call. With Async.NonBlocking full queues drop entries instead of waiting
+	ConcurrentOutputs bool
+	// OutputQueueSize is the per-output queue size for ConcurrentOutputs. Defaults to 1024
+	OutputQueueSize int
+	// Async writes entries to the outputs and Cores from a background goroutine,
+	// optionally dropping entries instead of blocking when the queue is full
+	Async AsyncConfig
+	// DeadLetterFile stores entries that failed to be written to all the outputs as JSON lines
+	// with the "dead_letter_reason" field. They can be logged again with Logger.RedriveDeadLetters.
+	// It can't be combined with ConcurrentOutputs, whose write errors are known only after the logging call returns
+	DeadLetterFile string
+	// Sampling throttles repetitive entries, optionally by a field value. It's disabled by default
+	Sampling SamplingConfig
+	// ErrorSummary periodically logs how many times every error was logged during the interval,
+	// optionally suppressing repeated errors. It's disabled by default
+	ErrorSummary ErrorSummaryConfig
+	// Alerts are rules writing an alert entry at DPanic level and calling a notification hook
+	// if too many matching entries are logged within a window, see AlertRule
+	Alerts []AlertRule
+	// ErrorFingerprint adds a "<key>.fingerprint" field (e.g. "error.fingerprint") to every error field:
+	// a hash of the error type chain and the error message with numbers masked,
+	// so dashboards can group identical failures with variable data in messages
+	ErrorFingerprint bool
+	// Offload replaces field values over a size threshold with references to payloads written to a directory
+	// or an object store. It's disabled by default
+	Offload OffloadConfig
+	// SinkGroups are named groups of outputs and sinks that entries can be targeted to with Logger.To,
+	// e.g. {"audit": {Outputs: []string{"/var/log/audit.log"}, Exclusive: true}}
+	SinkGroups map[string]SinkGroup
+	// Outputs is a list of additional outputs, e.g. network ones wrapped with NewCircuitBreaker or NewSpool.
+	// Outputs implementing io.Closer are closed by Logger.Shutdown
+	Outputs []zapcore.WriteSyncer
+	// Cores is a list of additional cores, e.g. NewMQTTCore. They are subject to the logger level.
+	// Cores implementing io.Closer are closed by Logger.Shutdown
+	Cores []zapcore.Core
+	// Heartbeat configures periodic heartbeat entries, so alerting can tell
+	// a silent service from a broken log pipeline. Heartbeats stop on Logger.Shutdown
+	Heartbeat HeartbeatConfig
+	// PackageLevels overrides the level for entries logged from packages with the given import path prefixes,
+	// e.g. {"github.com/org/app/db": "debug"}. The longest matching prefix wins.
+	// It lets tuning subsystems that don't use their own loggers
+	PackageLevels map[string]string
+	// LevelFile is a file with a level name (e.g. a mounted ConfigMap) to watch.
+	// The level is applied on start and whenever the file content changes
+	LevelFile string
+	// LevelFileInterval is the LevelFile polling interval. Defaults to 5s
+	LevelFileInterval time.Duration
+	// LevelSource provides the global level and levels by logger name, e.g. from a remote config service.
+	// It's polled every LevelSourceInterval (30s by default). The current levels are kept on fetch errors
+	LevelSource         LevelSource
+	LevelSourceInterval time.Duration
+	// LevelSchedule sets the global level during time windows, e.g. debug during a maintenance window.
+	// The first active window wins, the level set before is restored when windows end
+	LevelSchedule []LevelWindow
+	// Observe records all the written entries in memory, so tests can assert on logs
+	// of the fully-configured logger. See Logger.ObservedLogs
+	Observe bool
+	// Keys normalizes keys of all the fields, e.g. converting them to snake_case
+	Keys KeyPolicy
+	// SpanRecorder records entries logged with a context (see Logger.Ctx) as events of the active span,
+	// e.g. an OpenTelemetry adapter. It's disabled by default
+	SpanRecorder SpanRecorder
+	// Sanitize escapes newlines, carriage returns and other control characters (e.g. ANSI escape sequences)
+	// in messages, logger names and field values (including errors and objects), so user input can't forge log lines
+	// or mess up terminals (CWE-117). Strings escaped by the encoders (JSON strings and field values of the console
+	// and pretty encodings) are escaped only for the characters the encoders keep, so their backslashes aren't doubled
+	Sanitize bool
+	// AnonymizeIPs truncates or hashes IP addresses in field values, e.g. to keep access logs GDPR-compliant
+	AnonymizeIPs IPAnonymization
+	// Schema validates fields of entries against a JSON schema in development and tests
+	Schema SchemaConfig
+	// PreserveTemplates adds the "msg_template" and "msg_args" fields to entries logged with Infof-style methods,
+	// so log backends can group entries by template. It's intended for EncodingJSON
+	PreserveTemplates bool
+	// Enrichers add fields to entries at write time, e.g. UserAgentEnricher.
+	// Enrichers implementing io.Closer are closed by Logger.Shutdown
+	Enrichers []Enricher
+	// ZapOptions are applied to the underlying zap logger after the default ones,
+	// e.g. zap.WithClock, zap.WrapCore, zap.Fields or zap.Hooks
+	ZapOptions []zap.Option
+	// LogStartup makes New log an entry describing the effective configuration (outputs, level, format),
+	// helping to diagnose misrouted logs
+	LogStartup bool
+	// StartupMessage is the message of Logger.LogStartup entries. Defaults to "started"
+	StartupMessage string
+}
+
+func (cfg Config) fileOptions() (fileOptions, error) {
+	opts := fileOptions{
+		rotation:      cfg.Rotation,
+		lock:          cfg.FileLock,
+		maxRecordSize: cfg.MaxRecordSize,
+		mode:          cfg.FileMode,
+		dirMode:       cfg.DirMode,
+		templates:     cfg.PathTemplates,
+		expandTokens:  cfg.ExpandPathTokens,
+		index:         cfg.FileIndex,
+		sync:          cfg.FileSync,
+		syncInterval:  cfg.FileSyncInterval,
+		uid:           -1,
+		gid:           -1,
+	}
+	if cfg.FileOwner != "" {
+		var err error
+		if opts.uid, opts.gid, err = lookupOwner(cfg.FileOwner); err != nil {
+			return fileOptions{}, err
+		}
+	}
+	return opts, nil
+}
+
+// summary describes the effective configuration for LogStartup
+func (cfg Config) summary(level zapcore.Level) map[string]interface{} {
+	outputs := make([]string, 0, len(cfg.Files)+1)
+	if !cfg.DisableStdOut {
+		outputs = append(outputs, "stdout")
+	}
+	outputs = append(outputs, cfg.Files...)
+	outputs = append(outputs, sortedKeys(cfg.Sinks)...)
+
+	summary := map[string]interface{}{
+		"level":    level.String(),
+		"encoding": string(cfg.Encoding.orDefault()),
+		"color":    !cfg.DisableColor,
+		"outputs":  outputs,
+	}
+	if len(cfg.Outputs) != 0 {
+		summary["custom_outputs"] = len(cfg.Outputs)
+	}
+	if len(cfg.Cores) != 0 {
+		summary["custom_cores"] = len(cfg.Cores)
+	}
+	if cfg.Keys.Case != KeyCaseAsIs {
+		summary["key_case"] = string(cfg.Keys.Case)
+	}
+	if len(cfg.Schema.Schema) != 0 {
+		summary["schema"] = true
+	}
+	if cfg.Offload.Threshold > 0 {
+		summary["offload_threshold"] = cfg.Offload.Threshold
+	}
+	if cfg.Sanitize {
+		summary["sanitize"] = true
+	}
+	if cfg.AnonymizeIPs.Enabled {
+		summary["anonymize_ips"] = true
+	}
+	if cfg.SpanRecorder != nil {
+		summary["span_events"] = true
+	}
+	if cfg.PreserveTemplates {
+		summary["preserve_templates"] = true
+	}
+	if len(cfg.ZapOptions) != 0 {
+		summary["zap_options"] = len(cfg.ZapOptions)
+	}
+	if cfg.Async.Enabled {
+		summary["async"] = true
+		summary["async_non_blocking"] = cfg.Async.NonBlocking
+		summary["async_priority_lanes"] = cfg.Async.PriorityLanes
+		summary["async_capture_fields"] = cfg.Async.CaptureFields
+	}
+	if cfg.DeadLetterFile != "" {
+		summary["dead_letter_file"] = cfg.DeadLetterFile
+	}
+	if cfg.Sampling.First > 0 {
+		tick := cfg.Sampling.Tick
+		if tick <= 0 {
+			tick = defaultSamplingTick
+		}
+		summary["sampling"] = fmt.Sprintf("first %d, thereafter %d per %s by %q",
+			cfg.Sampling.First, cfg.Sampling.Thereafter, tick, cfg.Sampling.Key)
+	}
+	if cfg.ErrorSummary.Interval > 0 {
+		summary["error_summary"] = cfg.ErrorSummary.Interval.String()
+	}
+	if len(cfg.Alerts) != 0 {
+		summary["alerts"] = len(cfg.Alerts)
+	}
+	if cfg.ErrorFingerprint {
+		summary["error_fingerprint"] = true
+	}
+	if len(cfg.SinkGroups) != 0 {
+		summary["sink_groups"] = sortedKeys(cfg.SinkGroups)
+	}
+	if cfg.Rotation != RotateNever {
+		summary["rotation"] = string(cfg.Rotation)
+	}
+	if cfg.FileLock {
+		summary["file_lock"] = true
+	}
+	if cfg.MaxRecordSize > 0 {
+		summary["max_record_size"] = cfg.MaxRecordSize
+	}
+	if cfg.FileMode != 0 {
+		summary["file_mode"] = cfg.FileMode.String()
+	}
+	if cfg.FileOwner != "" {
+		summary["file_owner"] = cfg.FileOwner
+	}
+	if cfg.PathTemplates {
+		summary["path_templates"] = true
+	}
+	if cfg.ExpandPathTokens {
+		summary["expand_path_tokens"] = true
+	}
+	if cfg.FileIndex {
+		summary["file_index"] = true
+	}
+	if cfg.FileSync != FileSyncNever {
+		summary["file_sync"] = string(cfg.FileSync)
+	}
+	if cfg.ConcurrentOutputs {
+		summary["concurrent_outputs"] = true
+	}
+	if cfg.Heartbeat.Interval > 0 {
+		summary["heartbeat"] = cfg.Heartbeat.Interval.String()
+	}
+	if cfg.LevelFile != "" {
+		summary["level_file"] = cfg.LevelFile
+	}
+	if len(cfg.LevelSchedule) != 0 {
+		summary["level_schedule"] = len(cfg.LevelSchedule)
+	}
+	if len(cfg.Enrichers) != 0 {
+		summary["enrichers"] = len(cfg.Enrichers)
+	}
+	if cfg.Observe {
+		summary["observe"] = true
+	}
+	return summary
 }
 
 // New creates a new logger
 func New(cfg Config) (logger *Logger, err error) {
 	level := zap.NewAtomicLevelAt(zap.DebugLevel)
+	if cfg.Level != "" {
+		initial, err := parseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		level.SetLevel(initial)
+	}
 
-	var outputPaths []string
-	if !cfg.DisableStdOut {
-		outputPaths = append(outputPaths, "stdout")
+	if !cfg.Rotation.valid() {
+		return nil, errors.Errorf("unknown rotation period %q", cfg.Rotation)
+	}
+	if !cfg.FileSync.valid() {
+		return nil, errors.Errorf("unknown file sync mode %q", cfg.FileSync)
+	}
+	if !cfg.Encoding.valid() {
+		return nil, errors.Errorf("unknown encoding %q", cfg.Encoding)
+	}
+	if cfg.DeadLetterFile != "" && cfg.ConcurrentOutputs {
+		return nil, errors.New("DeadLetterFile can't be used with ConcurrentOutputs")
+	}
+	if cfg.FileLock && !fileLockSupported {
+		return nil, ErrFileLockUnsupported
 	}
-	if cfg.Files != nil {
-		outputPaths = append(outputPaths, cfg.Files...)
+	if !cfg.Keys.Case.valid() {
+		return nil, errors.Errorf("unknown key case %q", cfg.Keys.Case)
+	}
+	initialOverrides, err := newLevelOverrides(cfg.PackageLevels, nil)
+	if err != nil {
+		return nil, err
+	}
+	overrides := &atomic.Value{}
+	overrides.Store(initialOverrides)
+	schedule, err := newLevelScheduler(cfg.LevelSchedule, level)
+	if err != nil {
+		return nil, err
 	}
 
-	levelEncoder := zapcore.CapitalColorLevelEncoder
-	if cfg.DisableColor {
-		levelEncoder = zapcore.CapitalLevelEncoder
+	out := &outputs{loggerSinks: cfg.LoggerSinks}
+	defer func() {
+		if err != nil {
+			_ = out.close()
+		}
+	}()
+
+	opened, err := out.open(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	zapCfg := zap.Config{
-		Level:             level,
-		Development:       true,
-		DisableStacktrace: true,
-		Sampling:          nil,
-		Encoding:          "console",
-		OutputPaths:       outputPaths,
-		ErrorOutputPaths:  []string{"stderr"},
-		EncoderConfig: zapcore.EncoderConfig{
-			TimeKey:        "T",
-			LevelKey:       "L",
-			NameKey:        "N",
-			CallerKey:      "C",
-			FunctionKey:    zapcore.OmitKey,
-			MessageKey:     "M",
-			StacktraceKey:  "S",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    levelEncoder,
-			EncodeTime:     zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05"),
-			EncodeDuration: zapcore.StringDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		},
-	}
-
-	z, err := zapCfg.Build()
+	errSink, _, err := zap.Open("stderr")
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to zapCfg.Build")
+		return nil, errors.Wrap(err, "failed to zap.Open stderr")
 	}
 
+	for _, c := range cfg.Cores {
+		if closer, ok := c.(io.Closer); ok {
+			out.closers = append(out.closers, closer.Close)
+		}
+	}
+	for _, enricher := range cfg.Enrichers {
+		if closer, ok := enricher.(io.Closer); ok {
+			out.closers = append(out.closers, closer.Close)
+		}
+	}
+	enc := newEncoder(cfg)
+	cores, err := newRouteCores(opened, cfg.Cores, enc, cfg.SinkGroups, cfg.LoggerSinks)
+	if err != nil {
+		return nil, err
+	}
+	out.dynamic = newDynamicSinks(cfg, enc, opened)
+	out.closers = append(out.closers, out.dynamic.close)
+	if cfg.DeadLetterFile != "" {
+		if out.deadLetter, err = openDeadLetterFile(cfg.DeadLetterFile); err != nil {
+			return nil, err
+		}
+		out.closers = append(out.closers, out.deadLetter.Close)
+		// Custom cores aren't outputs, so their failures don't make an entry undeliverable
+		outputCores := &deadLetterCore{cores: cores[:len(opened)], deadLetter: out.deadLetter}
+		cores = append([]zapcore.Core{outputCores}, cores[len(opened):]...)
+	}
+	cores = append(cores, newDynamicCore(out.dynamic))
+	if cfg.Async.Enabled {
+		tee := zapcore.NewTee(cores...)
+		writer := newAsyncWriter(tee, cfg.Async, out.drops)
+		out.closers = append(out.closers, writer.Close)
+		cores = []zapcore.Core{&asyncCore{core: tee, writer: writer}}
+	} else if out.drops != nil {
+		out.closers = append(out.closers, startDropNotices(zapcore.NewTee(cores...), out.drops, cfg.Async.DropNoticeInterval))
+	}
+	if cfg.Observe {
+		var observerCore zapcore.Core
+		observerCore, out.observed = observer.New(zapcore.DebugLevel)
+		cores = append(cores, observerCore)
+	}
+	var core zapcore.Core = zapcore.NewTee(cores...)
+	if cfg.SpanRecorder != nil {
+		core = &spanCore{core: core, recorder: cfg.SpanRecorder}
+		out.spans = cfg.SpanRecorder
+	}
+	if cfg.Sanitize {
+		core = newSanitizeCore(core, cfg)
+	}
+	if cfg.AnonymizeIPs.Enabled {
+		anonymizer, err := newIPAnonymizer(cfg.AnonymizeIPs)
+		if err != nil {
+			return nil, err
+		}
+		core = &anonymizeCore{core: core, anonymizer: anonymizer}
+	}
+	if cfg.ErrorFingerprint {
+		core = &fingerprintCore{core: core}
+	}
+	if cfg.Offload.Threshold > 0 {
+		store, err := cfg.Offload.store()
+		if err != nil {
+			return nil, err
+		}
+		core = &offloadCore{core: core, threshold: cfg.Offload.Threshold, store: store}
+	}
+	if cfg.Sampling.First > 0 {
+		core = newSamplingCore(core, cfg.Sampling)
+	}
+	if cfg.ErrorSummary.Interval > 0 {
+		summary := newErrorSummary(core, cfg.ErrorSummary)
+		out.closers = append(out.closers, runEvery(cfg.ErrorSummary.Interval, summary.emit))
+		core = &errorSummaryCore{Core: core, summary: summary}
+	}
+	if len(cfg.Alerts) != 0 {
+		engine, err := newAlertEngine(core, cfg.Alerts)
+		if err != nil {
+			return nil, err
+		}
+		core = &alertCore{core: core, engine: engine}
+	}
+	if len(cfg.Schema.Schema) != 0 {
+		schema, err := parseSchema(cfg.Schema.Schema)
+		if err != nil {
+			return nil, err
+		}
+		core = &schemaCore{core: core, schema: schema, fail: cfg.Schema.Fail}
+	}
+	if cfg.Keys.enabled() {
+		core = &keyCore{core: core, normalizer: newKeyNormalizer(cfg.Keys)}
+	}
+	if len(cfg.Enrichers) != 0 {
+		core = newEnrichCore(core, cfg.Enrichers)
+	}
+	core = newTransformCore(core)
+	// Below the level core, so the causes are written whatever the level is
+	core = &fatalCauseCore{core: core}
+	core = newLevelCore(core, level, overrides)
+	core = &errorLevelCore{core: core}
+	core = &gateCore{Core: core, out: out}
+
+	opts := append([]zap.Option{
+		zap.Development(),
+		zap.AddCaller(),
+		zap.ErrorOutput(errSink),
+	}, cfg.ZapOptions...)
+	z := zap.New(core, opts...)
+
 	// // Send SIGINT on fatal calls
 	// z = z.WithOptions(
 	// 	zap.OnFatal(doNothingOnFatal),
@@ -88,84 +541,252 @@ func New(cfg Config) (logger *Logger, err error) {
 	// 	}),
 	// )
 
+	if cfg.LevelFile != "" {
+		out.closers = append(out.closers, watchLevelFile(cfg.LevelFile, cfg.LevelFileInterval, level))
+	}
+	if len(cfg.LevelSchedule) != 0 {
+		out.closers = append(out.closers, schedule.start())
+	}
+	if cfg.Heartbeat.Interval > 0 {
+		out.closers = append(out.closers, startHeartbeat(z, cfg.Heartbeat))
+	}
+
 	z = z.WithOptions(zap.AddCallerSkip(1))
 
-	return &Logger{
-		zap:   z.Sugar(),
-		level: level,
-	}, nil
+	logger = &Logger{
+		root:        z,
+		applied:     &appliedLogger{},
+		level:       level,
+		overrides:   overrides,
+		out:         out,
+		wrapperSkip: 1,
+		templates:   cfg.PreserveTemplates,
+
+		startupMessage: cfg.StartupMessage,
+	}
+	if cfg.LevelSource != nil {
+		out.closers = append(out.closers, pollLevelSource(logger, cfg.LevelSource, cfg.LevelSourceInterval))
+	}
+	if cfg.LogStartup {
+		z.WithOptions(zap.WithCaller(false)).Info("logger configured", mapToFields(cfg.summary(level.Level()))...)
+	}
+	return logger, nil
+}
+
+// Encoding is a format of entries written to outputs
+type Encoding string
+
+const (
+	// EncodingConsole writes tab-separated entries with fields as a JSON object
+	EncodingConsole Encoding = "console"
+	// EncodingJSON writes an entry per line as a JSON object
+	EncodingJSON Encoding = "json"
+	// EncodingPretty is a dev-mode console encoding: fields are written inline after the message
+	// as colored key=value pairs and columns are aligned across lines
+	EncodingPretty Encoding = "pretty"
+	// EncodingCanonical writes JSON entries with sorted keys, CanonicalTimestamp instead of the time,
+	// the caller file without line and no stacktraces. It's intended for golden files in tests, see the loggertest package
+	EncodingCanonical Encoding = "canonical"
+)
+
+func (e Encoding) orDefault() Encoding {
+	if e == "" {
+		return EncodingConsole
+	}
+	return e
+}
+
+func (e Encoding) valid() bool {
+	switch e.orDefault() {
+	case EncodingConsole, EncodingJSON, EncodingPretty, EncodingCanonical:
+		return true
+	default:
+		return false
+	}
+}
+
+func newEncoder(cfg Config) zapcore.Encoder {
+	switch cfg.Encoding.orDefault() {
+	case EncodingJSON:
+		return zapcore.NewJSONEncoder(jsonEncoderConfig())
+	case EncodingPretty:
+		enc := newPrettyEncoder(!cfg.DisableColor, cfg.HumanizeDurations, newTimeCache(cfg.TimeLayout))
+		enc.colorMessages = cfg.ColorMessages
+		return enc
+	case EncodingCanonical:
+		return newCanonicalEncoder()
+	default:
+		var enc zapcore.Encoder = humanizingEncoder{Encoder: zapcore.NewConsoleEncoder(encoderConfig(cfg))}
+		if cfg.ColorMessages && !cfg.DisableColor {
+			enc = messageColorEncoder{Encoder: enc}
+		}
+		return enc
+	}
+}
+
+func encoderConfig(cfg Config) zapcore.EncoderConfig {
+	levelEncoder := zapcore.CapitalColorLevelEncoder
+	if cfg.DisableColor {
+		levelEncoder = zapcore.CapitalLevelEncoder
+	}
+	durationEncoder := zapcore.StringDurationEncoder
+	if cfg.HumanizeDurations {
+		durationEncoder = humanDurationEncoder
+	}
+
+	return zapcore.EncoderConfig{
+		TimeKey:        "T",
+		LevelKey:       "L",
+		NameKey:        "N",
+		CallerKey:      "C",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "M",
+		StacktraceKey:  "S",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    levelEncoder,
+		EncodeTime:     newTimeCache(cfg.TimeLayout).encode,
+		EncodeDuration: durationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
 }
 
 // NewNoop returns a noop logger
 func NewNoop() *Logger {
+	z := zap.NewNop()
 	return &Logger{
-		zap:   zap.NewNop().Sugar(),
-		level: zap.NewAtomicLevel(),
+		root:    z,
+		applied: &appliedLogger{},
+		level:   zap.NewAtomicLevel(),
+		out:     &outputs{},
 	}
 }
 
 // NewWith returns a logger based on the passed zap logger
 func NewWith(log *zap.Logger, currentLvl zapcore.Level) *Logger {
 	return &Logger{
-		zap:   log.Sugar(),
-		level: zap.NewAtomicLevelAt(currentLvl),
+		root:    log,
+		applied: &appliedLogger{},
+		level:   zap.NewAtomicLevelAt(currentLvl),
+		out:     &outputs{},
 	}
 }
 
 // Zap returns the underlying *zap.SugaredLogger
 func (l *Logger) Zap() *zap.SugaredLogger {
-	return l.zap
+	return l.sugar()
 }
 
-func (l *Logger) SetLevel(lvl string) {
-	if lvl == "trace" || lvl == "TRACE" {
-		// zap doesn't have a trace level. See TODO for more info
-		lvl = "debug"
+// ZapDesugared returns the underlying *zap.Logger for libraries requiring it (e.g. zapgrpc).
+// Unlike the logger used by Logger methods, it reports the caller of its own methods
+func (l *Logger) ZapDesugared() *zap.Logger {
+	if l == nil {
+		return zap.NewNop()
 	}
+	return l.base().WithOptions(zap.AddCallerSkip(-l.wrapperSkip))
+}
 
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(lvl)); err == nil {
+func (l *Logger) SetLevel(lvl string) {
+	if l == nil {
+		return
+	}
+	if zapLevel, err := parseLevel(lvl); err == nil {
 		l.level.SetLevel(zapLevel)
 	}
 }
 
+// ObservedLogs returns the entries recorded with Config.Observe, nil if it isn't enabled
+func (l *Logger) ObservedLogs() *observer.ObservedLogs {
+	if l == nil {
+		return nil
+	}
+	return l.out.observed
+}
+
+// Named returns a cloned logger with the name segment added. Segments are joined with "."
+func (l *Logger) Named(name string) *Logger {
+	clone := l.clone()
+	clone.root = clone.root.Named(name)
+	if clone.name == "" {
+		clone.name = name
+	} else if name != "" {
+		clone.name += "." + name
+	}
+	registry.add(clone)
+	return clone
+}
+
 // WithCallerSkip returns a cloned logger with increased number of skipped callers.
 // Skip can be negative
 func (l *Logger) WithCallerSkip(skip int) *Logger {
-	clone := l.clone()
-	clone.zap = clone.zap.Desugar().WithOptions(zap.AddCallerSkip(skip)).Sugar()
-	return clone
+	return l.withOptions(zap.AddCallerSkip(skip))
+}
+
+// WithTime returns a cloned logger writing entries with the given timestamp instead of the current time.
+// Useful for replaying or importing historical events
+func (l *Logger) WithTime(t time.Time) *Logger {
+	return l.withOptions(zap.WithClock(fixedClock(t)))
 }
 
-// WithField returns a cloned logger with a new field
+// WithField returns a cloned logger with a new field. Values implementing zapcore.ObjectMarshaler or
+// zapcore.ArrayMarshaler are encoded with their methods instead of reflection, see MarshalStruct
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return l.withFields(key, value)
+	return l.withFields(zap.Any(key, value))
 }
 
-// WithError is a shorthand for Logger.WithField("error", err)
+// WithError is a shorthand for Logger.WithField("error", err). Classified errors (see RegisterErrorClassifier)
+// also get the error.code and error.category fields
 func (l *Logger) WithError(err error) *Logger {
-	return l.WithField("error", err)
+	return l.withFields(errorFields(err)...)
 }
 
 // WithField returns a cloned logger with new fields
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	zapFields := make([]interface{}, 0, len(fields))
-	for k, v := range fields {
-		zapFields = append(zapFields, k, v)
+	return l.withFields(mapToFields(fields)...)
+}
+
+// withFields returns a clone sharing the fields of the logger with the new fields added.
+// If the logger fields are already applied, the clone is based on the result
+func (l *Logger) withFields(fields ...zap.Field) *Logger {
+	if l == nil {
+		return nopLogger.withFields(fields...)
 	}
-	return l.withFields(zapFields...)
+	clone := l.clone()
+	if l.applied.ready.Load() {
+		clone.root, clone.fields = l.applied.base, nil
+	}
+	clone.fields = clone.fields.add(fields)
+	clone.described = clone.described.add(fields)
+	return clone
 }
 
-func (l *Logger) withFields(keyValArgs ...interface{}) *Logger {
+// withOptions returns a clone with the options applied to the root logger.
+// The options are expected to be independent of the fields
+func (l *Logger) withOptions(opts ...zap.Option) *Logger {
 	clone := l.clone()
-	clone.zap = clone.zap.With(keyValArgs...)
+	clone.root = clone.root.WithOptions(opts...)
 	return clone
 }
 
+// clone returns a copy of the logger, a noop one for a nil logger
 func (l *Logger) clone() *Logger {
+	if l == nil {
+		return nopLogger.clone()
+	}
 	return &Logger{
-		zap:   l.zap,
-		level: l.level,
+		root:        l.root,
+		fields:      l.fields,
+		applied:     &appliedLogger{},
+		name:        l.name,
+		described:   l.described,
+		level:       l.level,
+		overrides:   l.overrides,
+		out:         l.out,
+		wrapperSkip: l.wrapperSkip,
+		templates:   l.templates,
+		access:      l.access,
+		spanCtx:     l.spanCtx,
+
+		startupMessage: l.startupMessage,
 	}
 }
 
@@ -173,51 +794,207 @@ func (l *Logger) clone() *Logger {
 // See https://github.com/uber-go/zap/issues/680 for more info.
 
 // // Trace is an alias for Debug
-// func (l *Logger) Trace(args ...interface{}) { l.zap.Debug(args...) }
+// func (l *Logger) Trace(args ...interface{}) { l.sugar().Debug(args...) }
 
 // // Tracef is an alias for Debugf
-// func (l *Logger) Tracef(format string, args ...interface{}) { l.zap.Debugf(format, args...) }
+// func (l *Logger) Tracef(format string, args ...interface{}) { l.sugar().Debugf(format, args...) }
 
 // // Traceln is an alias for Debugln
-// func (l *Logger) Traceln(args ...interface{}) { l.zap.Debug(sprintln(args...)) }
+// func (l *Logger) Traceln(args ...interface{}) { l.sugar().Debug(sprintln(args...)) }
 
-func (l *Logger) Debug(args ...interface{})                 { l.zap.Debug(args...) }
-func (l *Logger) Debugf(format string, args ...interface{}) { l.zap.Debugf(format, args...) }
-func (l *Logger) Debugln(args ...interface{})               { l.zap.Debug(sprintln(args...)) }
+func (l *Logger) Debug(args ...interface{}) { l.sugar().Debug(args...) }
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if l.templated(DebugLevel) {
+		l.logTemplated(DebugLevel, format, args)
+		return
+	}
+	l.sugar().Debugf(format, args...)
+}
+func (l *Logger) Debugln(args ...interface{}) { l.sugar().Debug(sprintln(args...)) }
+
+func (l *Logger) Info(args ...interface{}) { l.sugar().Info(args...) }
+func (l *Logger) Infof(format string, args ...interface{}) {
+	if l.templated(InfoLevel) {
+		l.logTemplated(InfoLevel, format, args)
+		return
+	}
+	l.sugar().Infof(format, args...)
+}
+func (l *Logger) Infoln(args ...interface{}) { l.sugar().Info(sprintln(args...)) }
+
+func (l *Logger) Warn(args ...interface{}) { l.sugar().Warn(args...) }
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	if l.templated(WarnLevel) {
+		l.logTemplated(WarnLevel, format, args)
+		return
+	}
+	l.sugar().Warnf(format, args...)
+}
+func (l *Logger) Warnln(args ...interface{}) { l.sugar().Warn(sprintln(args...)) }
+
+func (l *Logger) Warning(args ...interface{}) { l.sugar().Warn(args...) }
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	if l.templated(WarnLevel) {
+		l.logTemplated(WarnLevel, format, args)
+		return
+	}
+	l.sugar().Warnf(format, args...)
+}
+func (l *Logger) Warningln(args ...interface{}) { l.sugar().Warn(sprintln(args...)) }
+
+func (l *Logger) Error(args ...interface{}) { l.sugar().Error(args...) }
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	if l.templated(ErrorLevel) {
+		l.logTemplated(ErrorLevel, format, args)
+		return
+	}
+	l.sugar().Errorf(format, args...)
+}
+func (l *Logger) Errorln(args ...interface{}) { l.sugar().Error(sprintln(args...)) }
+
+// Errorr logs the message with the error at Error level and returns the error wrapped with the message.
+// It collapses the "log then return errors.Wrap" two-liner. Nothing is logged for a nil error
+func (l *Logger) Errorr(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	l.base().Error(msg, errorFields(err)...)
+	return errors.Wrap(err, msg)
+}
+
+// LogStartup logs the summary of the application configuration at Info level with Config.StartupMessage
+func (l *Logger) LogStartup(cfgSummary map[string]interface{}) {
+	msg := defaultStartupMessage
+	if l != nil && l.startupMessage != "" {
+		msg = l.startupMessage
+	}
+	l.base().Info(msg, mapToFields(cfgSummary)...)
+}
+
+func (l *Logger) Fatal(args ...interface{}) { l.sugar().Fatal(args...) }
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	if l.templated(FatalLevel) {
+		l.logTemplated(FatalLevel, format, args)
+		return
+	}
+	l.sugar().Fatalf(format, args...)
+}
+func (l *Logger) Fatalln(args ...interface{}) { l.sugar().Fatal(sprintln(args...)) }
 
-func (l *Logger) Info(args ...interface{})                 { l.zap.Info(args...) }
-func (l *Logger) Infof(format string, args ...interface{}) { l.zap.Infof(format, args...) }
-func (l *Logger) Infoln(args ...interface{})               { l.zap.Info(sprintln(args...)) }
+// Panic logs a message with the panic fields (see RecoverAndLog) and panics with the message
+func (l *Logger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	l.base().Panic(msg, panicFields(panicValue(msg, args), 1)...)
+}
 
-func (l *Logger) Warn(args ...interface{})                 { l.zap.Warn(args...) }
-func (l *Logger) Warnf(format string, args ...interface{}) { l.zap.Warnf(format, args...) }
-func (l *Logger) Warnln(args ...interface{})               { l.zap.Warn(sprintln(args...)) }
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	fields := panicFields(msg, 1)
+	if l != nil && l.templates {
+		fields = append(fields, templateFields(format, args)...)
+	}
+	l.base().Panic(msg, fields...)
+}
 
-func (l *Logger) Warning(args ...interface{})                 { l.zap.Warn(args...) }
-func (l *Logger) Warningf(format string, args ...interface{}) { l.zap.Warnf(format, args...) }
-func (l *Logger) Warningln(args ...interface{})               { l.zap.Warn(sprintln(args...)) }
+func (l *Logger) Panicln(args ...interface{}) {
+	msg := sprintln(args...)
+	l.base().Panic(msg, panicFields(panicValue(msg, args), 1)...)
+}
 
-func (l *Logger) Error(args ...interface{})                 { l.zap.Error(args...) }
-func (l *Logger) Errorf(format string, args ...interface{}) { l.zap.Errorf(format, args...) }
-func (l *Logger) Errorln(args ...interface{})               { l.zap.Error(sprintln(args...)) }
+// DebugFields logs a message with strongly-typed fields bypassing the sugared layer.
+// Prefer *Fields methods on hot paths, as they don't use reflection
+func (l *Logger) DebugFields(msg string, fields ...zap.Field) { l.base().Debug(msg, fields...) }
+func (l *Logger) InfoFields(msg string, fields ...zap.Field)  { l.base().Info(msg, fields...) }
+func (l *Logger) WarnFields(msg string, fields ...zap.Field)  { l.base().Warn(msg, fields...) }
+func (l *Logger) ErrorFields(msg string, fields ...zap.Field) { l.base().Error(msg, fields...) }
+func (l *Logger) FatalFields(msg string, fields ...zap.Field) { l.base().Fatal(msg, fields...) }
+func (l *Logger) PanicFields(msg string, fields ...zap.Field) { l.base().Panic(msg, fields...) }
+
+func (l *Logger) Print(args ...interface{}) { l.sugar().Info(args...) }
+func (l *Logger) Printf(format string, args ...interface{}) {
+	if l.templated(InfoLevel) {
+		l.logTemplated(InfoLevel, format, args)
+		return
+	}
+	l.sugar().Infof(format, args...)
+}
+func (l *Logger) Println(args ...interface{}) { l.sugar().Info(sprintln(args...)) }
+
+// Sync flushes any buffered log entries. Outputs which can't be synced, like stdout attached to a terminal,
+// are skipped. Failures are returned as *SyncError reporting the failed outputs
+func (l *Logger) Sync() error { return newSyncError(l.sugar().Sync()) }
+
+// Shutdown stops accepting new entries, drains the output queues, flushes and closes the outputs.
+// Entries logged after Shutdown are dropped. It returns ctx.Err() if ctx is done before the outputs are closed.
+// Shutdown affects the logger and all the loggers derived from it, their names are removed from the Registry
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	registry.remove(l.level, l.overrides)
+	return l.out.shutdown(ctx, l.base().Core())
+}
 
-func (l *Logger) Fatal(args ...interface{})                 { l.zap.Fatal(args...) }
-func (l *Logger) Fatalf(format string, args ...interface{}) { l.zap.Fatalf(format, args...) }
-func (l *Logger) Fatalln(args ...interface{})               { l.zap.Fatal(sprintln(args...)) }
+// Rotate rotates all files opened by the logger. Rotated files are renamed to "<name>.<time>.<ext>"
+func (l *Logger) Rotate() error {
+	if l == nil {
+		return nil
+	}
+	return l.out.rotate()
+}
 
-func (l *Logger) Panic(args ...interface{})                 { l.zap.Panic(args...) }
-func (l *Logger) Panicf(format string, args ...interface{}) { l.zap.Panicf(format, args...) }
-func (l *Logger) Panicln(args ...interface{})               { l.zap.Panic(sprintln(args...)) }
+// templated reports whether the template fields are added to enabled formatted entries, see Config.PreserveTemplates
+func (l *Logger) templated(lvl zapcore.Level) bool {
+	return l != nil && l.templates && l.base().Core().Enabled(lvl)
+}
+
+// logTemplated logs the formatted message with the template fields. It must be called directly by a Logger method
+func (l *Logger) logTemplated(lvl zapcore.Level, format string, args []interface{}) {
+	msg := format
+	if len(args) != 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	// Only the caller skip is changed, so the cores and their fields aren't cloned
+	if ce := l.base().WithOptions(zap.AddCallerSkip(1)).Check(lvl, msg); ce != nil {
+		ce.Write(templateFields(format, args)...)
+	}
+}
 
-func (l *Logger) Print(args ...interface{})                 { l.zap.Info(args...) }
-func (l *Logger) Printf(format string, args ...interface{}) { l.zap.Infof(format, args...) }
-func (l *Logger) Println(args ...interface{})               { l.zap.Info(sprintln(args...)) }
+func templateFields(format string, args []interface{}) []zap.Field {
+	return []zap.Field{zap.String("msg_template", format), zap.Any("msg_args", args)}
+}
+
+// fixedClock is a zapcore.Clock always returning the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
 
-// Sync flushes any buffered log entries
-func (l *Logger) Sync() error { return l.zap.Sync() }
+func (c fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
 
-// sprintln returns the result of fmt.Sprintln without the trailing \n
+var bufferPool = buffer.NewPool()
+
+// sprintln returns the result of fmt.Sprintln without the trailing \n.
+// It's built in a pooled buffer, with common types appended without fmt
 func sprintln(args ...interface{}) string {
-	msg := fmt.Sprintln(args...)
-	return msg[:len(msg)-1]
+	buf := bufferPool.Get()
+	defer buf.Free()
+
+	for i, arg := range args {
+		if i > 0 {
+			buf.AppendByte(' ')
+		}
+		switch v := arg.(type) {
+		case string:
+			buf.AppendString(v)
+		case int:
+			buf.AppendInt(int64(v))
+		case int64:
+			buf.AppendInt(v)
+		case bool:
+			buf.AppendBool(v)
+		default:
+			_, _ = fmt.Fprint(buf, v)
+		}
+	}
+	return buf.String()
 }
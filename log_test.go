@@ -3,13 +3,16 @@ package logger
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // func TestCatchFatal(t *testing.T) {
@@ -124,6 +127,150 @@ func TestWithFields(t *testing.T) {
 	checkFileLogs(t, filename, expectedMsgs)
 }
 
+func TestTypedFields(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{Files: []string{filename}})
+
+	expectedMsgs := [][]string{
+		{`info	{"a": 1, "b": "2"`},
+		{`error	{"a": 1, "c": true`},
+	}
+
+	log = log.WithField("a", 1)
+	log.InfoFields("info", zap.String("b", "2"))
+	log.ErrorFields("error", zap.Bool("c", true))
+
+	checkFileLogs(t, filename, expectedMsgs)
+}
+
+func TestWithTime(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{Files: []string{filename}})
+
+	expectedMsgs := [][]string{
+		{"2020-01-02 03:04:05", "replayed"},
+		{"2020-01-02 03:04:05", `replayed	{"a": 1`},
+	}
+
+	log = log.WithTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local))
+	log.Info("replayed")
+	log.WithField("a", 1).Info("replayed")
+
+	checkFileLogs(t, filename, expectedMsgs)
+}
+
+func TestErrorr(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{Files: []string{filename}})
+
+	expectedMsgs := [][]string{
+		{"log_test.go", `failed to do	{"a": 1, "error": "some error"`},
+	}
+
+	err := log.WithField("a", 1).Errorr(errors.New("some error"), "failed to do")
+	if err == nil || err.Error() != "failed to do: some error" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := log.Errorr(nil, "failed to do"); err != nil {
+		t.Errorf("want nil error, got %v", err)
+	}
+
+	checkFileLogs(t, filename, expectedMsgs)
+}
+
+func TestLogStartup(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, LogStartup: true})
+
+	expectedMsgs := [][]string{
+		{"logger configured", `"level": "debug"`, `"outputs": ["` + filename + `"]`},
+		{"log_test.go", "started", `"version": "1.0.0"`},
+	}
+
+	log.LogStartup(map[string]interface{}{"version": "1.0.0"})
+
+	checkFileLogs(t, filename, expectedMsgs)
+}
+
+func TestLogStartupMessage(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, StartupMessage: "service ready"})
+
+	log.WithField("a", 1).LogStartup(map[string]interface{}{"version": "1.0.0"})
+
+	checkFileLogs(t, filename, [][]string{{"service ready", `"version": "1.0.0"`}})
+}
+
+func TestObserve(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+	log.SetLevel("info")
+
+	log.Debug("skipped")
+	log.WithField("a", 1).Info("info")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Message != "info" || e.ContextMap()["a"] != int64(1) || !strings.HasSuffix(e.Caller.File, "log_test.go") {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	if NewNoop().ObservedLogs() != nil {
+		t.Error("want nil observed logs")
+	}
+}
+
+func TestZapOptions(t *testing.T) {
+	now := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Observe:       true,
+		ZapOptions:    []zap.Option{zap.WithClock(fixedClock(now)), zap.Fields(zap.String("app", "test"))},
+	})
+
+	log.Info("info")
+
+	e := log.ObservedLogs().All()[0]
+	if !e.Time.Equal(now) || e.ContextMap()["app"] != "test" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestPreserveTemplates(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Encoding: EncodingJSON, PreserveTemplates: true, Files: []string{filename}})
+
+	log.Infof("user %s logged in %d times", "john", 2)
+	log.Info("plain")
+
+	checkFileLogs(t, filename, [][]string{
+		{`/log_test.go:`, `"msg":"user john logged in 2 times"`, `"msg_template":"user %s logged in %d times"`, `"msg_args":["john",2]`},
+		{`"msg":"plain"}`},
+	})
+}
+
+func TestSprintln(t *testing.T) {
+	var nilErr error
+	for _, args := range [][]interface{}{
+		{},
+		{"a", "b"},
+		{1, int64(-2), true, 1.5, nilErr, errors.New("err"), []int{1}, time.Second},
+	} {
+		want := fmt.Sprintln(args...)
+		if got := sprintln(args...); got+"\n" != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
+
+func BenchmarkSprintln(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = sprintln("user", 42, "logged in", true)
+	}
+}
+
 func TestCaller(t *testing.T) {
 	// Check only filepath. Line numbers are too unreliable
 	const callerPath = "log_test.go"
@@ -155,6 +302,12 @@ func TestCaller(t *testing.T) {
 	log.Print("1")
 	log.Printf("1")
 	log.Println("1")
+	log.DebugFields("1")
+	log.InfoFields("1")
+	log.WarnFields("1")
+	log.ErrorFields("1")
+	log.ZapDesugared().Info("1")
+	log.WithField("a", 1).ZapDesugared().Info("1")
 
 	data := readFile(t, filename)
 	scan := bufio.NewScanner(bytes.NewBuffer(data))
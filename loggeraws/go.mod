@@ -0,0 +1,15 @@
+module github.com/kiteggrad/logger/loggeraws
+
+go 1.21
+
+require (
+	github.com/aws/smithy-go v1.22.1
+	github.com/kiteggrad/logger v0.0.0-20261016132221-2958e7ae13fc
+)
+
+require (
+	github.com/pkg/errors v0.9.1 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/zap v1.22.0 // indirect
+)
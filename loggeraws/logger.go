@@ -0,0 +1,37 @@
+// Package loggeraws is the AWS SDK v2 logger of the logger, see logger.AWSLogger.
+// It's a separate module, so the logger doesn't depend on smithy-go
+package loggeraws
+
+import (
+	"github.com/aws/smithy-go/logging"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Logger is a smithy-go logging.Logger logging the SDK messages with logger.AWSLogger, one per client
+// to toggle them separately:
+//
+//	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+//		o.ClientLogMode = aws.LogRetries | aws.LogResponse
+//		o.Logger = loggeraws.New(log.Named("s3"))
+//	})
+type Logger struct {
+	aws *logger.AWSLogger
+}
+
+var _ logging.Logger = (*Logger)(nil)
+
+// New creates an enabled AWS SDK logger
+func New(log *logger.Logger) *Logger {
+	return &Logger{aws: logger.NewAWSLogger(log)}
+}
+
+// SetEnabled turns the logging of the client on or off
+func (l *Logger) SetEnabled(enabled bool) {
+	l.aws.SetEnabled(enabled)
+}
+
+// Logf logs an SDK message of the classification
+func (l *Logger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	l.aws.Logf(string(classification), format, v...)
+}
@@ -0,0 +1,40 @@
+package loggeraws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/logging"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestLogger(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+	log.SetLevel("debug")
+
+	var sdkLog logging.Logger = New(log)
+	sdkLog.Logf(logging.Debug, "retrying request %s/%s, attempt %d", "S3", "GetObject", 2)
+	sdkLog.Logf(logging.Warn, "failed to rewind transport stream for retry")
+	sdkLog.(*Logger).SetEnabled(false)
+	sdkLog.Logf(logging.Warn, "dropped")
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(buf.Bytes())), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 entries, got %s", buf.Bytes())
+	}
+	for i, want := range [][]string{
+		{`"aws.attempt":2`, `"aws.operation":"GetObject"`, `"aws.service":"S3"`, `"level":"debug"`},
+		{`"level":"warn"`, `"msg":"failed to rewind transport stream for retry"`},
+	} {
+		for _, s := range want {
+			if !strings.Contains(lines[i], s) {
+				t.Errorf("want %s in line %d: %s", s, i, lines[i])
+			}
+		}
+	}
+}
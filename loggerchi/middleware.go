@@ -0,0 +1,29 @@
+// Package loggerchi is the chi middleware of the logger, see logger.Logger.HTTPMiddleware.
+// It's a separate module, so the logger doesn't depend on chi
+package loggerchi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Middleware returns logger.Logger.HTTPMiddleware adding the matched chi route pattern
+// (e.g. "/users/{id}") to the access log, unless cfg.Route is set:
+//
+//	router.Use(loggerchi.Middleware(log, logger.HTTPConfig{}))
+func Middleware(log *logger.Logger, cfg logger.HTTPConfig) func(http.Handler) http.Handler {
+	if cfg.Route == nil {
+		cfg.Route = routePattern
+	}
+	return log.HTTPMiddleware(cfg)
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
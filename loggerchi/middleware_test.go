@@ -0,0 +1,45 @@
+package loggerchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestMiddleware(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+
+	router := chi.NewRouter()
+	router.Use(Middleware(log, logger.HTTPConfig{}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("handled")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	router.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/users/42", "/panic"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{
+		`"msg":"handled","path":"/users/42"`,
+		`"route":"/users/{id}","size":0,"status":204`,
+		`"path":"/panic","route":"/panic","size":0,"status":500`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package loggerecho is the echo middleware of the logger, see logger.Logger.HTTPMiddleware.
+// It's a separate module, so the logger doesn't depend on echo
+package loggerecho
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Middleware returns an echo middleware doing what logger.Logger.HTTPMiddleware does:
+// the request logger is stored in the request context (see logger.FromContext(c.Request().Context())),
+// requests are logged with the matched route (e.g. "/users/:id", unless cfg.Route is set)
+// and panics of the handlers are recovered.
+// Handler errors are passed to the echo error handler before the request is logged, so the access log has their status:
+//
+//	e.Use(loggerecho.Middleware(log, logger.HTTPConfig{}))
+func Middleware(log *logger.Logger, cfg logger.HTTPConfig) echo.MiddlewareFunc {
+	if cfg.Route == nil {
+		cfg.Route = routePath
+	}
+	middleware := log.HTTPMiddleware(cfg)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			original := res.Writer
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				// The handlers write through the middleware, so it sees the response status and size
+				res.Writer = w
+				if err := next(c); err != nil {
+					c.Error(err)
+				}
+			})
+
+			r := c.Request()
+			middleware(handler).ServeHTTP(original, r.WithContext(context.WithValue(r.Context(), echoContextKey{}, c)))
+			res.Writer = original
+			return nil
+		}
+	}
+}
+
+// echoContextKey is the request context key of the echo context, see routePath
+type echoContextKey struct{}
+
+// routePath returns the matched route of the request, e.g. "/users/:id"
+func routePath(r *http.Request) string {
+	if c, ok := r.Context().Value(echoContextKey{}).(echo.Context); ok {
+		return c.Path()
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package loggerecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestMiddleware(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+
+	e := echo.New()
+	e.Use(Middleware(log, logger.HTTPConfig{}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		logger.FromContext(c.Request().Context()).Info("handled")
+		return c.String(http.StatusCreated, "created")
+	})
+	e.GET("/missing", func(echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+	e.GET("/panic", func(echo.Context) error {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/users/42", "/missing", "/panic"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{
+		`"msg":"handled","path":"/users/42"`,
+		`"route":"/users/:id","size":7,"status":201`,
+		`"path":"/missing","route":"/missing",`,
+		`"status":404`,
+		`"msg":"recovered from panic"`,
+		`"path":"/panic","route":"/panic","size":0,"status":500`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package loggerfiber is the fiber middleware of the logger, see logger.Logger.HTTPMiddleware.
+// It's a separate module, so the logger doesn't depend on fiber
+package loggerfiber
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Middleware returns a fiber middleware doing what logger.Logger.HTTPMiddleware does:
+// the request logger is stored in the user context (see logger.FromContext(c.UserContext())),
+// requests are logged with the matched route (e.g. "/users/:id", unless cfg.Route is set)
+// and panics of the handlers are recovered.
+// Handler errors are passed to the app error handler before the request is logged, so the access log has their status.
+// The request is converted to net/http for the middleware, cfg.Route and HTTPConfig hooks:
+//
+//	app.Use(loggerfiber.Middleware(log, logger.HTTPConfig{}))
+func Middleware(log *logger.Logger, cfg logger.HTTPConfig) fiber.Handler {
+	if cfg.Route == nil {
+		cfg.Route = routePath
+	}
+	middleware := log.HTTPMiddleware(cfg)
+
+	return func(c *fiber.Ctx) error {
+		r, err := newRequest(c)
+		if err != nil {
+			return err
+		}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.SetUserContext(r.Context())
+			if err := c.Next(); err != nil {
+				if err := c.App().ErrorHandler(c, err); err != nil {
+					_ = c.SendStatus(fiber.StatusInternalServerError)
+				}
+			}
+
+			// The handlers write to the fiber response, so the middleware gets its status and size afterwards
+			res := c.Response()
+			w.WriteHeader(res.StatusCode())
+			if !res.IsBodyStream() {
+				_, _ = w.Write(res.Body())
+			}
+		})
+
+		middleware(handler).ServeHTTP(responseWriter{c: c}, r)
+		return nil
+	}
+}
+
+// newRequest converts the fiber request to net/http. Strings are copied, since fiber reuses their memory
+func newRequest(c *fiber.Ctx) (*http.Request, error) {
+	ctx := context.WithValue(c.UserContext(), fiberContextKey{}, c)
+	r, err := http.NewRequestWithContext(ctx, strings.Clone(c.Method()), strings.Clone(c.OriginalURL()), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert request")
+	}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		r.Header.Add(string(key), string(value))
+	})
+	r.Host = string(c.Request().Host())
+	r.RemoteAddr = c.Context().RemoteAddr().String()
+	r.RequestURI = r.URL.RequestURI()
+	if body := c.Body(); len(body) != 0 {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	return r, nil
+}
+
+// fiberContextKey is the request context key of the fiber context, see routePath
+type fiberContextKey struct{}
+
+// routePath returns the matched route of the request, e.g. "/users/:id"
+func routePath(r *http.Request) string {
+	if c, ok := r.Context().Value(fiberContextKey{}).(*fiber.Ctx); ok {
+		return c.Route().Path
+	}
+	return ""
+}
+
+// responseWriter is the net/http writer of the middleware. The response is already written by the fiber handlers,
+// so only the status is set, e.g. the 500 of a recovered panic
+type responseWriter struct {
+	c *fiber.Ctx
+}
+
+func (w responseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+func (w responseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w responseWriter) WriteHeader(code int) {
+	w.c.Status(code)
+}
@@ -0,0 +1,54 @@
+package loggerfiber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestMiddleware(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+
+	app := fiber.New()
+	app.Use(Middleware(log, logger.HTTPConfig{}))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		logger.FromContext(c.UserContext()).Info("handled")
+		return c.Status(http.StatusCreated).SendString("created")
+	})
+	app.Get("/missing", func(*fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+	app.Get("/panic", func(*fiber.Ctx) error {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/users/42", "/missing", "/panic"} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{
+		`"msg":"handled","path":"/users/42"`,
+		`"route":"/users/:id","size":7,"status":201`,
+		`"path":"/missing","route":"/missing","size":9,"status":404`,
+		`"msg":"recovered from panic"`,
+		`"path":"/panic","route":"/panic","size":0,"status":500`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package loggergin is the gin middleware of the logger, see logger.Logger.HTTPMiddleware.
+// It's a separate module, so the logger doesn't depend on gin
+package loggergin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Middleware returns a gin middleware doing what logger.Logger.HTTPMiddleware does:
+// the request logger is stored in the request context (see logger.FromContext(c.Request.Context())),
+// requests are logged with the matched route (e.g. "/users/:id", unless cfg.Route is set)
+// and panics of the handlers are recovered:
+//
+//	engine.Use(loggergin.Middleware(log, logger.HTTPConfig{}))
+func Middleware(log *logger.Logger, cfg logger.HTTPConfig) gin.HandlerFunc {
+	if cfg.Route == nil {
+		cfg.Route = fullPath
+	}
+	middleware := log.HTTPMiddleware(cfg)
+
+	return func(c *gin.Context) {
+		original := c.Writer
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.Request = r
+			// The handlers write through the middleware, so it sees the response status and size
+			c.Writer = &responseWriter{ResponseWriter: original, w: w}
+			c.Next()
+		})
+
+		r := c.Request.WithContext(context.WithValue(c.Request.Context(), ginContextKey{}, c))
+		middleware(next).ServeHTTP(original, r)
+		c.Writer = original
+	}
+}
+
+// ginContextKey is the request context key of the gin context, see fullPath
+type ginContextKey struct{}
+
+// fullPath returns the matched route of the request, e.g. "/users/:id"
+func fullPath(r *http.Request) string {
+	if c, ok := r.Context().Value(ginContextKey{}).(*gin.Context); ok {
+		return c.FullPath()
+	}
+	return ""
+}
+
+// responseWriter is the gin.ResponseWriter of the handlers writing through the middleware writer w.
+// The rest of gin.ResponseWriter (e.g. Status) is served by the original writer, which receives the writes
+type responseWriter struct {
+	gin.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.w.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	return w.w.Write(data)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
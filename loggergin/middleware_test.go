@@ -0,0 +1,47 @@
+package loggergin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, buf := loggertest.New(t, logger.Config{})
+
+	engine := gin.New()
+	engine.Use(Middleware(log, logger.HTTPConfig{}))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("handled")
+		c.String(http.StatusCreated, "created")
+	})
+	engine.GET("/panic", func(*gin.Context) {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/users/42", "/panic"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{
+		`"msg":"handled","path":"/users/42"`,
+		`"route":"/users/:id","size":7,"status":201`,
+		`"msg":"recovered from panic"`,
+		`"path":"/panic","route":"/panic","size":0,"status":500`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/kiteggrad/logger/loggergrpc
+
+go 1.21
+
+require (
+	github.com/kiteggrad/logger v0.0.0-20261016132221-2958e7ae13fc
+	github.com/pkg/errors v0.9.1
+	go.uber.org/zap v1.22.0
+	google.golang.org/grpc v1.64.1
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+)
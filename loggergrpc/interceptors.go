@@ -0,0 +1,112 @@
+package loggergrpc
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/kiteggrad/logger"
+)
+
+// UnaryServerInterceptor returns the gRPC counterpart of logger.Logger.HTTPMiddleware for unary RPCs:
+// it stores the RPC logger with the method, peer and deadline fields in the context (see logger.FromContext),
+// logs completed RPCs with the status code and latency (see logger.RPCCall.Finish)
+// and recovers panics of the handler, logging them and returning the Internal status
+func UnaryServerInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, call := log.StartRPC(ctx, info.FullMethod, peerAddr(ctx))
+		defer func() { call.Finish(status.Code(err).String(), err) }()
+		defer call.Recover(&err, internalError)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs. The RPC logger is in the stream context
+func StreamServerInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, call := log.StartRPC(ss.Context(), info.FullMethod, peerAddr(ss.Context()))
+		defer func() { call.Finish(status.Code(err).String(), err) }()
+		defer call.Recover(&err, internalError)
+		return handler(srv, &loggedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor storing the RPC logger with the method, target and deadline fields
+// in the context of the call and logging completed calls with the status code and latency
+func UnaryClientInterceptor(log *logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, call := log.StartRPC(ctx, method, cc.Target())
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		call.Finish(status.Code(err).String(), err)
+		return err
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming RPCs.
+// A stream is logged once RecvMsg returns the final response or an error,
+// so streams abandoned without receiving the end of the stream aren't logged
+func StreamClientInterceptor(log *logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, call := log.StartRPC(ctx, method, cc.Target())
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			call.Finish(status.Code(err).String(), err)
+			return nil, err
+		}
+		return &loggedClientStream{ClientStream: cs, call: call, serverStreams: desc.ServerStreams}, nil
+	}
+}
+
+// peerAddr returns the address of the RPC peer, empty if it's unknown
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// internalError is the error returned for recovered panics
+func internalError(interface{}) error {
+	return status.Error(codes.Internal, "internal error")
+}
+
+// loggedServerStream replaces the context of the stream with the one of the RPC logger
+type loggedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// loggedClientStream logs the call once the stream ends
+type loggedClientStream struct {
+	grpc.ClientStream
+	call          *logger.RPCCall
+	serverStreams bool
+	once          sync.Once
+}
+
+func (s *loggedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.serverStreams:
+		// The single response ends the stream
+		s.finish(nil)
+	}
+	return err
+}
+
+func (s *loggedClientStream) finish(err error) {
+	s.once.Do(func() { s.call.Finish(status.Code(err).String(), err) })
+}
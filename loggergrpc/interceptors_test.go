@@ -0,0 +1,99 @@
+package loggergrpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggergrpc/loggerv1"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestInterceptors(t *testing.T) {
+	serverLog, serverBuf := loggertest.New(t, logger.Config{})
+	clientLog, clientBuf := loggertest.New(t, logger.Config{})
+	collected, _ := loggertest.New(t, logger.Config{})
+
+	conn := dialWith(t,
+		func(s *grpc.Server) {
+			grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+			loggerv1.RegisterLogCollectorServer(s, NewCollector(collected))
+		},
+		[]grpc.ServerOption{
+			grpc.UnaryInterceptor(UnaryServerInterceptor(serverLog)),
+			grpc.StreamInterceptor(StreamServerInterceptor(serverLog)),
+		},
+		[]grpc.DialOption{
+			grpc.WithUnaryInterceptor(UnaryClientInterceptor(clientLog)),
+			grpc.WithStreamInterceptor(StreamClientInterceptor(clientLog)),
+		},
+	)
+
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+	if _, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound, got %v", err)
+	}
+
+	// The entries are streamed by a client streaming RPC
+	log, err := logger.New(logger.Config{
+		DisableStdOut: true,
+		Cores:         []zapcore.Core{NewCore(conn, logger.StreamConfig{})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("streamed")
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, buf := range map[string]*loggertest.Buffer{"server": serverBuf, "client": clientBuf} {
+		got := string(buf.Bytes())
+		for _, want := range []string{
+			`"msg":"rpc completed","rpc.code":"OK","rpc.method":"/grpc.health.v1.Health/Check"`,
+			`"msg":"rpc completed","rpc.code":"NotFound","rpc.method":"/grpc.health.v1.Health/Check"`,
+			`"msg":"rpc completed","rpc.code":"OK","rpc.method":"/logger.v1.LogCollector/Stream"`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("want %s in the %s entries, got %s", want, name, got)
+			}
+		}
+	}
+}
+
+func TestUnaryServerInterceptorRecovery(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+
+	interceptor := UnaryServerInterceptor(log)
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Panic"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger.FromContext(ctx).Info("handling")
+			panic("boom")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("want Internal, got %v", err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{
+		`"msg":"handling","rpc.method":"/svc/Panic"`,
+		`"msg":"recovered from panic"`,
+		`"level":"error","msg":"rpc completed","rpc.code":"Internal"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
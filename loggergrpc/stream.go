@@ -0,0 +1,130 @@
+// Package loggergrpc provides the gRPC transport of stream cores (see logger.NewStreamCore):
+// a client streaming entries to a LogCollector service and the collector server writing them to a logger
+package loggergrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggergrpc/loggerv1"
+)
+
+// NewCore creates a core streaming entries to the LogCollector service of the connection,
+// e.g. a *grpc.ClientConn. Add it with Config.Cores, the stream is closed by Logger.Shutdown
+func NewCore(conn grpc.ClientConnInterface, cfg logger.StreamConfig) zapcore.Core {
+	return logger.NewStreamCore(OpenStream(conn), cfg)
+}
+
+// OpenStream returns a function opening LogCollector streams of the connection
+func OpenStream(conn grpc.ClientConnInterface) logger.OpenStreamFunc {
+	client := loggerv1.NewLogCollectorClient(conn)
+	return func(ctx context.Context) (logger.EntryStream, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		stream, err := client.Stream(ctx)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "failed to open LogCollector stream")
+		}
+		return &clientStream{stream: stream, cancel: cancel}, nil
+	}
+}
+
+// clientStream adapts the generated client stream to logger.EntryStream
+type clientStream struct {
+	stream loggerv1.LogCollector_StreamClient
+	cancel context.CancelFunc
+}
+
+func (s *clientStream) Send(e logger.Entry) error {
+	return s.stream.Send(toProto(e))
+}
+
+// CloseSend closes the stream and waits until the collector receives all the sent entries
+func (s *clientStream) CloseSend() error {
+	defer s.cancel()
+	_, err := s.stream.CloseAndRecv()
+	return err
+}
+
+// collector is the LogCollector service writing the received entries to a logger
+type collector struct {
+	loggerv1.UnimplementedLogCollectorServer
+	log *logger.Logger
+}
+
+// NewCollector returns the LogCollector service writing the received entries to the logger, register it with
+// loggerv1.RegisterLogCollectorServer. Entries of unknown levels are written at Info level
+func NewCollector(log *logger.Logger) loggerv1.LogCollectorServer {
+	return &collector{log: log}
+}
+
+func (c *collector) Stream(stream loggerv1.LogCollector_StreamServer) error {
+	received, err := logger.ServeStream(c.log, func() (logger.Entry, error) {
+		msg, err := stream.Recv()
+		if err != nil {
+			return logger.Entry{}, err
+		}
+		return fromProto(msg), nil
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&loggerv1.StreamSummary{Received: received})
+}
+
+func toProto(e logger.Entry) *loggerv1.Entry {
+	msg := &loggerv1.Entry{
+		Level:      e.Level.String(),
+		LoggerName: e.LoggerName,
+		Message:    e.Message,
+	}
+	if !e.Time.IsZero() {
+		msg.Time = timestamppb.New(e.Time)
+	}
+	if len(e.Fields) != 0 {
+		msg.Fields = &structpb.Struct{Fields: make(map[string]*structpb.Value, len(e.Fields))}
+		for key, value := range e.Fields {
+			msg.Fields.Fields[key] = toValue(value)
+		}
+	}
+	return msg
+}
+
+// toValue converts a field value to a protobuf value. Values structpb doesn't support (e.g. time.Time)
+// are converted by their JSON encoding, or formatted if they can't be encoded
+func toValue(v interface{}) *structpb.Value {
+	if value, err := structpb.NewValue(v); err == nil {
+		return value
+	}
+
+	var decoded interface{}
+	if data, err := json.Marshal(v); err == nil && json.Unmarshal(data, &decoded) == nil {
+		if value, err := structpb.NewValue(decoded); err == nil {
+			return value
+		}
+	}
+	return structpb.NewStringValue(fmt.Sprint(v))
+}
+
+func fromProto(msg *loggerv1.Entry) logger.Entry {
+	e := logger.Entry{
+		LoggerName: msg.GetLoggerName(),
+		Message:    msg.GetMessage(),
+		Fields:     msg.GetFields().AsMap(),
+	}
+	if msg.GetTime() != nil {
+		e.Time = msg.GetTime().AsTime().Local()
+	}
+	if err := e.Level.UnmarshalText([]byte(msg.GetLevel())); err != nil {
+		e.Level = zapcore.InfoLevel
+	}
+	return e
+}
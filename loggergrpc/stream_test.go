@@ -0,0 +1,73 @@
+package loggergrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggergrpc/loggerv1"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+// dial starts a server with the service registered and returns a connection to it
+func dial(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	return dialWith(t, register, nil, nil)
+}
+
+// dialWith is dial with the server and client options
+func dialWith(t *testing.T, register func(s *grpc.Server), serverOpts []grpc.ServerOption, dialOpts []grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	dialOpts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, dialOpts...)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestStream(t *testing.T) {
+	collected, buf := loggertest.New(t, logger.Config{})
+	conn := dial(t, func(s *grpc.Server) { loggerv1.RegisterLogCollectorServer(s, NewCollector(collected)) })
+
+	log, err := logger.New(logger.Config{
+		DisableStdOut: true,
+		Cores:         []zapcore.Core{NewCore(conn, logger.StreamConfig{Level: zapcore.InfoLevel})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Named("api").WithField("user", "john").Info("logged in")
+	log.Debug("skipped")
+	// Closes the stream, waiting until the collector receives the entries
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf.Bytes())
+	for _, want := range []string{`"logger":"api"`, `"msg":"logged in"`, `"user":"john"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in the collected entries, got %s", want, got)
+		}
+	}
+	if strings.Contains(got, "skipped") {
+		t.Errorf("want entries below the stream level skipped, got %s", got)
+	}
+}
@@ -0,0 +1,60 @@
+// Package loggermock provides a logger.Interface implementation recording entries in memory
+package loggermock
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kiteggrad/logger"
+)
+
+// Logger is a logger.Interface implementation recording all the entries.
+// Loggers returned by its With* methods record into the same Logger.
+// Fatal methods don't exit, Panic methods still panic
+type Logger struct {
+	*logger.Logger
+
+	logs *observer.ObservedLogs
+
+	mu     sync.Mutex
+	levels []string
+}
+
+var _ logger.Interface = (*Logger)(nil)
+
+// New creates a new mock logger recording entries of all levels
+func New() *Logger {
+	core, logs := observer.New(zapcore.DebugLevel)
+	z := zap.New(core, zap.WithFatalHook(noopHook{}))
+
+	return &Logger{
+		Logger: logger.NewWith(z, zapcore.DebugLevel),
+		logs:   logs,
+	}
+}
+
+// Logs returns the recorded entries
+func (m *Logger) Logs() *observer.ObservedLogs {
+	return m.logs
+}
+
+// SetLevel records the level. The level doesn't affect recording
+func (m *Logger) SetLevel(lvl string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.levels = append(m.levels, lvl)
+}
+
+// Levels returns all the levels passed to SetLevel
+func (m *Logger) Levels() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.levels...)
+}
+
+type noopHook struct{}
+
+func (noopHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
@@ -0,0 +1,35 @@
+package loggermock
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger"
+)
+
+func TestLogger(t *testing.T) {
+	mock := New()
+
+	var log logger.Interface = mock
+	log.SetLevel("info")
+	log.Info("info")
+	log.WithError(errors.New("some error")).Errorf("error %d", 1)
+	log.Fatal("fatal")
+
+	if levels := mock.Levels(); len(levels) != 1 || levels[0] != "info" {
+		t.Errorf("want [info] levels, got %v", levels)
+	}
+
+	entries := mock.Logs().AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+	if e := entries[1]; e.Level != zapcore.ErrorLevel || e.Message != "error 1" || e.ContextMap()["error"] != "some error" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[2]; e.Level != zapcore.FatalLevel {
+		t.Errorf("want fatal entry, got %+v", e)
+	}
+}
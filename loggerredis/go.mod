@@ -0,0 +1,17 @@
+module github.com/kiteggrad/logger/loggerredis
+
+go 1.21
+
+require (
+	github.com/kiteggrad/logger v0.0.0-20261016132221-2958e7ae13fc
+	github.com/pkg/errors v0.9.1
+	github.com/redis/go-redis/v9 v9.7.3
+	go.uber.org/zap v1.22.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+)
@@ -0,0 +1,100 @@
+// Package loggerredis is the go-redis hook of the logger logging slow and failed commands, see logger.RedisLogger
+// for the internal messages of go-redis. It's a separate module, so the logger doesn't depend on go-redis
+package loggerredis
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger"
+)
+
+const defaultSlowCommand = 100 * time.Millisecond
+
+// HookConfig configures NewHook
+type HookConfig struct {
+	// SlowCommand is the latency of commands and pipelines logged at Warn level. Defaults to 100ms
+	SlowCommand time.Duration
+}
+
+// Hook is a redis.Hook logging commands and pipelines with the latency and the error (if any)
+// with the fields of the context (see logger.RegisterContextExtractor): failed ones at Error level,
+// slow ones at Warn level and others at Debug level. Failed dials are logged at Error level.
+// Command arguments aren't logged, as they can contain personal data:
+//
+//	client.AddHook(loggerredis.NewHook(log.Named("redis"), loggerredis.HookConfig{SlowCommand: 50 * time.Millisecond}))
+type Hook struct {
+	log *logger.Logger
+	cfg HookConfig
+}
+
+var _ redis.Hook = (*Hook)(nil)
+
+// NewHook creates a go-redis hook
+func NewHook(log *logger.Logger, cfg HookConfig) *Hook {
+	if cfg.SlowCommand <= 0 {
+		cfg.SlowCommand = defaultSlowCommand
+	}
+	return &Hook{log: log, cfg: cfg}
+}
+
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.log.Ctx(ctx).Event(logger.ErrorLevel).Str("network", network).Str("addr", addr).Err(err).Msg("redis dial failed")
+		}
+		return conn, err
+	}
+}
+
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		latency := time.Since(start)
+		h.log.Ctx(ctx).Event(h.level(latency, err)).Str("command", cmd.FullName()).Dur("latency", latency).
+			Err(failure(err)).Msg("redis command")
+		return err
+	}
+}
+
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		latency := time.Since(start)
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.FullName()
+		}
+		h.log.Ctx(ctx).Event(h.level(latency, err)).Strs("commands", names).Dur("latency", latency).
+			Err(failure(err)).Msg("redis pipeline")
+		return err
+	}
+}
+
+// level returns the level of a command or pipeline with the latency and the error
+func (h *Hook) level(latency time.Duration, err error) zapcore.Level {
+	switch {
+	case failure(err) != nil:
+		return logger.ErrorLevel
+	case latency >= h.cfg.SlowCommand:
+		return logger.WarnLevel
+	default:
+		return logger.DebugLevel
+	}
+}
+
+// failure returns the error unless it's redis.Nil, the reply of missing keys
+func failure(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package loggerredis
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kiteggrad/logger"
+	"github.com/kiteggrad/logger/loggertest"
+)
+
+func TestHook(t *testing.T) {
+	log, buf := loggertest.New(t, logger.Config{})
+	log.SetLevel("debug")
+	hook := NewHook(log, HookConfig{SlowCommand: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		switch cmd.Name() {
+		case "get":
+			return redis.Nil
+		case "hgetall":
+			time.Sleep(30 * time.Millisecond)
+		case "set":
+			return errors.New("READONLY You can't write against a read only replica")
+		}
+		return nil
+	})
+	for _, cmd := range []redis.Cmder{
+		redis.NewStringCmd(ctx, "get", "session:42"),
+		redis.NewMapStringStringCmd(ctx, "hgetall", "user:42"),
+		redis.NewStatusCmd(ctx, "set", "user:42", "secret"),
+	} {
+		_ = process(ctx, cmd)
+	}
+	pipeline := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })
+	_ = pipeline(ctx, []redis.Cmder{redis.NewIntCmd(ctx, "incr", "hits"), redis.NewBoolCmd(ctx, "expire", "hits", 60)})
+	dial := hook.DialHook(func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+	_, _ = dial(ctx, "tcp", "localhost:6379")
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(buf.Bytes())), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("want 5 entries, got %s", buf.Bytes())
+	}
+	for i, want := range [][]string{
+		{`"command":"get"`, `"level":"debug"`, `"msg":"redis command"`},
+		{`"command":"hgetall"`, `"level":"warn"`},
+		{`"command":"set"`, `"error":"READONLY`, `"level":"error"`},
+		{`"commands":["incr","expire"]`, `"level":"debug"`, `"msg":"redis pipeline"`},
+		{`"addr":"localhost:6379"`, `"error":"connection refused"`, `"msg":"redis dial failed","network":"tcp"`},
+	} {
+		for _, s := range want {
+			if !strings.Contains(lines[i], s) {
+				t.Errorf("want %s in line %d: %s", s, i, lines[i])
+			}
+		}
+	}
+	if strings.Contains(string(buf.Bytes()), "secret") {
+		t.Errorf("want command arguments skipped, got %s", buf.Bytes())
+	}
+}
@@ -0,0 +1,85 @@
+// Package loggertest provides helpers comparing logs produced in tests against golden files
+package loggertest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite golden files with the produced logs")
+
+// Buffer is a concurrency-safe output collecting the written entries
+type Buffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *Buffer) Sync() error { return nil }
+
+// Bytes returns a copy of the written entries
+func (b *Buffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// New creates a logger writing logger.EncodingCanonical entries to the returned buffer only.
+// The Encoding, DisableStdOut, Files, Sinks and Outputs of the config are overridden.
+// The logger is shut down when the test finishes
+func New(t testing.TB, cfg logger.Config) (*logger.Logger, *Buffer) {
+	t.Helper()
+
+	buf := &Buffer{}
+	cfg.Encoding = logger.EncodingCanonical
+	cfg.DisableStdOut = true
+	cfg.Files = nil
+	cfg.Sinks = nil
+	cfg.Outputs = []zapcore.WriteSyncer{buf}
+
+	log, err := logger.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create logger: %s", err)
+	}
+	t.Cleanup(func() { _ = log.Shutdown(context.Background()) })
+	return log, buf
+}
+
+// Golden compares the logs with testdata/<name>.golden.
+// Running the tests with -update-golden rewrites the file instead
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create testdata: %s", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run the tests with -update-golden to create it: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("logs don't match %s, run the tests with -update-golden to accept them:\nwant:\n%s\ngot:\n%s", path, want, got)
+	}
+}
@@ -0,0 +1,23 @@
+package loggertest
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/kiteggrad/logger"
+)
+
+func TestGolden(t *testing.T) {
+	log, buf := New(t, logger.Config{})
+
+	log.Named("api").WithFields(map[string]interface{}{"user": "john", "attempt": 2}).Info("logged in")
+	log.WithError(errors.New("timeout")).ErrorFields("failed to fetch", zap.Any("request", map[string]interface{}{"z": 1, "a": "<b>"}))
+	log.WarnFields("retrying", zap.NamedError("cause", errors.Wrap(errors.New("eof"), "failed to read")), zap.String("modeVerbose", "full"), zap.String("mode", "slow"))
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	Golden(t, "golden", buf.Bytes())
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// StructObject returns a zapcore.ObjectMarshaler encoding the exported fields of a struct or a pointer to it
+// with the typed encoder methods instead of reflection-based JSON encoding of zap.Any, e.g.
+//
+//	log.WithField("user", logger.StructObject(user))
+//
+// See MarshalStruct for the field names
+func StructObject(v interface{}) zapcore.ObjectMarshaler {
+	return structObject{v: reflect.ValueOf(v)}
+}
+
+// MarshalStruct encodes the exported fields of a struct or a pointer to it, so a type becomes
+// a zapcore.ObjectMarshaler (which WithField and Event.Any use as is) with a one-line method:
+//
+//	func (u User) MarshalLogObject(enc zapcore.ObjectEncoder) error { return logger.MarshalStruct(enc, u) }
+//
+// Fields are named by the "log" tag or the "json" tag, falling back to the field name.
+// The "-" name skips a field, the "omitempty" option skips zero values.
+// Nested structs are encoded as objects, values of other kinds zap can't encode natively are reflected.
+// Pointer cycles are encoded as "<cycle>" and structs nested deeper than 32 levels as "<max depth>"
+func MarshalStruct(enc zapcore.ObjectEncoder, v interface{}) error {
+	return structObject{v: reflect.ValueOf(v)}.MarshalLogObject(enc)
+}
+
+// maxStructDepth is the maximum nesting of structs encoded by structObject
+const maxStructDepth = 32
+
+type structObject struct {
+	v reflect.Value
+	// path is the pointers to the enclosing structs, so pointer cycles aren't followed
+	path  []uintptr
+	depth int
+}
+
+func (o structObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	v := o.v
+	path := o.path
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		path = append(path[:len(path):len(path)], v.Pointer())
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() != reflect.Struct {
+		return errors.Errorf("%s isn't a struct", v.Type())
+	}
+
+	for _, f := range structFieldsOf(v.Type()) {
+		field := v.Field(f.index)
+		if f.omitEmpty && field.IsZero() {
+			continue
+		}
+		if err := encodeValue(enc, f.name, field, structObject{path: path, depth: o.depth + 1}); err != nil {
+			return errors.Wrapf(err, "failed to encode %s", f.name)
+		}
+	}
+	return nil
+}
+
+// encodeValue adds the value with the typed encoder method of its kind.
+// Nested is the structObject of the nested structs with the path and depth of the value
+func encodeValue(enc zapcore.ObjectEncoder, key string, v reflect.Value, nested structObject) error {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return enc.AddReflected(key, nil)
+	}
+	switch val := v.Interface().(type) {
+	case zapcore.ObjectMarshaler:
+		return enc.AddObject(key, val)
+	case zapcore.ArrayMarshaler:
+		return enc.AddArray(key, val)
+	case time.Time:
+		enc.AddTime(key, val)
+		return nil
+	case time.Duration:
+		enc.AddDuration(key, val)
+		return nil
+	case error:
+		enc.AddString(key, val.Error())
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		for _, p := range nested.path {
+			if p == v.Pointer() {
+				enc.AddString(key, "<cycle>")
+				return nil
+			}
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return encodeStruct(enc, key, v, nested)
+		}
+		return encodeValue(enc, key, v.Elem(), nested)
+	case reflect.Interface:
+		return encodeValue(enc, key, v.Elem(), nested)
+	case reflect.Bool:
+		enc.AddBool(key, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.AddInt64(key, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.AddUint64(key, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		enc.AddFloat64(key, v.Float())
+	case reflect.String:
+		enc.AddString(key, v.String())
+	case reflect.Struct:
+		return encodeStruct(enc, key, v, nested)
+	default:
+		return enc.AddReflected(key, v.Interface())
+	}
+	return nil
+}
+
+// encodeStruct adds the struct or pointer to it as an object unless it's nested too deep
+func encodeStruct(enc zapcore.ObjectEncoder, key string, v reflect.Value, nested structObject) error {
+	if nested.depth >= maxStructDepth {
+		enc.AddString(key, "<max depth>")
+		return nil
+	}
+	nested.v = v
+	return enc.AddObject(key, nested)
+}
+
+// structField is an encoded field of a struct type
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// structFields caches the encoded fields by struct type
+var structFields sync.Map // reflect.Type -> []structField
+
+func structFieldsOf(t reflect.Type) []structField {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("log")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, structField{index: i, name: name, omitEmpty: strings.Contains(","+options+",", ",omitempty,")})
+	}
+
+	structFields.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type marshalAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type marshalUser struct {
+	ID       int64          `log:"id"`
+	Name     string         `json:"name"`
+	Password string         `json:"-"`
+	Timeout  time.Duration  `json:"timeout"`
+	Address  marshalAddress `json:"address"`
+	Manager  *marshalUser   `json:"manager,omitempty"`
+	Tags     []string       `json:"tags"`
+	internal string
+}
+
+func (u marshalUser) MarshalLogObject(enc zapcore.ObjectEncoder) error { return MarshalStruct(enc, u) }
+
+func TestMarshalStruct(t *testing.T) {
+	buf := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, Encoding: EncodingJSON, Outputs: []zapcore.WriteSyncer{buf}})
+
+	user := marshalUser{
+		ID: 1, Name: "bob", Password: "secret", Timeout: time.Second,
+		Address: marshalAddress{City: "Paris"}, Tags: []string{"admin"}, internal: "x",
+	}
+	log.WithField("user", user).Info("marshaler")
+	log.WithField("user", StructObject(&user)).Info("struct object")
+
+	want := `"user":{"id":1,"name":"bob","timeout":"1s","address":{"city":"Paris"},"tags":["admin"]}`
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output: %s", buf.Bytes())
+	}
+	for _, line := range lines {
+		if !bytes.Contains(line, []byte(want)) {
+			t.Errorf("want %s, got %s", want, line)
+		}
+	}
+}
+
+func TestMarshalStructNotStruct(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	if err := MarshalStruct(enc, 42); err == nil {
+		t.Error("want an error for a non-struct value")
+	}
+	if err := MarshalStruct(enc, (*marshalUser)(nil)); err != nil || len(enc.Fields) != 0 {
+		t.Errorf("want nil pointers skipped, got %v %v", enc.Fields, err)
+	}
+	if err := StructObject(nil).MarshalLogObject(enc); err != nil || len(enc.Fields) != 0 {
+		t.Errorf("want nil skipped, got %v %v", enc.Fields, err)
+	}
+}
+
+type marshalNode struct {
+	Name string       `json:"name"`
+	Next *marshalNode `json:"next,omitempty"`
+}
+
+func TestMarshalStructCycles(t *testing.T) {
+	first := &marshalNode{Name: "first"}
+	first.Next = &marshalNode{Name: "second", Next: first}
+	enc := zapcore.NewMapObjectEncoder()
+	if err := MarshalStruct(enc, first); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := enc.Fields["next"].(map[string]interface{})
+	if second["name"] != "second" || second["next"] != "<cycle>" {
+		t.Errorf("want the cycle cut, got %v", enc.Fields)
+	}
+
+	// Shared pointers out of the path aren't cycles
+	shared := &marshalAddress{City: "Paris"}
+	enc = zapcore.NewMapObjectEncoder()
+	if err := MarshalStruct(enc, struct{ Home, Work *marshalAddress }{shared, shared}); err != nil {
+		t.Fatal(err)
+	}
+	if work, _ := enc.Fields["Work"].(map[string]interface{}); work["city"] != "Paris" {
+		t.Errorf("want the shared pointer encoded twice, got %v", enc.Fields)
+	}
+
+	deep := &marshalNode{Name: "0"}
+	for i, node := 0, deep; i < 2*maxStructDepth; i, node = i+1, node.Next {
+		node.Next = &marshalNode{Name: "next"}
+	}
+	enc = zapcore.NewMapObjectEncoder()
+	if err := MarshalStruct(enc, deep); err != nil {
+		t.Fatal(err)
+	}
+	level, depth := enc.Fields, 0
+	for next, ok := level["next"].(map[string]interface{}); ok; next, ok = level["next"].(map[string]interface{}) {
+		level, depth = next, depth+1
+	}
+	if depth != maxStructDepth-1 || level["next"] != "<max depth>" {
+		t.Errorf("want %d levels, got %d ending with %v", maxStructDepth-1, depth, level["next"])
+	}
+}
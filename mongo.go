@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MongoLogSink adapts a logger to the options.LogSink interface of the MongoDB driver:
+//
+//	sink := &logger.MongoLogSink{Log: log.Named("mongo"), SlowCommand: 100 * time.Millisecond}
+//	opts := options.Client().SetLoggerOptions(options.Logger().SetSink(sink).
+//		SetComponentLevel(options.LogComponentCommand, options.LogLevelDebug))
+//
+// Driver info messages are logged at Info level, debug ones (commands, connection events) at Debug level.
+// Keys and values of the messages become fields
+type MongoLogSink struct {
+	Log *Logger
+	// SlowCommand makes "Command succeeded" messages of commands running at least as long logged at Warn level.
+	// Zero disables it
+	SlowCommand time.Duration
+}
+
+// mongoLevelInfo is the level of driver info messages. The driver subtracts logger.DiffToInfo
+// from options.LogLevelInfo, so info messages have level 0 and debug ones level 1
+const mongoLevelInfo = 0
+
+// Info logs a driver message of the level
+func (s *MongoLogSink) Info(level int, message string, keysAndValues ...interface{}) {
+	lvl := DebugLevel
+	if level <= mongoLevelInfo {
+		lvl = InfoLevel
+	}
+	if s.SlowCommand > 0 && message == "Command succeeded" && s.slow(keysAndValues) {
+		lvl = WarnLevel
+	}
+	s.write(lvl, message, nil, keysAndValues)
+}
+
+// Error logs a driver error
+func (s *MongoLogSink) Error(err error, message string, keysAndValues ...interface{}) {
+	s.write(ErrorLevel, message, err, keysAndValues)
+}
+
+func (s *MongoLogSink) write(lvl zapcore.Level, message string, err error, keysAndValues []interface{}) {
+	ce := s.Log.base().WithOptions(zap.WithCaller(false)).Check(lvl, message)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(keysAndValues)/2+1)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok {
+			fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		}
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(fields...)
+}
+
+// slow reports whether the durationMS value is over the threshold
+func (s *MongoLogSink) slow(keysAndValues []interface{}) bool {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i] != "durationMS" {
+			continue
+		}
+		var ms float64
+		switch v := keysAndValues[i+1].(type) {
+		case int64:
+			ms = float64(v)
+		case int:
+			ms = float64(v)
+		case float64:
+			ms = v
+		default:
+			return false
+		}
+		return time.Duration(ms*float64(time.Millisecond)) >= s.SlowCommand
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// mongoLogSink is the options.LogSink interface of the MongoDB driver
+type mongoLogSink interface {
+	Info(level int, message string, keysAndValues ...interface{})
+	Error(err error, message string, keysAndValues ...interface{})
+}
+
+var _ mongoLogSink = (*MongoLogSink)(nil)
+
+func TestMongoLogSink(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	sink := &MongoLogSink{Log: log, SlowCommand: 100 * time.Millisecond}
+	// The driver passes info messages at level 0 and debug ones at level 1
+	sink.Info(1, "Command succeeded", "commandName", "find", "durationMS", int64(5))
+	sink.Info(1, "Command succeeded", "commandName", "aggregate", "durationMS", int64(250))
+	sink.Info(0, "Connection pool created", "serverHost", "localhost")
+	sink.Error(errors.New("connection refused"), "Server heartbeat failed")
+
+	want := []zapcore.Level{DebugLevel, WarnLevel, InfoLevel, ErrorLevel}
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %d", len(want), len(entries))
+	}
+	for i, e := range entries {
+		if e.Level != want[i] {
+			t.Errorf("%s: want %s, got %s", e.Message, want[i], e.Level)
+		}
+	}
+	if fields := entries[1].ContextMap(); fields["commandName"] != "aggregate" || fields["durationMS"] != int64(250) {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if fields := entries[3].ContextMap(); fields["error"] != "connection refused" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// MQTTPublishFunc publishes a message to an MQTT broker. Adapt an existing client, e.g. paho.mqtt.golang:
+//
+//	func(topic string, qos byte, retained bool, payload []byte) error {
+//		token := client.Publish(topic, qos, retained, payload)
+//		token.Wait()
+//		return token.Error()
+//	}
+type MQTTPublishFunc func(topic string, qos byte, retained bool, payload []byte) error
+
+type MQTTConfig struct {
+	// Topic is a topic template. "{key}" placeholders are replaced with entry field values,
+	// "{level}" and "{logger}" with the entry level and logger name, e.g. "logs/{service}/{level}".
+	// MQTT special characters in values are replaced with "_"
+	Topic string
+	// QoS is the MQTT quality of service level: 0, 1 or 2
+	QoS byte
+	// Retained makes the broker keep the last entry for new subscribers
+	Retained bool
+	// Level is the minimum level of published entries. All entries are published if it's nil
+	Level zapcore.LevelEnabler
+}
+
+var mqttTopicReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// NewMQTTCore creates a core publishing JSON-encoded entries to an MQTT topic,
+// so edge devices can forward logs over their existing broker connection. Add it with Config.Cores
+func NewMQTTCore(publish MQTTPublishFunc, cfg MQTTConfig) zapcore.Core {
+	return newPublishCore(cfg.Level, func(e publishedEntry) error {
+		topic := renderTemplate(cfg.Topic, e, mqttTopicReplacer.Replace)
+		return publish(topic, cfg.QoS, cfg.Retained, e.Payload)
+	})
+}
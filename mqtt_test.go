@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMQTTCore(t *testing.T) {
+	type message struct {
+		topic    string
+		qos      byte
+		retained bool
+		payload  map[string]interface{}
+	}
+
+	var (
+		mu       sync.Mutex
+		messages []message
+	)
+	publish := func(topic string, qos byte, retained bool, payload []byte) error {
+		m := message{topic: topic, qos: qos, retained: retained}
+		if err := json.Unmarshal(payload, &m.payload); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, m)
+		return nil
+	}
+
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Cores: []zapcore.Core{NewMQTTCore(publish, MQTTConfig{
+			Topic: "logs/{service}/{level}",
+			QoS:   1,
+			Level: zap.InfoLevel,
+		})},
+	})
+
+	log.Debug("skipped")
+	log.WithField("service", "pump/1").Warn("pressure")
+	log.InfoFields("started")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 2 {
+		t.Fatalf("want 2 messages, got %d", len(messages))
+	}
+	if m := messages[0]; m.topic != "logs/pump_1/warn" || m.qos != 1 || m.payload["msg"] != "pressure" || m.payload["service"] != "pump/1" {
+		t.Errorf("unexpected message: %+v", m)
+	}
+	if m := messages[1]; m.topic != "logs/_/info" {
+		t.Errorf("unexpected topic: %s", m.topic)
+	}
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultNATSMaxPending is the pending acks limit used if NATSConfig.MaxPending isn't set
+const defaultNATSMaxPending = 256
+
+// NATSPublishFunc publishes data to a NATS subject. For asynchronous publishing it returns
+// a channel receiving the ack result, a nil channel means the message is already acknowledged.
+// Adapt an existing nats.go connection:
+//
+//	// core NATS
+//	func(subj string, data []byte) (<-chan error, error) { return nil, nc.Publish(subj, data) }
+//
+//	// JetStream
+//	func(subj string, data []byte) (<-chan error, error) {
+//		future, err := js.PublishAsync(subj, data)
+//		if err != nil {
+//			return nil, err
+//		}
+//		ack := make(chan error, 1)
+//		go func() {
+//			select {
+//			case <-future.Ok():
+//				ack <- nil
+//			case err := <-future.Err():
+//				ack <- err
+//			}
+//		}()
+//		return ack, nil
+//	}
+type NATSPublishFunc func(subject string, data []byte) (ack <-chan error, err error)
+
+type NATSConfig struct {
+	// Subject is a subject template. "{key}" placeholders are replaced with entry field values,
+	// "{level}" and "{logger}" with the entry level and logger name, e.g. "logs.{service}.{level}".
+	// NATS special characters in values are replaced with "_"
+	Subject string
+	// MaxPending limits the number of unacknowledged asynchronous publishes.
+	// Writes wait for acks when the limit is reached. Defaults to 256
+	MaxPending int
+	// Level is the minimum level of published entries. All entries are published if it's nil
+	Level zapcore.LevelEnabler
+}
+
+var natsSubjectReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_")
+
+// natsAcks tracks pending asynchronous acks
+type natsAcks struct {
+	max int
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signaled when an ack is received
+	pending int
+	err     error
+}
+
+func newNATSAcks(max int) *natsAcks {
+	a := &natsAcks{max: max}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// acquire waits until there are less than max pending acks and adds one
+func (a *natsAcks) acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.pending >= a.max {
+		a.cond.Wait()
+	}
+	a.pending++
+}
+
+// release removes a pending ack, recording its error
+func (a *natsAcks) release(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending--
+	a.err = multierr.Append(a.err, err)
+	a.cond.Broadcast()
+}
+
+// NewNATSCore creates a core publishing JSON-encoded entries to a NATS subject or JetStream stream.
+// Failed acks are reported by the next Sync, which also waits for all the pending acks. Add it with Config.Cores
+func NewNATSCore(publish NATSPublishFunc, cfg NATSConfig) zapcore.Core {
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = defaultNATSMaxPending
+	}
+	acks := newNATSAcks(cfg.MaxPending)
+
+	c := newPublishCore(cfg.Level, func(e publishedEntry) error {
+		subject := renderTemplate(cfg.Subject, e, natsSubjectReplacer.Replace)
+
+		acks.acquire()
+		ack, err := publish(subject, e.Payload)
+		if err != nil || ack == nil {
+			acks.release(nil)
+			return err
+		}
+
+		go func() { acks.release(<-ack) }()
+		return nil
+	})
+	c.sync = acks.wait
+	return c
+}
+
+// wait waits for all the pending acks and returns their errors. Concurrent waits are safe
+func (a *natsAcks) wait() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.pending > 0 {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	return err
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNATSCore(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		subjects []string
+		acks     []chan error
+	)
+	publish := func(subject string, data []byte) (<-chan error, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		subjects = append(subjects, subject)
+		ack := make(chan error, 1)
+		acks = append(acks, ack)
+		return ack, nil
+	}
+
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Cores:         []zapcore.Core{NewNATSCore(publish, NATSConfig{Subject: "logs.{service}.{level}", MaxPending: 2})},
+	})
+
+	log.WithField("service", "api.v1").Info("1")
+	log.Info("2")
+
+	mu.Lock()
+	acks[0] <- nil
+	acks[1] <- errors.New("no responders")
+	mu.Unlock()
+
+	if err := log.Sync(); err == nil {
+		t.Error("want ack error on Sync, got nil")
+	}
+	if want := []string{"logs.api_v1.info", "logs._.info"}; subjects[0] != want[0] || subjects[1] != want[1] {
+		t.Errorf("want %v subjects, got %v", want, subjects)
+	}
+}
+
+func TestNATSAcksConcurrentWait(t *testing.T) {
+	acks := newNATSAcks(2)
+	acks.acquire()
+	acks.acquire()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = acks.wait()
+		}()
+	}
+	acks.release(nil)
+	acks.release(errors.New("no responders"))
+	wg.Wait()
+}
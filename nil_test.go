@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilLogger(t *testing.T) {
+	var log *Logger
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+
+	v := reflect.ValueOf(log)
+	for i := 0; i < v.NumMethod(); i++ {
+		method := v.Type().Method(i)
+		if strings.HasPrefix(method.Name, "Fatal") || strings.HasPrefix(method.Name, "Panic") {
+			continue
+		}
+		args := make([]reflect.Value, method.Type.NumIn()-1)
+		for j := range args {
+			typ := method.Type.In(j + 1)
+			switch {
+			case typ == ctxType:
+				args[j] = reflect.ValueOf(context.Background())
+			case typ == reflect.TypeOf(time.Duration(0)):
+				args[j] = reflect.ValueOf(time.Hour)
+			case method.Type.IsVariadic() && j == len(args)-1:
+				args[j] = reflect.MakeSlice(typ, 0, 0)
+			default:
+				args[j] = reflect.Zero(typ)
+			}
+		}
+		t.Run(method.Name, func(t *testing.T) {
+			call := v.Method(i).Call
+			if method.Type.IsVariadic() {
+				call = v.Method(i).CallSlice
+			}
+			for _, out := range call(args) {
+				if stop, ok := out.Interface().(func() error); ok && stop != nil {
+					_ = stop()
+				}
+			}
+		})
+	}
+}
+
+func TestStderrFallback(t *testing.T) {
+	t.Cleanup(func() { stderrFallback.Store(false) })
+
+	if L().base().Core().Enabled(InfoLevel) {
+		t.Fatal("want the noop logger by default")
+	}
+	UseStderrFallback()
+	if core := L().base().Core(); !core.Enabled(InfoLevel) || core.Enabled(DebugLevel) {
+		t.Error("want the stderr logger at Info level")
+	}
+	if L() != L() {
+		t.Error("want the stderr logger built once")
+	}
+
+	log := newLogger(t, Config{DisableStdOut: true})
+	SetGlobal(log)
+	defer SetGlobal(nil)
+	if L() != log {
+		t.Error("want the set logger")
+	}
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// NotifyService is a notification service defining the payload format
+type NotifyService string
+
+const (
+	// NotifySlack posts {"text": ...} to a Slack incoming webhook
+	NotifySlack NotifyService = "slack"
+	// NotifyTeams posts {"text": ...} to an MS Teams incoming webhook
+	NotifyTeams NotifyService = "teams"
+	// NotifyPagerDuty triggers a PagerDuty Events API v2 event with the entry fields as custom details
+	NotifyPagerDuty NotifyService = "pagerduty"
+)
+
+// NotifyConfig configures NewNotifyCore and NewAlertNotifier
+type NotifyConfig struct {
+	// Service defines the payload format
+	Service NotifyService
+	// URL is the webhook URL. Defaults to the Events API endpoint for PagerDuty
+	URL string
+	// RoutingKey is the PagerDuty integration key
+	RoutingKey string
+	// Template is the notification text. "{key}" placeholders are replaced with entry field values,
+	// "{level}", "{logger}" and "{msg}" with the entry level, logger name and message.
+	// Defaults to "[{level}] {msg}"
+	Template string
+	// Level is the minimum level of notified entries. Defaults to Panic, so Panic and Fatal entries are notified
+	Level zapcore.LevelEnabler
+	// RateLimit is the minimum interval between notifications. Entries within it are counted
+	// and the count is added to the next notification. Defaults to 1m, negative disables limiting
+	RateLimit time.Duration
+	// Transport configures the HTTP client
+	Transport SinkTransportConfig
+}
+
+func (c NotifyConfig) template() string {
+	if c.Template == "" {
+		return "[{level}] {msg}"
+	}
+	return c.Template
+}
+
+func (c NotifyConfig) rateLimit() time.Duration {
+	if c.RateLimit == 0 {
+		return time.Minute
+	}
+	return c.RateLimit
+}
+
+// notifier sends notifications to a webhook with rate limiting
+type notifier struct {
+	cfg    NotifyConfig
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.Mutex
+	lastSent   time.Time
+	suppressed int
+}
+
+// NewNotifyCore creates a core sending alerts to Slack, MS Teams or PagerDuty. Add it with Config.Cores.
+// Notifications are sent synchronously, so Fatal entries are delivered before the program exits
+func NewNotifyCore(cfg NotifyConfig) (zapcore.Core, error) {
+	n, err := newNotifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	level := cfg.Level
+	if level == nil {
+		level = zapcore.PanicLevel
+	}
+	return newPublishCore(level, n.notify), nil
+}
+
+func newNotifier(cfg NotifyConfig) (*notifier, error) {
+	n := &notifier{cfg: cfg, url: cfg.URL, now: time.Now}
+	switch cfg.Service {
+	case NotifySlack, NotifyTeams:
+		if cfg.URL == "" {
+			return nil, errors.Errorf("%s notifications require URL", cfg.Service)
+		}
+	case NotifyPagerDuty:
+		if cfg.RoutingKey == "" {
+			return nil, errors.New("pagerduty notifications require RoutingKey")
+		}
+		if n.url == "" {
+			n.url = pagerDutyEventsURL
+		}
+	default:
+		return nil, errors.Errorf("unknown notification service %q", cfg.Service)
+	}
+
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	n.client = client
+	return n, nil
+}
+
+func (n *notifier) notify(e publishedEntry) error {
+	suppressed, ok := n.allow()
+	if !ok {
+		return nil
+	}
+
+	text := renderTemplate(n.cfg.template(), e, func(s string) string { return s })
+	if suppressed > 0 {
+		text += fmt.Sprintf(" (%d more notifications suppressed)", suppressed)
+	}
+
+	payload, err := json.Marshal(n.payload(text, e))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+	return n.send(payload)
+}
+
+// allow reports whether a notification can be sent and how many were suppressed since the last one
+func (n *notifier) allow() (suppressed int, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+	if limit := n.cfg.rateLimit(); limit > 0 && !n.lastSent.IsZero() && now.Sub(n.lastSent) < limit {
+		n.suppressed++
+		return 0, false
+	}
+	suppressed, n.suppressed = n.suppressed, 0
+	n.lastSent = now
+	return suppressed, true
+}
+
+func (n *notifier) payload(text string, e publishedEntry) interface{} {
+	if n.cfg.Service != NotifyPagerDuty {
+		return map[string]interface{}{"text": text}
+	}
+
+	// PagerDuty limits the summary to 1024 characters
+	if len(text) > 1024 {
+		text = text[:1021] + "..."
+	}
+	source := e.Entry.LoggerName
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+	return map[string]interface{}{
+		"routing_key":  n.cfg.RoutingKey,
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":        text,
+			"source":         source,
+			"severity":       pagerDutySeverity(e.Entry.Level),
+			"timestamp":      e.Entry.Time.Format(time.RFC3339Nano),
+			"custom_details": e.Fields,
+		},
+	}
+}
+
+func pagerDutySeverity(lvl zapcore.Level) string {
+	switch {
+	case lvl >= zapcore.DPanicLevel:
+		return "critical"
+	case lvl == zapcore.ErrorLevel:
+		return "error"
+	case lvl == zapcore.WarnLevel:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func (n *notifier) send(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to http.NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	n.cfg.Transport.SetHeaders(req)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send notification")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("unexpected notification response status %s", resp.Status)
+	}
+	return nil
+}
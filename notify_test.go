@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNotifyCore(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	slack, err := newNotifier(NotifyConfig{Service: NotifySlack, URL: server.URL, Template: "{service} {level}: {msg}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	slack.now = func() time.Time { return now }
+	pagerDuty, err := NewNotifyCore(NotifyConfig{Service: NotifyPagerDuty, URL: server.URL, RoutingKey: "key", Level: zap.ErrorLevel, RateLimit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{newPublishCore(zap.WarnLevel, slack.notify), pagerDuty}})
+	log = log.WithField("service", "billing")
+	log.Info("skipped")
+	log.Warn("slow 1")
+	log.Warn("slow 2")
+	now = now.Add(time.Minute)
+	log.Error("failed")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 3 {
+		t.Fatalf("want 3 notifications, got %v", payloads)
+	}
+	if text := payloads[0]["text"]; text != "billing warn: slow 1" {
+		t.Errorf("unexpected text: %v", text)
+	}
+	if text := payloads[1]["text"]; text != "billing error: failed (1 more notifications suppressed)" {
+		t.Errorf("unexpected text: %v", text)
+	}
+	event := payloads[2]
+	details := event["payload"].(map[string]interface{})
+	if event["routing_key"] != "key" || event["event_action"] != "trigger" || details["severity"] != "error" ||
+		details["summary"] != "[error] failed" || details["custom_details"].(map[string]interface{})["service"] != "billing" {
+		t.Errorf("unexpected event: %v", event)
+	}
+}
+
+func TestNotifyConfigErrors(t *testing.T) {
+	for _, cfg := range []NotifyConfig{{Service: "sms"}, {Service: NotifySlack}, {Service: NotifyPagerDuty}} {
+		if _, err := NewNotifyCore(cfg); err == nil {
+			t.Errorf("%+v: want an error", cfg)
+		}
+	}
+}
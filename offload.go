@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OffloadConfig configures offloading of large field values, keeping log lines small while preserving full payloads.
+// An offloaded value is replaced by a reference: {"offloaded": "<ref>", "sha256": "<hash>", "size": <bytes>}
+type OffloadConfig struct {
+	// Threshold is the size in bytes of string, binary and reflected (JSON-encoded) values to offload.
+	// Zero disables offloading
+	Threshold int
+	// Dir is the directory payloads are written to, named by their hashes. The reference is the file path
+	Dir string
+	// Store stores payloads instead of Dir, e.g. in an object store
+	Store PayloadStore
+}
+
+// PayloadStore stores offloaded payloads, see OffloadConfig
+type PayloadStore interface {
+	// Put stores the payload with the hex-encoded SHA-256 hash and returns a reference to it, e.g. a URL
+	Put(hash string, payload []byte) (ref string, err error)
+}
+
+func (cfg OffloadConfig) store() (PayloadStore, error) {
+	if cfg.Store != nil {
+		return cfg.Store, nil
+	}
+	if cfg.Dir == "" {
+		return nil, errors.New("offloading requires Dir or Store")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create offload directory")
+	}
+	return dirPayloadStore(cfg.Dir), nil
+}
+
+// dirPayloadStore writes payloads to files named by their hashes, so repeated payloads are stored once
+type dirPayloadStore string
+
+func (d dirPayloadStore) Put(hash string, payload []byte) (string, error) {
+	path := filepath.Join(string(d), hash)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	// Write to a temporary file first, so a reader never sees a partial payload.
+	// Every writer has its own file, so concurrent writers of the same payload don't interleave
+	tmp, err := os.CreateTemp(string(d), hash+".*.tmp")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create payload file")
+	}
+	defer os.Remove(tmp.Name()) // fails after the rename
+	_, err = tmp.Write(payload)
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write payload")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", errors.Wrap(err, "failed to rename payload")
+	}
+	return path, nil
+}
+
+// payloadRef is the reference replacing an offloaded value
+type payloadRef struct {
+	ref  string
+	hash string
+	size int
+}
+
+func (r payloadRef) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("offloaded", r.ref)
+	enc.AddString("sha256", r.hash)
+	enc.AddInt("size", r.size)
+	return nil
+}
+
+// offloadCore replaces large field values with references to the stored payloads.
+// Store errors are reported to the error output and the values are kept
+type offloadCore struct {
+	core      zapcore.Core
+	threshold int
+	store     PayloadStore
+}
+
+func (c *offloadCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *offloadCore) With(fields []zapcore.Field) zapcore.Core {
+	// With can't report errors, the values are kept on failures
+	offloaded, _ := c.offload(fields)
+	clone := *c
+	clone.core = c.core.With(offloaded)
+	return &clone
+}
+
+func (c *offloadCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *offloadCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	offloaded, err := c.offload(fields)
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(offloaded...)
+	}
+	return err
+}
+
+func (c *offloadCore) Sync() error {
+	return c.core.Sync()
+}
+
+// offload returns the fields with large values replaced, copying them only if needed
+func (c *offloadCore) offload(fields []zapcore.Field) ([]zapcore.Field, error) {
+	var errs error
+	result, copied := fields, false
+	for i, f := range fields {
+		payload := c.payload(f)
+		if payload == nil {
+			continue
+		}
+		sum := sha256.Sum256(payload)
+		hash := hex.EncodeToString(sum[:])
+		ref, err := c.store.Put(hash, payload)
+		if err != nil {
+			errs = multierr.Append(errs, errors.Wrapf(err, "failed to offload %q", f.Key))
+			continue
+		}
+		if !copied {
+			result, copied = append([]zapcore.Field(nil), fields...), true
+		}
+		result[i] = zap.Object(f.Key, payloadRef{ref: ref, hash: hash, size: len(payload)})
+	}
+	return result, errs
+}
+
+// payload returns the encoded value of the field if it's over the threshold
+func (c *offloadCore) payload(f zapcore.Field) []byte {
+	switch f.Type {
+	case zapcore.StringType:
+		if len(f.String) > c.threshold {
+			return []byte(f.String)
+		}
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if b, _ := f.Interface.([]byte); len(b) > c.threshold {
+			return b
+		}
+	case zapcore.ReflectType:
+		if b, err := json.Marshal(f.Interface); err == nil && len(b) > c.threshold {
+			return b
+		}
+	}
+	return nil
+}
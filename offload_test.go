@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type failingPayloadStore struct{}
+
+func (failingPayloadStore) Put(string, []byte) (string, error) {
+	return "", errors.New("bucket is gone")
+}
+
+func TestOffload(t *testing.T) {
+	dir := t.TempDir()
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Offload: OffloadConfig{Threshold: 16, Dir: dir}})
+
+	body := strings.Repeat("x", 100)
+	log.WithField("request", map[string]string{"body": body}).InfoFields("call",
+		zap.String("response", body),
+		zap.String("status", "ok"),
+		zap.Binary("frame", bytes.Repeat([]byte{1}, 32)),
+	)
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["status"] != "ok" {
+		t.Errorf("want short values kept, got %v", fields["status"])
+	}
+	for _, key := range []string{"request", "response", "frame"} {
+		ref, ok := fields[key].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: want a reference, got %v", key, fields[key])
+			continue
+		}
+		payload := readFile(t, ref["offloaded"].(string))
+		sum := sha256.Sum256(payload)
+		if ref["sha256"] != hex.EncodeToString(sum[:]) || ref["size"] != len(payload) {
+			t.Errorf("%s: unexpected reference %v", key, ref)
+		}
+	}
+	if got := string(readFile(t, fields["response"].(map[string]interface{})["offloaded"].(string))); got != body {
+		t.Errorf("unexpected payload: %s", got)
+	}
+}
+
+func TestOffloadStoreError(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Offload: OffloadConfig{Threshold: 4, Store: failingPayloadStore{}}})
+
+	log.InfoFields("call", zap.String("response", "too long"))
+	if got := log.ObservedLogs().AllUntimed()[0].ContextMap()["response"]; got != "too long" {
+		t.Errorf("want the value kept, got %v", got)
+	}
+
+	if _, err := New(Config{DisableStdOut: true, Offload: OffloadConfig{Threshold: 4}}); err == nil {
+		t.Error("want an error without Dir and Store")
+	}
+}
+
+func TestDirPayloadStoreConcurrentPuts(t *testing.T) {
+	dir := t.TempDir()
+	payload := bytes.Repeat([]byte("x"), 1<<16)
+	sum := sha256.Sum256(payload)
+	hash := hex.EncodeToString(sum[:])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := dirPayloadStore(dir).Put(hash, payload); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := readFile(t, filepath.Join(dir, hash)); !bytes.Equal(got, payload) {
+		t.Errorf("want the payload stored, got %d bytes", len(got))
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmp) != 0 {
+		t.Errorf("want no temporary files, got %v", tmp)
+	}
+}
@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// outputs holds the resources opened by New. It's shared between a logger and its clones
+type outputs struct {
+	files      []*fileWriter
+	closers    []func() error
+	observed   *observer.ObservedLogs
+	deadLetter *deadLetterFile
+	// loggerSinks is Config.LoggerSinks, see Registry
+	loggerSinks map[string][]string
+	// spans is Config.SpanRecorder, so Logger.Ctx adds the context to loggers. Nil if it isn't set
+	spans SpanRecorder
+	// dynamic are the sinks added with Logger.AddSink
+	dynamic *dynamicSinks
+	// drops counts the entries dropped by the non-blocking ConcurrentOutputs queues and Async, see AsyncConfig.NonBlocking
+	drops *dropCounter
+
+	closed       atomic.Bool
+	shutdownOnce sync.Once
+	shutdownDone chan struct{}
+	shutdownErr  error
+}
+
+// namedOutput is an opened output with the name used to target it, see Logger.To.
+// Unnamed outputs only receive untargeted entries
+type namedOutput struct {
+	name      string
+	ws        zapcore.WriteSyncer
+	exclusive bool
+	enc       zapcore.Encoder // nil for the encoder of Config.Encoding
+	drops     *dropCounter    // counts the entries dropped by a non-blocking ConcurrentOutputs queue
+}
+
+// open opens all the outputs from the config
+func (o *outputs) open(cfg Config) ([]namedOutput, error) {
+	fileOpts, err := cfg.fileOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := cfg.Files
+	if !cfg.DisableStdOut {
+		paths = append([]string{"stdout"}, paths...)
+	}
+
+	opened := make([]namedOutput, 0, len(paths)+len(cfg.Outputs))
+	for _, path := range paths {
+		ws, err := o.openPath(path, fileOpts)
+		if err != nil {
+			return nil, err
+		}
+		opened = append(opened, namedOutput{name: path, ws: ws})
+	}
+	for _, name := range sortedKeys(cfg.Sinks) {
+		ws, err := o.openSink(name, cfg.Sinks[name], fileOpts)
+		if err != nil {
+			return nil, err
+		}
+		enc, err := cfg.sinkEncoder(name, cfg.Sinks[name])
+		if err != nil {
+			return nil, err
+		}
+		opened = append(opened, namedOutput{name: name, ws: ws, exclusive: cfg.Sinks[name].Exclusive, enc: enc})
+	}
+	for _, ws := range cfg.Outputs {
+		if closer, ok := ws.(io.Closer); ok {
+			o.closers = append(o.closers, closer.Close)
+		}
+		opened = append(opened, namedOutput{ws: ws})
+	}
+
+	if cfg.ConcurrentOutputs && cfg.Async.NonBlocking {
+		o.drops = &dropCounter{}
+	}
+	outputIndex := 0
+	for i := range opened {
+		if cfg.ConcurrentOutputs {
+			fanOut := newFanOutWriter(cfg.OutputQueueSize, cfg.Async.NonBlocking, opened[i].ws)
+			o.closers = append(o.closers, fanOut.Close)
+			opened[i].ws = fanOut
+			opened[i].drops = o.drops
+		}
+
+		// Unnamed outputs can't be targeted, so they're named by index for reporting only
+		name := opened[i].name
+		if name == "" {
+			name = "outputs[" + strconv.Itoa(outputIndex) + "]"
+			outputIndex++
+		}
+		opened[i].ws = &batchWriter{WriteSyncer: reportingSyncer{WriteSyncer: opened[i].ws, name: name}, name: name}
+	}
+	return opened, nil
+}
+
+// sinkEncoder returns the encoder of the sink if it overrides the encoding or colors, nil otherwise
+func (cfg Config) sinkEncoder(name string, sink SinkConfig) (zapcore.Encoder, error) {
+	if sink.Encoding == "" && !sink.DisableColor {
+		return nil, nil
+	}
+	if !sink.Encoding.valid() {
+		return nil, errors.Errorf("unknown encoding %q of sink %q", sink.Encoding, name)
+	}
+	if sink.Encoding != "" {
+		cfg.Encoding = sink.Encoding
+	}
+	cfg.DisableColor = cfg.DisableColor || sink.DisableColor
+	return newEncoder(cfg), nil
+}
+
+func (o *outputs) openSink(name string, sink SinkConfig, opts fileOptions) (zapcore.WriteSyncer, error) {
+	if (sink.Path == "") == (sink.Output == nil) {
+		return nil, errors.Errorf("sink %q must have either Path or Output", name)
+	}
+	if sink.Output != nil {
+		if closer, ok := sink.Output.(io.Closer); ok {
+			o.closers = append(o.closers, closer.Close)
+		}
+		return sink.Output, nil
+	}
+
+	if sink.Rotation != RotateNever {
+		if !sink.Rotation.valid() {
+			return nil, errors.Errorf("unknown rotation period %q of sink %q", sink.Rotation, name)
+		}
+		opts.rotation = sink.Rotation
+	}
+	return o.openPath(sink.Path, opts)
+}
+
+func (o *outputs) openPath(path string, opts fileOptions) (zapcore.WriteSyncer, error) {
+	if strings.HasPrefix(path, fdScheme) {
+		file, ws, err := openFD(path)
+		if err != nil {
+			return nil, err
+		}
+		o.closers = append(o.closers, file.Close)
+		return ws, nil
+	}
+	if !isPlainPath(path) {
+		sink, closeSink, err := zap.Open(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to zap.Open %s", path)
+		}
+		o.closers = append(o.closers, func() error { closeSink(); return nil })
+		return sink, nil
+	}
+
+	if opts.expandTokens {
+		expanded, err := expandPathTokens(path)
+		if err != nil {
+			return nil, err
+		}
+		path = expanded
+	}
+	file, err := openFileWriter(path, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to openFileWriter %s", path)
+	}
+	o.files = append(o.files, file)
+	o.closers = append(o.closers, file.Close)
+	if opts.sync == FileSyncPeriodic {
+		interval := opts.syncInterval
+		if interval <= 0 {
+			interval = defaultFileSyncInterval
+		}
+		o.closers = append(o.closers, runEvery(interval, func() {
+			if err := file.Sync(); err != nil {
+				fmt.Fprintf(stderr, "%v failed to fsync %s: %v\n", time.Now().UTC(), file.path, err)
+			}
+		}))
+	}
+	return file, nil
+}
+
+// rotate rotates all opened files
+func (o *outputs) rotate() (err error) {
+	for _, file := range o.files {
+		err = multierr.Append(err, errors.Wrapf(file.Rotate(), "failed to rotate %s", file.path))
+	}
+	if o.dynamic != nil {
+		err = multierr.Append(err, o.dynamic.rotate())
+	}
+	return err
+}
+
+// shutdown stops accepting new entries, flushes and closes the outputs
+func (o *outputs) shutdown(ctx context.Context, core zapcore.Core) error {
+	o.shutdownOnce.Do(func() {
+		o.closed.Store(true)
+		o.shutdownDone = make(chan struct{})
+		go func() {
+			defer close(o.shutdownDone)
+			o.shutdownErr = multierr.Append(
+				newSyncError(core.Sync()),
+				errors.Wrap(o.close(), "failed to close"),
+			)
+		}()
+	})
+
+	select {
+	case <-o.shutdownDone:
+		return o.shutdownErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close releases the outputs in reverse order of opening
+func (o *outputs) close() (err error) {
+	for i := len(o.closers) - 1; i >= 0; i-- {
+		err = multierr.Append(err, o.closers[i]())
+	}
+	return err
+}
+
+// isPlainPath reports whether the path isn't a zap URL like "stdout" or "scheme://..."
+func isPlainPath(path string) bool {
+	return path != "stdout" && path != "stderr" && !strings.Contains(path, "://")
+}
+
+// gateCore drops all the entries once the outputs are closed
+type gateCore struct {
+	zapcore.Core
+	out *outputs
+}
+
+func (c *gateCore) Enabled(lvl zapcore.Level) bool {
+	return !c.out.closed.Load() && c.Core.Enabled(lvl)
+}
+
+func (c *gateCore) With(fields []zapcore.Field) zapcore.Core {
+	return &gateCore{Core: c.Core.With(fields), out: c.out}
+}
+
+func (c *gateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.out.closed.Load() {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// lookupOwner resolves a "user[:group]" owner of names or IDs.
+// The primary group of the user is used if the group is omitted
+func lookupOwner(owner string) (uid, gid int, err error) {
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if u, err = user.LookupId(userName); err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to lookup user %s", userName)
+		}
+	}
+	if uid, err = strconv.Atoi(u.Uid); err != nil {
+		return 0, 0, errors.Errorf("user %s has non-numeric id %s", userName, u.Uid)
+	}
+
+	gidStr := u.Gid
+	if hasGroup {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return 0, 0, errors.Wrapf(err, "failed to lookup group %s", groupName)
+			}
+		}
+		gidStr = g.Gid
+	}
+	if gid, err = strconv.Atoi(gidStr); err != nil {
+		return 0, 0, errors.Errorf("group of %s has non-numeric id %s", owner, gidStr)
+	}
+	return uid, gid, nil
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, ConcurrentOutputs: true})
+
+	log.Info("before")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := log.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.WithField("a", 1).Shutdown(ctx); err != nil {
+		t.Fatalf("repeated shutdown: %s", err)
+	}
+
+	log.Info("after")
+	log.WithField("a", 1).Info("after")
+
+	checkFileLogs(t, filename, [][]string{{"before"}})
+	if n := len(readFile(t, filename)); n == 0 {
+		t.Error("no data in file")
+	}
+}
+
+func TestFilePermissions(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "app.log")[0])
+	filename := filepath.Join(dir, "nested", "logs", "app.log")
+
+	current, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, FileMode: 0o600, DirMode: 0o750, FileOwner: current.Username})
+	log.Info("info")
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("want 0600, got %o", mode)
+	}
+	if info, err = os.Stat(filepath.Dir(filename)); err != nil || info.Mode().Perm()&0o007 != 0 {
+		t.Errorf("unexpected directory: %v, %v", info, err)
+	}
+}
+
+func TestLookupOwner(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, gid, err := lookupOwner(current.Uid + ":" + current.Gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strconv.Itoa(uid) != current.Uid || strconv.Itoa(gid) != current.Gid {
+		t.Errorf("want %s:%s, got %d:%d", current.Uid, current.Gid, uid, gid)
+	}
+	if _, _, err := lookupOwner("no-such-user-for-logger-tests"); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestExpandPathTokens(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "app.log")[0])
+	log := newLogger(t, Config{DisableStdOut: true, ExpandPathTokens: true, Files: []string{filepath.Join(dir, "app-%H-%P-100%%.log")}})
+	log.Info("expanded")
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFileLogs(t, filepath.Join(dir, "app-"+hostname+"-"+strconv.Itoa(os.Getpid())+"-100%.log"), [][]string{{"expanded"}})
+
+	for _, path := range []string{"app-%X.log", "app-%"} {
+		if _, err := expandPathTokens(path); err == nil {
+			t.Errorf("%s: want an error", path)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RecoverAndLog recovers a panic and logs it at Error level with the panic fields (see panicFields).
+// It must be deferred directly:
+//
+//	defer log.RecoverAndLog()
+func (l *Logger) RecoverAndLog() {
+	if v := recover(); v != nil {
+		l.logRecovered(v, 0)
+	}
+}
+
+// logRecovered logs a recovered panic value.
+// Skip is the number of stack frames to skip, with 0 identifying the deferred function calling logRecovered
+func (l *Logger) logRecovered(v interface{}, skip int) {
+	ce := l.base().Check(ErrorLevel, "recovered from panic")
+	if ce == nil {
+		return
+	}
+	// Report the panicking function as the caller instead of the runtime
+	if ce.Caller.Defined {
+		ce.Caller = panicCaller(skip + 1)
+	}
+	ce.Write(panicFields(v, skip+2)...)
+}
+
+// panicCaller returns the first non-runtime caller of the deferred function.
+// Skip is the number of stack frames to skip, with 0 identifying the deferred function calling panicCaller
+func panicCaller(skip int) zapcore.EntryCaller {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return zapcore.EntryCaller{
+				Defined:  frame.PC != 0,
+				PC:       frame.PC,
+				File:     frame.File,
+				Line:     frame.Line,
+				Function: frame.Function,
+			}
+		}
+		if !more {
+			return zapcore.EntryCaller{}
+		}
+	}
+}
+
+// panicFields returns structured fields describing a panic value:
+// its type, its Error/String rendering and the stack without runtime frames.
+// Skip is the number of stack frames to skip, with 0 identifying the caller of panicFields
+func panicFields(v interface{}, skip int) []zap.Field {
+	return []zap.Field{
+		zap.String("panic.type", fmt.Sprintf("%T", v)),
+		zap.String("panic.value", panicValueString(v)),
+		zap.String("panic.stack", stack(skip+1)),
+	}
+}
+
+func panicValueString(v interface{}) string {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// panicValue returns the value Panic is called with: the argument itself if it's the only one, or the message
+func panicValue(msg string, args []interface{}) interface{} {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return msg
+}
+
+// stack returns the stack of the calling goroutine without runtime frames.
+// Skip is the number of stack frames to skip, with 0 identifying the caller of stack
+func stack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			if b.Len() != 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPanicFields(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}})
+
+	func() {
+		defer func() {
+			if v := recover(); v != "some error" {
+				t.Errorf("want panic with message, got %v", v)
+			}
+		}()
+		log.Panic(errors.New("some error"))
+	}()
+
+	func() {
+		defer log.RecoverAndLog()
+		panic(errors.New("recovered"))
+	}()
+
+	checkFileLogs(t, filename, [][]string{
+		{"panic_test.go", `"panic.type": "*errors.fundamental"`, `"panic.value": "some error"`, `"panic.stack": "github.com/kiteggrad/logger.TestPanicFields.func1`},
+		{"panic_test.go:25", "recovered from panic", `"panic.value": "recovered"`, `"panic.stack": "github.com/kiteggrad/logger.TestPanicFields.func2`},
+	})
+
+	if strings.Contains(string(readFile(t, filename)), "runtime.gopanic") {
+		t.Error("stack contains runtime frames")
+	}
+}
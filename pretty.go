@@ -0,0 +1,260 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxPrettyMessageWidth limits the message column width, so a single long message doesn't shift all the fields
+const maxPrettyMessageWidth = 60
+
+// prettyKeyColors is a palette of key colors. A key gets the same color on every line
+var prettyKeyColors = []string{"36", "32", "35", "33", "34", "96", "92", "95"}
+
+// prettyWidths tracks column widths shared between an encoder and its clones, so columns stay aligned across lines
+type prettyWidths struct {
+	name    atomic.Int64
+	caller  atomic.Int64
+	message atomic.Int64
+}
+
+// grow returns the column width extended to fit n
+func (w *prettyWidths) grow(width *atomic.Int64, n int) int {
+	for {
+		current := width.Load()
+		if int64(n) <= current {
+			return int(current)
+		}
+		if width.CAS(current, int64(n)) {
+			return n
+		}
+	}
+}
+
+// prettyEncoder is a dev-mode zapcore.Encoder rendering fields inline after the message
+// as key=value pairs with per-key coloring and aligning columns across lines
+type prettyEncoder struct {
+	*prettyFields
+	color  bool
+	widths *prettyWidths
+	times  *timeCache
+	// colorMessages colors messages by level, see Config.ColorMessages
+	colorMessages bool
+}
+
+type prettyField struct {
+	key   string
+	value string
+}
+
+func newPrettyEncoder(color, humanizeDurations bool, times *timeCache) *prettyEncoder {
+	return &prettyEncoder{
+		prettyFields: &prettyFields{humanizeDurations: humanizeDurations},
+		color:        color,
+		widths:       &prettyWidths{},
+		times:        times,
+	}
+}
+
+func (e *prettyEncoder) Clone() zapcore.Encoder {
+	return &prettyEncoder{prettyFields: e.prettyFields.clone(), color: e.color, widths: e.widths, times: e.times, colorMessages: e.colorMessages}
+}
+
+func (e *prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	all := e.prettyFields.clone()
+	for i := range fields {
+		fields[i].AddTo(all)
+	}
+
+	buf := bufferPool.Get()
+	buf.AppendString(e.times.format(ent.Time))
+	buf.AppendByte(' ')
+	e.colorize(buf, levelColor(ent.Level), pad(ent.Level.CapitalString(), 5))
+
+	if ent.LoggerName != "" {
+		buf.AppendByte(' ')
+		buf.AppendString(pad(ent.LoggerName, e.widths.grow(&e.widths.name, len(ent.LoggerName))))
+	}
+	if ent.Caller.Defined {
+		caller := ent.Caller.TrimmedPath()
+		buf.AppendByte(' ')
+		e.colorize(buf, "90", pad(caller, e.widths.grow(&e.widths.caller, len(caller))))
+	}
+
+	buf.AppendByte(' ')
+	msg := ent.Message
+	if len(all.fields) != 0 {
+		width := len(msg)
+		if width <= maxPrettyMessageWidth {
+			width = e.widths.grow(&e.widths.message, width)
+		}
+		msg = pad(msg, width)
+	}
+	if e.color && e.colorMessages {
+		msg = colorMessage(ent.Level, msg)
+	}
+	buf.AppendString(msg)
+
+	for _, f := range all.fields {
+		buf.AppendByte(' ')
+		e.colorize(buf, keyColor(f.key), f.key)
+		buf.AppendByte('=')
+		buf.AppendString(f.value)
+	}
+
+	if ent.Stack != "" {
+		buf.AppendByte('\n')
+		buf.AppendString(ent.Stack)
+	}
+	buf.AppendString(zapcore.DefaultLineEnding)
+	return buf, nil
+}
+
+func (e *prettyEncoder) colorize(buf *buffer.Buffer, color, s string) {
+	if !e.color {
+		buf.AppendString(s)
+		return
+	}
+	buf.AppendString("\x1b[")
+	buf.AppendString(color)
+	buf.AppendByte('m')
+	buf.AppendString(s)
+	buf.AppendString("\x1b[0m")
+}
+
+func levelColor(lvl zapcore.Level) string {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return "35"
+	case zapcore.InfoLevel:
+		return "34"
+	case zapcore.WarnLevel:
+		return "33"
+	default:
+		return "31"
+	}
+}
+
+func keyColor(key string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return prettyKeyColors[h.Sum32()%uint32(len(prettyKeyColors))]
+}
+
+func pad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// prettyFields is a zapcore.ObjectEncoder rendering field values to strings
+type prettyFields struct {
+	fields            []prettyField
+	namespace         string
+	humanizeDurations bool
+}
+
+func (f *prettyFields) clone() *prettyFields {
+	return &prettyFields{
+		fields:            append([]prettyField(nil), f.fields...),
+		namespace:         f.namespace,
+		humanizeDurations: f.humanizeDurations,
+	}
+}
+
+func (f *prettyFields) add(key, value string) {
+	f.fields = append(f.fields, prettyField{key: f.namespace + key, value: value})
+}
+
+func (f *prettyFields) addJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f.add(key, string(data))
+	return nil
+}
+
+func (f *prettyFields) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	enc := zapcore.NewMapObjectEncoder()
+	if err := enc.AddArray(key, marshaler); err != nil {
+		return err
+	}
+	return f.addJSON(key, enc.Fields[key])
+}
+
+func (f *prettyFields) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	enc := zapcore.NewMapObjectEncoder()
+	if err := marshaler.MarshalLogObject(enc); err != nil {
+		return err
+	}
+	return f.addJSON(key, enc.Fields)
+}
+
+func (f *prettyFields) AddReflected(key string, value interface{}) error {
+	if n, ok := value.(byteSize); ok {
+		f.AddString(key, humanizeBytes(int64(n)))
+		return nil
+	}
+	return f.addJSON(key, value)
+}
+
+func (f *prettyFields) OpenNamespace(key string) {
+	f.namespace += key + "."
+}
+
+func (f *prettyFields) AddBinary(key string, value []byte) {
+	f.add(key, base64.StdEncoding.EncodeToString(value))
+}
+func (f *prettyFields) AddByteString(key string, value []byte) { f.AddString(key, string(value)) }
+func (f *prettyFields) AddBool(key string, value bool)         { f.add(key, strconv.FormatBool(value)) }
+func (f *prettyFields) AddComplex128(key string, value complex128) {
+	f.add(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+func (f *prettyFields) AddComplex64(key string, value complex64) {
+	f.add(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+func (f *prettyFields) AddDuration(key string, value time.Duration) {
+	if f.humanizeDurations {
+		f.add(key, humanizeDuration(value))
+		return
+	}
+	f.add(key, value.String())
+}
+func (f *prettyFields) AddFloat64(key string, value float64) {
+	f.add(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+func (f *prettyFields) AddFloat32(key string, value float32) {
+	f.add(key, strconv.FormatFloat(float64(value), 'g', -1, 32))
+}
+func (f *prettyFields) AddInt(key string, value int)     { f.AddInt64(key, int64(value)) }
+func (f *prettyFields) AddInt64(key string, value int64) { f.add(key, strconv.FormatInt(value, 10)) }
+func (f *prettyFields) AddInt32(key string, value int32) { f.AddInt64(key, int64(value)) }
+func (f *prettyFields) AddInt16(key string, value int16) { f.AddInt64(key, int64(value)) }
+func (f *prettyFields) AddInt8(key string, value int8)   { f.AddInt64(key, int64(value)) }
+func (f *prettyFields) AddTime(key string, value time.Time) {
+	f.add(key, value.Format(time.RFC3339Nano))
+}
+func (f *prettyFields) AddUint(key string, value uint)       { f.AddUint64(key, uint64(value)) }
+func (f *prettyFields) AddUint64(key string, value uint64)   { f.add(key, strconv.FormatUint(value, 10)) }
+func (f *prettyFields) AddUint32(key string, value uint32)   { f.AddUint64(key, uint64(value)) }
+func (f *prettyFields) AddUint16(key string, value uint16)   { f.AddUint64(key, uint64(value)) }
+func (f *prettyFields) AddUint8(key string, value uint8)     { f.AddUint64(key, uint64(value)) }
+func (f *prettyFields) AddUintptr(key string, value uintptr) { f.AddUint64(key, uint64(value)) }
+
+// AddString quotes values with spaces, quotes, "=" or control characters to keep lines parseable
+func (f *prettyFields) AddString(key, value string) {
+	if value == "" || strings.ContainsAny(value, " =\"\t") || strings.IndexFunc(value, unsafeRune) >= 0 {
+		value = strconv.Quote(value)
+	}
+	f.add(key, value)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPrettyEncoding(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Encoding: EncodingPretty, Files: []string{filename}})
+
+	log.WithField("user", "john doe").Info("hi")
+	log.WithFields(map[string]interface{}{"n": 1, "tags": []string{"a"}}).Info("longer message")
+
+	checkFileLogs(t, filename, [][]string{
+		{"INFO ", ` hi user="john doe"`},
+		{"INFO ", " longer message ", "n=1", `tags=["a"]`},
+	})
+}
+
+func TestPrettyEncodingAlignment(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Encoding: EncodingPretty, Files: []string{filename}})
+
+	log.WithField("a", 1).Info("longer message")
+	log.WithField("b", 2).Info("short")
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %q", lines)
+	}
+	if strings.Index(lines[0], "a=1") != strings.Index(lines[1], "b=2") {
+		t.Errorf("fields aren't aligned:\n%s\n%s", lines[0], lines[1])
+	}
+}
+
+func TestPrettyEncodingColor(t *testing.T) {
+	enc := newPrettyEncoder(true, false, newTimeCache(""))
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.WarnLevel, Message: "hi"}, []zapcore.Field{zap.String("key", "value")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\x1b[33mWARN \x1b[0m hi \x1b[" + keyColor("key") + "mkey\x1b[0m=value"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("want %q in %q", want, buf.String())
+	}
+}
+
+func TestUnknownEncoding(t *testing.T) {
+	if _, err := New(Config{DisableStdOut: true, Encoding: "xml"}); err == nil {
+		t.Error("want an error")
+	}
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// publishedEntry is an encoded entry passed to publishCore publishers
+type publishedEntry struct {
+	Entry   zapcore.Entry
+	Fields  map[string]interface{} // context and entry fields
+	Payload []byte                 // the JSON-encoded entry
+}
+
+// publishCore is a zapcore.Core encoding entries to JSON and passing them to a publisher,
+// e.g. a message broker client. Unlike outputs, publishers get access to entry fields
+type publishCore struct {
+	zapcore.LevelEnabler
+	enc     zapcore.Encoder
+	fields  []zapcore.Field
+	publish func(e publishedEntry) error
+	sync    func() error // optional
+}
+
+func newPublishCore(enabler zapcore.LevelEnabler, publish func(e publishedEntry) error) *publishCore {
+	if enabler == nil {
+		enabler = zapcore.DebugLevel
+	}
+	return &publishCore{
+		LevelEnabler: enabler,
+		enc:          zapcore.NewJSONEncoder(jsonEncoderConfig()),
+		publish:      publish,
+	}
+}
+
+func (c *publishCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *publishCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *publishCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	return c.publish(publishedEntry{
+		Entry:   ent,
+		Fields:  fieldsMap(c.fields, fields),
+		Payload: copyBuffer(buf),
+	})
+}
+
+func (c *publishCore) Sync() error {
+	if c.sync == nil {
+		return nil
+	}
+	return c.sync()
+}
+
+// fieldsMap encodes the fields into a map. The latter fields overwrite the former ones with the same keys
+func fieldsMap(fieldSets ...[]zapcore.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, fields := range fieldSets {
+		for i := range fields {
+			fields[i].AddTo(enc)
+		}
+	}
+	return enc.Fields
+}
+
+func copyBuffer(buf *buffer.Buffer) []byte {
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+// jsonEncoderConfig is the encoder config for machine-readable outputs
+func jsonEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "ts",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.TimeEncoderOfLayout(time.RFC3339Nano),
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+}
+
+// renderTemplate replaces "{key}" placeholders with the entry field values.
+// "{level}", "{logger}" and "{msg}" are replaced with the entry level, logger name and message unless there are such fields.
+// Missing values are rendered as "_", values are passed through sanitize
+func renderTemplate(tmpl string, e publishedEntry, sanitize func(string) string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		end := -1
+		if start >= 0 {
+			end = strings.IndexByte(tmpl[start:], '}')
+		}
+		if end < 0 {
+			b.WriteString(tmpl)
+			return b.String()
+		}
+		end += start
+
+		b.WriteString(tmpl[:start])
+		key := tmpl[start+1 : end]
+
+		value := "_"
+		if v, ok := e.Fields[key]; ok {
+			value = fmt.Sprint(v)
+		} else if key == "level" {
+			value = e.Entry.Level.String()
+		} else if key == "logger" && e.Entry.LoggerName != "" {
+			value = e.Entry.LoggerName
+		} else if key == "msg" {
+			value = e.Entry.Message
+		}
+		b.WriteString(sanitize(value))
+
+		tmpl = tmpl[end+1:]
+	}
+}
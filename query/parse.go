@@ -0,0 +1,103 @@
+package query
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Record is a parsed entry of the logger output
+type Record struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Logger  string
+	Caller  string
+	Message string
+	Fields  map[string]interface{}
+	// Raw is the original line of lines that can't be parsed
+	Raw string
+}
+
+var (
+	ansiEscape   = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	callerSuffix = regexp.MustCompile(`\.go:\d+$`)
+)
+
+// consoleTimeLayout is the default time layout of the console encoding
+const consoleTimeLayout = "2006-01-02 15:04:05"
+
+// ParseLine parses a line of the JSON or console encoding. Unknown lines are kept raw at info level
+func ParseLine(line string) Record {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		if r, ok := parseJSON(line); ok {
+			return r
+		}
+	}
+	if r, ok := parseConsole(line); ok {
+		return r
+	}
+	return Record{Level: zapcore.InfoLevel, Message: line, Raw: line}
+}
+
+// parseJSON parses a line of the JSON encoding (also served by EntryBus.TailHandler)
+func parseJSON(line string) (Record, bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return Record{}, false
+	}
+	msg, ok := fields["msg"].(string)
+	if !ok {
+		return Record{}, false
+	}
+
+	r := Record{Message: msg, Fields: fields}
+	if lvl, ok := fields["level"].(string); ok {
+		_ = r.Level.UnmarshalText([]byte(lvl))
+	}
+	if ts, ok := fields["ts"].(string); ok {
+		r.Time, _ = time.Parse(time.RFC3339Nano, ts)
+	}
+	r.Logger, _ = fields["logger"].(string)
+	r.Caller, _ = fields["caller"].(string)
+	for _, key := range []string{"ts", "level", "logger", "caller", "msg"} {
+		delete(fields, key)
+	}
+	return r, true
+}
+
+// parseConsole parses a line of the console encoding: tab separated time, level,
+// optional logger name and caller, message and optional JSON fields
+func parseConsole(line string) (Record, bool) {
+	parts := strings.Split(ansiEscape.ReplaceAllString(line, ""), "\t")
+	if len(parts) < 3 {
+		return Record{}, false
+	}
+	ts, err := time.ParseInLocation(consoleTimeLayout, parts[0], time.Local)
+	if err != nil {
+		return Record{}, false
+	}
+	r := Record{Time: ts}
+	if err := r.Level.UnmarshalText([]byte(parts[1])); err != nil {
+		return Record{}, false
+	}
+
+	rest := parts[2:]
+	if last := rest[len(rest)-1]; len(rest) > 1 && strings.HasPrefix(last, "{") {
+		if err := json.Unmarshal([]byte(last), &r.Fields); err == nil {
+			rest = rest[:len(rest)-1]
+		}
+	}
+	r.Message = rest[len(rest)-1]
+	for _, part := range rest[:len(rest)-1] {
+		if callerSuffix.MatchString(part) {
+			r.Caller = part
+		} else if r.Logger == "" {
+			r.Logger = part
+		}
+	}
+	return r, true
+}
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLine(t *testing.T) {
+	ts := time.Date(2024, 6, 2, 10, 30, 15, 0, time.Local)
+
+	tests := map[string]Record{
+		`{"level":"warn","ts":"2024-06-02T10:30:15Z","logger":"db","caller":"db/db.go:12","msg":"slow query","user":"bob"}`: {
+			Time: time.Date(2024, 6, 2, 10, 30, 15, 0, time.UTC), Level: zapcore.WarnLevel, Logger: "db", Caller: "db/db.go:12",
+			Message: "slow query", Fields: map[string]interface{}{"user": "bob"},
+		},
+		"2024-06-02 10:30:15\t\x1b[31mERROR\x1b[0m\tdb\tdb/db.go:12\tfailed\t{\"n\":1}": {
+			Time: ts, Level: zapcore.ErrorLevel, Logger: "db", Caller: "db/db.go:12", Message: "failed", Fields: map[string]interface{}{"n": float64(1)},
+		},
+		"2024-06-02 10:30:15\tINFO\tmain.go:5\tstarted": {
+			Time: ts, Level: zapcore.InfoLevel, Caller: "main.go:5", Message: "started",
+		},
+		"panic: boom": {Level: zapcore.InfoLevel, Message: "panic: boom", Raw: "panic: boom"},
+	}
+	for line, want := range tests {
+		if got := ParseLine(line); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %+v, got %+v", line, want, got)
+		}
+	}
+}
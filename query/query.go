@@ -0,0 +1,205 @@
+// Package query scans files written by the logger (JSON and console encodings) by time range, level and fields,
+// so admin endpoints can answer questions like "errors of request X in the last hour" locally:
+//
+//	files, err := query.Files("/var/log/app.log")
+//	records, err := query.Find(ctx, query.Query{
+//		From:   time.Now().Add(-time.Hour),
+//		Level:  "error",
+//		Fields: map[string]string{"request_id": id},
+//	}, files...)
+//
+// Files with the sidecar index (see logger.Config.FileIndex) are read only in the blocks of the time range
+// containing entries of the level
+package query
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kiteggrad/logger"
+)
+
+// maxLineSize is the maximum size of a scanned line
+const maxLineSize = 16 * 1024 * 1024
+
+// Query selects records. All the set conditions must match
+type Query struct {
+	// From and To limit the time range, zero values leave it open. To is exclusive
+	From, To time.Time
+	// Level is the minimum level, all levels match if it's empty
+	Level string
+	// Fields are the field values to match, compared as formatted by fmt.Sprint
+	Fields map[string]string
+	// Match is an additional predicate
+	Match func(r Record) bool
+	// Limit stops the scan after the number of records, zero is unlimited
+	Limit int
+}
+
+// matcher is a compiled Query
+type matcher struct {
+	Query
+	level    zapcore.Level
+	hasLevel bool
+}
+
+func (q Query) compile() (matcher, error) {
+	m := matcher{Query: q}
+	if q.Level != "" {
+		if err := m.level.UnmarshalText([]byte(q.Level)); err != nil {
+			return m, errors.Errorf("unknown level %q", q.Level)
+		}
+		m.hasLevel = true
+	}
+	return m, nil
+}
+
+func (m matcher) match(r Record) bool {
+	if m.hasLevel && r.Level < m.level {
+		return false
+	}
+	if !r.Time.IsZero() && (!m.From.IsZero() && r.Time.Before(m.From) || !m.To.IsZero() && !r.Time.Before(m.To)) {
+		return false
+	}
+	for key, value := range m.Fields {
+		if v, ok := r.Fields[key]; !ok || fmt.Sprint(v) != value {
+			return false
+		}
+	}
+	return m.Match == nil || m.Match(r)
+}
+
+// matchBlock reports whether an index block can contain matching records
+func (m matcher) matchBlock(block logger.FileIndexBlock) bool {
+	if !m.From.IsZero() && !block.Minute.Add(time.Minute).After(m.From) || !m.To.IsZero() && !block.Minute.Before(m.To) {
+		return false
+	}
+	if !m.hasLevel || len(block.Levels) == 0 {
+		return true
+	}
+	for name, count := range block.Levels {
+		var lvl zapcore.Level
+		if count > 0 && lvl.UnmarshalText([]byte(name)) == nil && lvl >= m.level {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan calls fn with the records of the files matching the query in order until it returns false
+func Scan(ctx context.Context, q Query, fn func(r Record) bool, paths ...string) error {
+	m, err := q.compile()
+	if err != nil {
+		return err
+	}
+
+	found := 0
+	visit := func(r Record) bool {
+		if !m.match(r) {
+			return true
+		}
+		found++
+		return fn(r) && (m.Limit == 0 || found < m.Limit)
+	}
+	for _, path := range paths {
+		more, err := scanFile(ctx, m, path, visit)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan %s", path)
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Find returns the records of the files matching the query
+func Find(ctx context.Context, q Query, paths ...string) ([]Record, error) {
+	var records []Record
+	err := Scan(ctx, q, func(r Record) bool {
+		records = append(records, r)
+		return true
+	}, paths...)
+	return records, err
+}
+
+// scanFile scans the file, only in the matching blocks if it's indexed
+func scanFile(ctx context.Context, m matcher, path string, visit func(r Record) bool) (more bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to open")
+	}
+	defer file.Close()
+
+	blocks, err := logger.ReadFileIndex(path)
+	if err != nil {
+		return scanLines(ctx, file, visit)
+	}
+	// Records written after the last flushed block aren't indexed yet
+	var indexed int64
+	for _, block := range blocks {
+		if end := block.Offset + block.Size; end > indexed {
+			indexed = end
+		}
+		if !m.matchBlock(block) {
+			continue
+		}
+		if more, err = scanLines(ctx, io.NewSectionReader(file, block.Offset, block.Size), visit); err != nil || !more {
+			return more, err
+		}
+	}
+	return scanLines(ctx, io.NewSectionReader(file, indexed, 1<<62), visit)
+}
+
+func scanLines(ctx context.Context, r io.Reader, visit func(r Record) bool) (more bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !visit(ParseLine(scanner.Text())) {
+			return false, nil
+		}
+	}
+	return true, errors.Wrap(scanner.Err(), "failed to read")
+}
+
+// Files returns the file and its rotated files (e.g. "app.2024-06-02.log" of "app.log") from the oldest one
+func Files(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	rotated, err := filepath.Glob(strings.TrimSuffix(path, ext) + ".*" + ext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to glob rotated files")
+	}
+
+	type file struct {
+		path    string
+		modTime time.Time
+	}
+	var files []file
+	for _, p := range append(rotated, path) {
+		info, err := os.Stat(p)
+		if err != nil || strings.HasSuffix(p, ".idx") {
+			continue
+		}
+		files = append(files, file{path: p, modTime: info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
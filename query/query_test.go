@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kiteggrad/logger"
+)
+
+func TestFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, encoding := range []logger.Encoding{logger.EncodingJSON, logger.EncodingConsole} {
+		log, err := logger.New(logger.Config{DisableStdOut: true, DisableColor: true, Encoding: encoding, Files: []string{path}, FileIndex: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		log.WithField("request_id", "1").Info("started")
+		log.WithField("request_id", "1").Error(string(encoding) + " failed")
+		log.WithField("request_id", "2").Error("other request")
+		if encoding == logger.EncodingJSON {
+			if err := log.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := log.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[1] != path {
+		t.Fatalf("want the rotated file and the current one, got %v", files)
+	}
+
+	records, err := Find(context.Background(), Query{
+		From:   time.Now().Add(-time.Hour),
+		Level:  "error",
+		Fields: map[string]string{"request_id": "1"},
+	}, files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, r := range records {
+		messages = append(messages, r.Message)
+	}
+	if want := []string{"json failed", "console failed"}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("want %v, got %v", want, messages)
+	}
+
+	if records, err := Find(context.Background(), Query{To: time.Now().Add(-time.Hour)}, files...); err != nil || len(records) != 0 {
+		t.Errorf("want no records before the time range, got %v %v", records, err)
+	}
+	if records, err := Find(context.Background(), Query{Limit: 2}, files...); err != nil || len(records) != 2 {
+		t.Errorf("want 2 records, got %v %v", records, err)
+	}
+	if _, err := Find(context.Background(), Query{Level: "loud"}, files...); err == nil {
+		t.Error("want an invalid level error")
+	}
+}
+
+func TestFindSkipsIndexBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lines := "old line\nrecent line\nunindexed line\n"
+	if err := os.WriteFile(path, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	blocks := []logger.FileIndexBlock{
+		{Minute: now.Add(-time.Hour), Offset: 0, Size: 9, Records: 1, Levels: map[string]int{"info": 1}},
+		{Minute: now, Offset: 9, Size: 12, Records: 1, Levels: map[string]int{"info": 1}},
+	}
+	index, err := os.Create(path + ".idx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if err := json.NewEncoder(index).Encode(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := index.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := Find(context.Background(), Query{From: now}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Message != "recent line" || records[1].Message != "unindexed line" {
+		t.Errorf("want the recent and unindexed lines, got %+v", records)
+	}
+	if records, _ := Find(context.Background(), Query{From: now, Level: "warn"}, path); len(records) != 0 {
+		t.Errorf("want blocks without the level skipped, got %+v", records)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentUse is meant to be run with -race: it mixes level changes, hot reload, global swaps,
+// rotation and logging through cloned loggers
+func TestConcurrentUse(t *testing.T) {
+	files := createTempFiles(t, "app.log", "level")
+	if err := os.WriteFile(files[1], []byte("info"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := newLogger(t, Config{
+		DisableStdOut:     true,
+		Files:             files[:1],
+		LevelFile:         files[1],
+		LevelFileInterval: time.Millisecond,
+		PackageLevels:     map[string]string{"github.com/kiteggrad/logger": "debug"},
+		Observe:           true,
+	})
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	const iterations = 200
+	levels := []string{"debug", "info", "warn", "error"}
+
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				f(i)
+			}
+		}()
+	}
+
+	run(func(i int) { log.SetLevel(levels[i%len(levels)]) })
+	run(func(i int) { log.TemporarilySetLevel(levels[i%len(levels)])() })
+	run(func(i int) {
+		err := log.ApplyLevels(LevelConfig{Level: levels[i%len(levels)], Names: map[string]string{"db": levels[(i+1)%len(levels)]}})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(i int) {
+		if err := os.WriteFile(files[1], []byte(levels[i%len(levels)]), 0o644); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(i int) {
+		if err := log.Rotate(); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(i int) {
+		SetGlobal(log.Named("global"))
+		L().Info("global")
+	})
+	for g := 0; g < 4; g++ {
+		g := g
+		run(func(i int) {
+			l := log.WithField("goroutine", g).Named("db")
+			l.WithField("i", i).Info("info")
+			l.WithMinLevel("debug").Debugf("debug %d", i)
+			L().WithField("i", i).Warn("warn")
+		})
+	}
+	wg.Wait()
+
+	if len(log.ObservedLogs().All()) == 0 {
+		t.Error("want observed entries")
+	}
+}
+
+func TestSetGlobal(t *testing.T) {
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	log := newLogger(t, Config{DisableStdOut: true})
+	SetGlobal(log)
+	if L() != log {
+		t.Error("want the set logger")
+	}
+
+	SetGlobal(nil)
+	if L() == nil || L() == log {
+		t.Error("want the noop logger")
+	}
+	L().Info("noop")
+}
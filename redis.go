@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// RedisLogger adapts a logger to the internal logger interface of go-redis.
+// go-redis logs only abnormal conditions (dropped connections, failed reconnects...), so they're logged at Warn level
+// with the fields of the context (see RegisterContextExtractor). Slow commands are logged by the hook of the
+// github.com/kiteggrad/logger/loggerredis module:
+//
+//	redis.SetLogger(logger.NewRedisLogger(log.Named("redis")))
+type RedisLogger struct {
+	log *Logger
+}
+
+// NewRedisLogger creates a go-redis logger
+func NewRedisLogger(l *Logger) *RedisLogger {
+	return &RedisLogger{log: l.withOptions(zap.WithCaller(false))}
+}
+
+// Printf logs a go-redis message
+func (r *RedisLogger) Printf(ctx context.Context, format string, v ...interface{}) {
+	log := r.log
+	if ctx != nil {
+		log = log.Ctx(ctx)
+	}
+	base := log.base()
+	if !base.Core().Enabled(WarnLevel) {
+		return
+	}
+	if ce := base.Check(WarnLevel, fmt.Sprintf(format, v...)); ce != nil {
+		ce.Write()
+	}
+}
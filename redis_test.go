@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedisLogger(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	NewRedisLogger(log).Printf(context.Background(), "redis: discarding bad PubSub connection: %s", "EOF")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Level != zapcore.WarnLevel || e.Message != "redis: discarding bad PubSub connection: EOF" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestRedisLoggerCheckedMessage(t *testing.T) {
+	checked := &messageCore{LevelEnabler: zapcore.WarnLevel}
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{checked}})
+
+	NewRedisLogger(log).Printf(context.Background(), "redis: connection pool: %s", "timeout")
+	if len(checked.messages) != 1 || checked.messages[0] != "redis: connection pool: timeout" {
+		t.Errorf("want the message known in Check, got %q", checked.messages)
+	}
+}
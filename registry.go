@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LoggerInfo describes a named logger, see LoggerRegistry.List
+type LoggerInfo struct {
+	Name string `json:"name"`
+	// Level is the effective level: the override of the name (see LevelConfig.Names) or the global one
+	Level string `json:"level"`
+	// Fields are the keys of the logger fields
+	Fields []string `json:"fields"`
+	// Sinks are the sinks the logger writes to by default (see Config.LoggerSinks), empty for all of them
+	Sinks []string `json:"sinks,omitempty"`
+}
+
+// LoggerRegistry keeps the named loggers created by Logger.Named. Loggers aren't kept themselves:
+// a name is registered once per root logger (see New) with the field keys and sinks of its first logger
+// and the level handle of the root, and the registrations of a root are removed by Logger.Shutdown
+type LoggerRegistry struct {
+	loggers sync.Map // registryKey -> *registeredLogger
+
+	tokenOnce sync.Once
+	token     string
+}
+
+// registryKey identifies a name of a root logger by its level handle
+type registryKey struct {
+	name      string
+	level     zap.AtomicLevel
+	overrides *atomic.Value
+}
+
+// registeredLogger is a registered name of a root logger
+type registeredLogger struct {
+	name      string
+	level     zap.AtomicLevel
+	overrides *atomic.Value // *levelOverrides, nil if the logger doesn't support overrides
+	fields    []string
+	sinks     []string
+}
+
+var registry = &LoggerRegistry{}
+
+// Registry returns the registry of named loggers, so operators can see and tune the logging topology at runtime.
+// Names are kept until the root logger is shut down, so they shouldn't be generated per request
+func Registry() *LoggerRegistry {
+	return registry
+}
+
+// add registers the logger name. Names registered for the root logger are only looked up
+func (r *LoggerRegistry) add(l *Logger) {
+	key := registryKey{name: l.name, level: l.level, overrides: l.overrides}
+	if _, ok := r.loggers.Load(key); ok {
+		return
+	}
+	// Noop loggers don't log, so there's nothing to tune
+	if l.root.Core() == zapcore.NewNopCore() {
+		return
+	}
+	r.loggers.LoadOrStore(key, l.registration())
+}
+
+// remove removes the names of the root logger with the level handle
+func (r *LoggerRegistry) remove(level zap.AtomicLevel, overrides *atomic.Value) {
+	r.loggers.Range(func(key, _ interface{}) bool {
+		if k := key.(registryKey); k.level == level && k.overrides == overrides {
+			r.loggers.Delete(key)
+		}
+		return true
+	})
+}
+
+// named returns the registrations of the name in all the root loggers
+func (r *LoggerRegistry) named(name string) []*registeredLogger {
+	var loggers []*registeredLogger
+	r.loggers.Range(func(key, value interface{}) bool {
+		if key.(registryKey).name == name {
+			loggers = append(loggers, value.(*registeredLogger))
+		}
+		return true
+	})
+	return loggers
+}
+
+// List returns the named loggers sorted by name. A name used by several root loggers is listed for each of them
+func (r *LoggerRegistry) List() []LoggerInfo {
+	var infos []LoggerInfo
+	r.loggers.Range(func(_, value interface{}) bool {
+		infos = append(infos, value.(*registeredLogger).info())
+		return true
+	})
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// SetLevel overrides the level of the named logger and its children like LevelConfig.Names,
+// keeping the other overrides. The level is set in all the root loggers with the name
+func (r *LoggerRegistry) SetLevel(name, lvl string) error {
+	loggers := r.named(name)
+	if len(loggers) == 0 {
+		return errors.Errorf("unknown logger %q", name)
+	}
+	if _, err := parseLevel(lvl); err != nil {
+		return err
+	}
+	for _, l := range loggers {
+		if err := l.setLevel(lvl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *registeredLogger) setLevel(lvl string) error {
+	if l.overrides == nil {
+		return errors.New("the logger doesn't support level overrides")
+	}
+	for {
+		current := l.overrides.Load()
+		o := current.(*levelOverrides)
+		names := make(map[string]string, len(o.names)+1)
+		for n, v := range o.names {
+			names[n] = v.String()
+		}
+		names[l.name] = lvl
+		overrides, err := o.withNames(names)
+		if err != nil {
+			return err
+		}
+		if l.overrides.CompareAndSwap(current, overrides) {
+			return nil
+		}
+	}
+}
+
+func (l *registeredLogger) info() LoggerInfo {
+	info := LoggerInfo{Name: l.name, Level: l.level.Level().String(), Fields: append([]string{}, l.fields...)}
+	if l.sinks != nil {
+		info.Sinks = append([]string(nil), l.sinks...)
+	}
+	if l.overrides != nil {
+		if lvl, ok := l.overrides.Load().(*levelOverrides).nameLevel(l.name); ok {
+			info.Level = lvl.String()
+		}
+	}
+	return info
+}
+
+// registration returns the registration of the logger name
+func (l *Logger) registration() *registeredLogger {
+	reg := &registeredLogger{name: l.name, level: l.level, overrides: l.overrides, fields: []string{}}
+	for _, f := range l.described.all() {
+		if targets, ok := f.Interface.(sinkTargets); ok && f.Type == zapcore.SkipType {
+			reg.sinks = append([]string(nil), targets...)
+		} else if f.Type != zapcore.SkipType {
+			reg.fields = append(reg.fields, f.Key)
+		}
+	}
+	if reg.sinks == nil && l.out != nil {
+		if name, ok := closestName(l.name, func(name string) bool { _, ok := l.out.loggerSinks[name]; return ok }); ok {
+			reg.sinks = append([]string(nil), l.out.loggerSinks[name]...)
+		}
+	}
+	return reg
+}
+
+// Handler returns an HTTP debug page listing the named loggers with forms to set their levels.
+// It serves JSON if requested with the Accept header or ?format=json.
+// Levels are set with POST requests protected against cross-site request forgery: form requests
+// (name and level values) must have the token value of the page forms, and JSON requests
+// ({"name": "...", "level": "..."}) must have the application/json content type, which browsers don't send cross-site
+// without a CORS preflight. The handler has no authentication, so it should be served on an internal port
+func (r *LoggerRegistry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			r.handleSetLevel(w, req)
+			return
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		loggers := r.List()
+		if req.URL.Query().Get("format") == "json" || strings.Contains(req.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(loggers)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = registryPage.Execute(w, registryPageData{Loggers: loggers, Levels: registryLevels, Token: r.csrfToken()})
+	})
+}
+
+func (r *LoggerRegistry) handleSetLevel(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Name  string `json:"name"`
+		Level string `json:"level"`
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		if subtle.ConstantTimeCompare([]byte(req.FormValue("token")), []byte(r.csrfToken())) != 1 {
+			http.Error(w, "invalid token", http.StatusForbidden)
+			return
+		}
+		body.Name, body.Level = req.FormValue("name"), req.FormValue("level")
+	}
+
+	if err := r.SetLevel(body.Name, body.Level); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mediaType == "application/json" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, req, req.URL.Path, http.StatusSeeOther)
+}
+
+// csrfToken returns the random token of the page forms, generated once per process
+func (r *LoggerRegistry) csrfToken() string {
+	r.tokenOnce.Do(func() {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			panic(errors.Wrap(err, "failed to generate the registry token"))
+		}
+		r.token = hex.EncodeToString(b)
+	})
+	return r.token
+}
+
+var registryLevels = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+
+type registryPageData struct {
+	Loggers []LoggerInfo
+	Levels  []string
+	Token   string
+}
+
+var registryPage = template.Must(template.New("registry").Parse(`<!DOCTYPE html>
+<html><head><title>Loggers</title></head><body>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>Level</th><th>Fields</th><th>Sinks</th></tr>
+{{range .Loggers}}<tr>
+<td>{{.Name}}</td>
+<td><form method="post"><input type="hidden" name="token" value="{{$.Token}}"><input type="hidden" name="name" value="{{.Name}}">
+<select name="level">{{$lvl := .Level}}{{range $.Levels}}<option{{if eq . $lvl}} selected{{end}}>{{.}}</option>{{end}}</select>
+<button>Set</button></form></td>
+<td>{{range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f}}{{end}}</td>
+<td>{{if .Sinks}}{{range $i, $s := .Sinks}}{{if $i}}, {{end}}{{$s}}{{end}}{{else}}all{{end}}</td>
+</tr>{{end}}
+</table>
+</body></html>
+`))
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// registryInfo returns the registry entry of the named logger
+func registryInfo(t *testing.T, name string) LoggerInfo {
+	t.Helper()
+	for _, info := range Registry().List() {
+		if info.Name == name {
+			return info
+		}
+	}
+	t.Fatalf("logger %q isn't registered", name)
+	return LoggerInfo{}
+}
+
+func TestRegistry(t *testing.T) {
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Sinks:         map[string]SinkConfig{"app": {Output: &bufferSyncer{}}, "sql": {Output: &bufferSyncer{}}},
+		LoggerSinks:   map[string][]string{"registrytest.db": {"sql"}},
+	})
+	t.Cleanup(func() { _ = log.Shutdown(context.Background()) })
+	log.SetLevel("info")
+
+	db := log.WithField("service", "api").Named("registrytest").Named("db").WithField("pool", 1)
+	db.Named("pool")
+	log.Named("registrytest").To("app").Named("cache")
+
+	if got, want := registryInfo(t, "registrytest.db"), (LoggerInfo{Name: "registrytest.db", Level: "info", Fields: []string{"service"}, Sinks: []string{"sql"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+	if got := registryInfo(t, "registrytest.db.pool"); !reflect.DeepEqual(got.Fields, []string{"service", "pool"}) || !reflect.DeepEqual(got.Sinks, []string{"sql"}) {
+		t.Errorf("unexpected info: %+v", got)
+	}
+	if got := registryInfo(t, "registrytest.cache"); !reflect.DeepEqual(got.Sinks, []string{"app"}) {
+		t.Errorf("unexpected info: %+v", got)
+	}
+
+	if err := Registry().SetLevel("registrytest.db", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Registry().SetLevel("registrytest.cache", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	if got := registryInfo(t, "registrytest.db.pool"); got.Level != "debug" {
+		t.Errorf("want the parent override, got %s", got.Level)
+	}
+	if got := registryInfo(t, "registrytest.db"); got.Level != "debug" {
+		t.Errorf("want the override kept, got %s", got.Level)
+	}
+	if err := Registry().SetLevel("registrytest.unknown", "debug"); err == nil {
+		t.Error("want an unknown logger error")
+	}
+
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := Registry().SetLevel("registrytest.db", "debug"); err == nil {
+		t.Error("want the names removed on shutdown")
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true})
+	t.Cleanup(func() { _ = log.Shutdown(context.Background()) })
+	log.Named("registryhandler")
+	handler := Registry().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loggers", nil))
+	if !strings.Contains(rec.Body.String(), "<td>registryhandler</td>") {
+		t.Errorf("unexpected page: %s", rec.Body)
+	}
+	token := regexp.MustCompile(`name="token" value="([0-9a-f]+)"`).FindStringSubmatch(rec.Body.String())
+	if token == nil {
+		t.Fatalf("want a token in the page: %s", rec.Body)
+	}
+
+	postForm := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debug/loggers", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := postForm(url.Values{"name": {"registryhandler"}, "level": {"warn"}}); rec.Code != http.StatusForbidden {
+		t.Errorf("want %d without the token, got %d", http.StatusForbidden, rec.Code)
+	}
+	if rec := postForm(url.Values{"name": {"registryhandler"}, "level": {"warn"}, "token": {token[1]}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("want %d, got %d: %s", http.StatusSeeOther, rec.Code, rec.Body)
+	}
+	if got := registryInfo(t, "registryhandler"); got.Level != "warn" {
+		t.Errorf("want the level set by the form, got %s", got.Level)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/loggers", strings.NewReader(`{"name":"registryhandler","level":"error"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("want %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loggers?format=json", nil))
+	var infos []LoggerInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, info := range infos {
+		found = found || info.Name == "registryhandler" && info.Level == "error"
+	}
+	if !found {
+		t.Errorf("want the level set, got %s", rec.Body)
+	}
+}
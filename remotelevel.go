@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultLevelSourceInterval is the polling interval used if Config.LevelSourceInterval isn't set
+const defaultLevelSourceInterval = 30 * time.Second
+
+// LevelConfig is a level configuration provided by a LevelSource:
+//
+//	{"level": "info", "names": {"db": "debug", "http.access": "warn"}}
+type LevelConfig struct {
+	// Level is the global level. The current level is kept if it's empty
+	Level string `json:"level"`
+	// Names are level overrides by logger name (see Logger.Named). Names match hierarchically
+	Names map[string]string `json:"names"`
+}
+
+// LevelSource provides the level configuration, e.g. from a remote config service
+type LevelSource interface {
+	FetchLevels(ctx context.Context) (LevelConfig, error)
+}
+
+// LevelSourceFunc is a function implementing LevelSource
+type LevelSourceFunc func(ctx context.Context) (LevelConfig, error)
+
+func (f LevelSourceFunc) FetchLevels(ctx context.Context) (LevelConfig, error) { return f(ctx) }
+
+// ApplyLevels sets the global level and replaces level overrides by logger name.
+// Overrides by package (Config.PackageLevels) are kept.
+// It affects the logger and all the loggers derived from it
+func (l *Logger) ApplyLevels(cfg LevelConfig) error {
+	if l == nil {
+		return nil
+	}
+	lvl := l.level.Level()
+	if cfg.Level != "" {
+		var err error
+		if lvl, err = parseLevel(cfg.Level); err != nil {
+			return err
+		}
+	}
+
+	if l.overrides != nil {
+		// Retry if the overrides were replaced concurrently, so package overrides aren't lost
+		for {
+			current := l.overrides.Load()
+			overrides, err := current.(*levelOverrides).withNames(cfg.Names)
+			if err != nil {
+				return err
+			}
+			if l.overrides.CompareAndSwap(current, overrides) {
+				break
+			}
+		}
+	} else if len(cfg.Names) != 0 {
+		return errors.New("the logger doesn't support level overrides")
+	}
+
+	l.level.SetLevel(lvl)
+	return nil
+}
+
+// pollLevelSource applies levels from the source every interval until the returned stop function is called.
+// Fetch errors are logged once per failure streak, the current levels are kept
+func pollLevelSource(l *Logger, source LevelSource, interval time.Duration) (stop func() error) {
+	if interval <= 0 {
+		interval = defaultLevelSourceInterval
+	}
+
+	failing := false
+	apply := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+
+		cfg, err := source.FetchLevels(ctx)
+		if err == nil {
+			err = l.ApplyLevels(cfg)
+		}
+		if err != nil && !failing {
+			l.WithError(err).Warn("failed to apply levels from the level source, keeping the current ones")
+		}
+		failing = err != nil
+	}
+
+	apply()
+	return runEvery(interval, apply)
+}
+
+// httpLevelSource fetches LevelConfig as JSON from an HTTP endpoint
+type httpLevelSource struct {
+	newRequest func(ctx context.Context) (*http.Request, error)
+	decode     func(body []byte) (LevelConfig, error)
+	transport  SinkTransportConfig
+	client     *http.Client
+}
+
+func (s *httpLevelSource) FetchLevels(ctx context.Context) (LevelConfig, error) {
+	req, err := s.newRequest(ctx)
+	if err != nil {
+		return LevelConfig{}, errors.Wrap(err, "failed to create request")
+	}
+	s.transport.SetHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return LevelConfig{}, errors.Wrap(err, "failed to fetch levels")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LevelConfig{}, errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return LevelConfig{}, errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return s.decode(body)
+}
+
+func newHTTPLevelSource(
+	transport SinkTransportConfig,
+	newRequest func(ctx context.Context) (*http.Request, error),
+	decode func(body []byte) (LevelConfig, error),
+) (LevelSource, error) {
+	client, err := transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &httpLevelSource{newRequest: newRequest, decode: decode, transport: transport, client: client}, nil
+}
+
+func decodeLevelConfig(data []byte) (cfg LevelConfig, err error) {
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, errors.Wrap(err, "failed to decode level config")
+	}
+	return cfg, nil
+}
+
+// NewHTTPLevelSource creates a LevelSource fetching LevelConfig JSON with GET requests to the URL
+func NewHTTPLevelSource(url string, transport SinkTransportConfig) (LevelSource, error) {
+	return newHTTPLevelSource(transport, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	}, decodeLevelConfig)
+}
+
+// NewConsulLevelSource creates a LevelSource reading LevelConfig JSON from the Consul KV key
+// using the Consul HTTP API at addr (e.g. "http://127.0.0.1:8500").
+// Set the ACL token with SinkTransportConfig.Headers["X-Consul-Token"]
+func NewConsulLevelSource(addr, key string, transport SinkTransportConfig) (LevelSource, error) {
+	url := strings.TrimSuffix(addr, "/") + "/v1/kv/" + strings.TrimPrefix(key, "/") + "?raw"
+	return NewHTTPLevelSource(url, transport)
+}
+
+// NewEtcdLevelSource creates a LevelSource reading LevelConfig JSON from the etcd key
+// using the etcd v3 JSON gateway at addr (e.g. "http://127.0.0.1:2379")
+func NewEtcdLevelSource(addr, key string, transport SinkTransportConfig) (LevelSource, error) {
+	url := strings.TrimSuffix(addr, "/") + "/v3/kv/range"
+	reqBody, err := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(key))})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode request")
+	}
+
+	return newHTTPLevelSource(transport, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	}, func(body []byte) (LevelConfig, error) {
+		var resp struct {
+			KVs []struct {
+				Value []byte `json:"value"` // base64 is decoded by encoding/json
+			} `json:"kvs"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return LevelConfig{}, errors.Wrap(err, "failed to decode etcd response")
+		}
+		if len(resp.KVs) == 0 {
+			return LevelConfig{}, errors.Errorf("etcd key %s not found", key)
+		}
+		return decodeLevelConfig(resp.KVs[0].Value)
+	})
+}
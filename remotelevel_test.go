@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApplyLevels(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}})
+
+	err := log.ApplyLevels(LevelConfig{Level: "warn", Names: map[string]string{"db": "debug", "db.pool": "error"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.ApplyLevels(LevelConfig{Names: map[string]string{"db": "unknown"}}); err == nil {
+		t.Error("want error for unknown level")
+	}
+
+	log.Info("skipped")
+	log.Named("db").Debug("db")
+	log.Named("db").Named("tx").Debug("db.tx")
+	log.Named("db").Named("pool").Warn("skipped")
+
+	checkFileLogs(t, filename, [][]string{{"db	", "db"}, {"db.tx	", "db.tx"}})
+}
+
+func TestLevelSources(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const levels = `{"level": "error", "names": {"db": "debug"}}`
+		switch r.URL.Path {
+		case "/levels", "/v1/kv/app/levels":
+			fmt.Fprint(w, levels)
+		case "/v3/kv/range":
+			fmt.Fprintf(w, `{"kvs": [{"value": %q}]}`, base64.StdEncoding.EncodeToString([]byte(levels)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	httpSource, err := NewHTTPLevelSource(srv.URL+"/levels", SinkTransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consulSource, err := NewConsulLevelSource(srv.URL, "app/levels", SinkTransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	etcdSource, err := NewEtcdLevelSource(srv.URL, "app/levels", SinkTransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, source := range map[string]LevelSource{"http": httpSource, "consul": consulSource, "etcd": etcdSource} {
+		cfg, err := source.FetchLevels(context.Background())
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if cfg.Level != "error" || cfg.Names["db"] != "debug" {
+			t.Errorf("%s: unexpected config %+v", name, cfg)
+		}
+	}
+}
+
+func TestLevelSourcePolling(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Files:         []string{filename},
+		LevelSource: LevelSourceFunc(func(ctx context.Context) (LevelConfig, error) {
+			return LevelConfig{Level: "error"}, nil
+		}),
+	})
+	defer log.Shutdown(context.Background())
+
+	log.Info("skipped")
+	log.Error("error")
+
+	checkFileLogs(t, filename, [][]string{{"error"}})
+}
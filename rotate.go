@@ -0,0 +1,472 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+)
+
+// RotationPeriod is a wall-clock interval to rotate log files on
+type RotationPeriod string
+
+const (
+	// RotateNever disables time-based rotation
+	RotateNever RotationPeriod = ""
+	// RotateHourly rotates files at the beginning of every hour
+	RotateHourly RotationPeriod = "hourly"
+	// RotateDaily rotates files at local midnight
+	RotateDaily RotationPeriod = "daily"
+)
+
+func (p RotationPeriod) valid() bool {
+	switch p {
+	case RotateNever, RotateHourly, RotateDaily:
+		return true
+	default:
+		return false
+	}
+}
+
+// start returns the beginning of the period containing t
+func (p RotationPeriod) start(t time.Time) time.Time {
+	switch p {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// next returns the beginning of the period following the one containing t
+func (p RotationPeriod) next(t time.Time) time.Time {
+	switch p {
+	case RotateHourly:
+		return p.start(t).Add(time.Hour)
+	case RotateDaily:
+		return p.start(t).AddDate(0, 0, 1)
+	default:
+		return time.Time{}
+	}
+}
+
+// layout returns the time layout used in names of rotated files
+func (p RotationPeriod) layout() string {
+	switch p {
+	case RotateHourly:
+		return "2006-01-02T15"
+	case RotateDaily:
+		return "2006-01-02"
+	default:
+		return "2006-01-02T15-04-05"
+	}
+}
+
+// fileOptions configures a fileWriter
+type fileOptions struct {
+	rotation RotationPeriod
+	// lock locks the file for every write, see Config.FileLock
+	lock bool
+	// maxRecordSize truncates longer records, see Config.MaxRecordSize
+	maxRecordSize int
+	// mode is the file permissions, 0666 before umask if it's zero
+	mode os.FileMode
+	// dirMode is the permissions of created parent directories. Directories aren't created if it's zero
+	dirMode os.FileMode
+	// uid and gid are the file owner set when running as root, -1 keeps it unchanged
+	uid, gid int
+	// templates renders time layouts in braces in paths, see Config.PathTemplates
+	templates bool
+	// expandTokens replaces "%H" and "%P" in paths, see Config.ExpandPathTokens
+	expandTokens bool
+	// index maintains the sidecar index, see Config.FileIndex
+	index bool
+	// sync is how often files are fsynced, see Config.FileSync
+	sync         FileSyncMode
+	syncInterval time.Duration
+}
+
+// fileWriter is a zapcore.WriteSyncer writing to a file that can be rotated
+// either manually or on wall-clock boundaries.
+// Rotated files are renamed to "<name>.<time>.<ext>", e.g. "app.2024-06-02.log".
+// Paths with time layouts in braces, e.g. "/var/log/app/{2006/01/02}/app.log", switch to the next path instead
+// if fileOptions.templates is set
+type fileWriter struct {
+	mu   sync.Mutex
+	path string
+	// template is the path with time layouts, empty for static paths
+	template string
+	period   RotationPeriod
+	opts     fileOptions
+	file     *os.File
+	index    *fileIndex // nil unless fileOptions.index is set
+	// indexErr is the first index error since the last Sync. The records are written anyway,
+	// so it's reported to stderr and returned by the next Sync
+	indexErr  error
+	periodEnd time.Time
+	now       func() time.Time
+
+	// written is the sequence number of the last written record, synced is the one of the last fsynced record
+	written uint64
+	synced  atomic.Uint64
+	syncMu  sync.Mutex // serializes fsyncs of FileSyncEveryEntry
+}
+
+func openFileWriter(path string, opts fileOptions) (*fileWriter, error) {
+	period := opts.rotation
+	w := &fileWriter{
+		path:   path,
+		period: period,
+		opts:   opts,
+		now:    time.Now,
+	}
+
+	if opts.templates && isPathTemplate(path) {
+		rendered, err := renderPathTemplate(path, w.now())
+		if err != nil {
+			return nil, err
+		}
+		w.template, w.path = path, rendered
+		if period == RotateNever {
+			w.period = templatePeriod(path)
+		}
+	} else if period != RotateNever {
+		// The file could be left by a previous run in an already finished period
+		info, err := os.Stat(path)
+		if err == nil && info.ModTime().Before(period.start(w.now())) {
+			if err := w.rename(period.start(info.ModTime())); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, seq, err := w.write(p)
+	if err != nil || w.opts.sync != FileSyncEveryEntry {
+		return n, err
+	}
+	if err := w.waitDurable(seq); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// write writes the record returning its sequence number
+func (w *fileWriter) write(p []byte) (n int, seq uint64, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		// The file couldn't be reopened after a failed rotation
+		if err := w.open(); err != nil {
+			return 0, 0, err
+		}
+	} else if w.period != RotateNever && !w.now().Before(w.periodEnd) {
+		if err := w.rotate(); err != nil {
+			if w.file == nil {
+				return 0, 0, err
+			}
+			// The record is written to the reopened file, the rotation is retried in the next period
+			fmt.Fprintf(stderr, "%v failed to rotate %s: %v\n", time.Now().UTC(), w.path, err)
+		}
+	}
+
+	// A record is written with a single write call, so O_APPEND keeps records of several processes whole
+	record := p
+	if w.opts.maxRecordSize > 0 && len(record) > w.opts.maxRecordSize {
+		record = truncateRecord(record, w.opts.maxRecordSize)
+	}
+	if w.opts.lock {
+		if err := lockFile(w.file); err != nil {
+			return 0, 0, errors.Wrap(err, "failed to lock file")
+		}
+		defer func() { _ = unlockFile(w.file) }()
+	}
+
+	if _, err := w.file.Write(record); err != nil {
+		return 0, 0, err
+	}
+	w.written++
+	if w.index != nil {
+		if err := w.index.add(w.now(), record); err != nil {
+			fmt.Fprintf(stderr, "%v failed to index %s: %v\n", time.Now().UTC(), w.path, err)
+			if w.indexErr == nil {
+				w.indexErr = errors.Wrap(err, "failed to index records")
+			}
+		}
+	}
+	return len(p), w.written, nil
+}
+
+// truncateRecord cuts the record to the size keeping the trailing newline
+func truncateRecord(p []byte, size int) []byte {
+	if size < 2 || p[len(p)-1] != '\n' {
+		return p[:size]
+	}
+	record := make([]byte, size)
+	copy(record, p[:size-1])
+	record[size-1] = '\n'
+	return record
+}
+
+func (w *fileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errors.New("file isn't open after a failed rotation")
+	}
+	if w.index != nil {
+		if err := w.index.flush(); err != nil {
+			return err
+		}
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	storeMax(&w.synced, w.written)
+	err := w.indexErr
+	w.indexErr = nil
+	return err
+}
+
+func (w *fileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.close()
+}
+
+func (w *fileWriter) close() error {
+	file, index := w.file, w.index
+	w.file, w.index = nil, nil
+	if file == nil {
+		return nil
+	}
+	if index == nil {
+		return file.Close()
+	}
+	return multierr.Append(index.close(), file.Close())
+}
+
+// Rotate renames the current file and starts a new one
+func (w *fileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// rotate closes the current file and starts the next one. If that fails, the current path is reopened,
+// so a temporary failure (e.g. a full disk) doesn't stop the writes
+func (w *fileWriter) rotate() error {
+	var err error
+	if w.file != nil && w.opts.sync != FileSyncNever {
+		if syncErr := w.file.Sync(); syncErr != nil {
+			err = errors.Wrap(syncErr, "failed to fsync file")
+		} else {
+			storeMax(&w.synced, w.written)
+		}
+	}
+	if closeErr := w.close(); closeErr != nil {
+		err = multierr.Append(err, errors.Wrap(closeErr, "failed to close file"))
+	}
+
+	path := w.path
+	if nextErr := w.next(); nextErr != nil {
+		err = multierr.Append(err, nextErr)
+		w.path = path
+		if openErr := w.open(); openErr != nil {
+			return multierr.Append(err, errors.Wrap(openErr, "failed to reopen file"))
+		}
+	}
+	return err
+}
+
+// next starts the next file after the current one is closed
+func (w *fileWriter) next() error {
+	// A new period of a templated path starts a new file, unless it resolves to the same path
+	if w.template != "" {
+		if path, _ := renderPathTemplate(w.template, w.now()); path != w.path {
+			w.path = path
+			return w.open()
+		}
+	}
+
+	// Name the rotated file after the period it contains
+	stamp := w.now()
+	if w.period != RotateNever {
+		stamp = w.period.start(w.periodEnd.Add(-time.Nanosecond))
+	}
+	if err := w.rename(stamp); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *fileWriter) open() error {
+	dirMode := w.opts.dirMode
+	if dirMode == 0 && w.template != "" {
+		dirMode = 0o755
+	}
+	if dirMode != 0 {
+		if err := os.MkdirAll(filepath.Dir(w.path), dirMode); err != nil {
+			return errors.Wrap(err, "failed to create directory")
+		}
+	}
+
+	mode := w.opts.mode
+	if mode == 0 {
+		mode = 0o666
+	}
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, mode)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	// The mode of existing files isn't changed by OpenFile
+	if w.opts.mode != 0 {
+		if err := file.Chmod(w.opts.mode); err != nil {
+			_ = file.Close()
+			return errors.Wrap(err, "failed to chmod file")
+		}
+	}
+	if (w.opts.uid >= 0 || w.opts.gid >= 0) && os.Geteuid() == 0 {
+		if err := file.Chown(w.opts.uid, w.opts.gid); err != nil {
+			_ = file.Close()
+			return errors.Wrap(err, "failed to chown file")
+		}
+	}
+	// The directory entries of the created and rotated files are made durable like the records
+	if w.opts.sync != FileSyncNever {
+		if err := syncDir(filepath.Dir(w.path)); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if w.opts.index {
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return errors.Wrap(err, "failed to stat file")
+		}
+		if w.index, err = openFileIndex(w.path, info.Size(), mode); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	w.file = file
+	w.periodEnd = w.period.next(w.now())
+	return nil
+}
+
+// rename moves the current file to a backup name derived from t.
+// A numeric suffix is added if the backup already exists (e.g. after manual rotation)
+func (w *fileWriter) rename(t time.Time) error {
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(w.path, ext) + "." + t.Format(w.period.layout())
+
+	name := prefix + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			break
+		}
+		name = prefix + "." + strconv.Itoa(i) + ext
+	}
+
+	if err := os.Rename(w.path, name); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to rename file")
+	}
+	if w.opts.index {
+		if err := os.Rename(w.path+fileIndexSuffix, name+fileIndexSuffix); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to rename file index")
+		}
+	}
+	return nil
+}
+
+// isPathTemplate reports whether the path contains time layouts in braces
+func isPathTemplate(path string) bool {
+	return strings.ContainsAny(path, "{}")
+}
+
+// renderPathTemplate replaces time layouts in braces with t formatted by them,
+// e.g. "/var/log/{2006/01/02}/app.log" becomes "/var/log/2024/06/02/app.log"
+func renderPathTemplate(template string, t time.Time) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", errors.Errorf("unbalanced braces in path %s", template)
+			}
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 || strings.IndexByte(rest[:start], '}') >= 0 {
+			return "", errors.Errorf("unbalanced braces in path %s", template)
+		}
+		end += start
+
+		b.WriteString(rest[:start])
+		b.WriteString(t.Format(rest[start+1 : end]))
+		rest = rest[end+1:]
+	}
+}
+
+// templatePeriod returns the period a templated path changes with: hourly if it contains an hour, daily otherwise
+func templatePeriod(template string) RotationPeriod {
+	day := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, _ := renderPathTemplate(template, day)
+	second, _ := renderPathTemplate(template, day.Add(time.Hour))
+	if first != second {
+		return RotateHourly
+	}
+	return RotateDaily
+}
+
+// expandPathTokens replaces "%H" with the hostname, "%P" with the process ID and "%%" with "%"
+func expandPathTokens(path string) (string, error) {
+	if !strings.Contains(path, "%") {
+		return path, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' {
+			b.WriteByte(path[i])
+			continue
+		}
+		if i++; i == len(path) {
+			return "", errors.Errorf("incomplete token at the end of path %s", path)
+		}
+		switch path[i] {
+		case 'H':
+			hostname, err := os.Hostname()
+			if err != nil {
+				return "", errors.Wrap(err, "failed to get hostname")
+			}
+			b.WriteString(hostname)
+		case 'P':
+			b.WriteString(strconv.Itoa(os.Getpid()))
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", errors.Errorf("unknown token %%%c in path %s", path[i], path)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,215 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotate(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Files: []string{filename}})
+
+	log.Info(1)
+	if err := log.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info(2)
+	if err := log.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info(3)
+
+	rotated, err := filepath.Glob(filepath.Join(filepath.Dir(filename), "app.*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 {
+		t.Errorf("want 2 rotated files, got %v", rotated)
+	}
+	checkFileLogs(t, filename, [][]string{{"INFO", "	3"}})
+}
+
+func TestRotateByTime(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+
+	now := time.Date(2024, 6, 2, 23, 59, 0, 0, time.Local)
+	w, err := openFileWriter(filename, fileOptions{rotation: RotateDaily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.now = func() time.Time { return now }
+	w.periodEnd = RotateDaily.next(now)
+
+	mustWrite(t, w, "1\n")
+	now = now.Add(2 * time.Minute)
+	mustWrite(t, w, "2\n")
+
+	checkFileLogs(t, filename, [][]string{{"2"}})
+	checkFileLogs(t, filepath.Join(filepath.Dir(filename), "app.2024-06-02.log"), [][]string{{"1"}})
+}
+
+func TestRotateTemplatedPath(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "app.log")[0])
+
+	w, err := openFileWriter(filepath.Join(dir, "{2006/01/02}", "app.log"), fileOptions{templates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.period != RotateDaily {
+		t.Errorf("want daily period, got %q", w.period)
+	}
+
+	now := time.Date(2024, 6, 2, 23, 59, 0, 0, time.Local)
+	w.now = func() time.Time { return now }
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	mustWrite(t, w, "1\n")
+	now = now.Add(2 * time.Minute)
+	mustWrite(t, w, "2\n")
+
+	checkFileLogs(t, filepath.Join(dir, "2024", "06", "02", "app.log"), [][]string{{"1"}})
+	checkFileLogs(t, filepath.Join(dir, "2024", "06", "03", "app.log"), [][]string{{"2"}})
+}
+
+func TestRotateFailureKeepsWriting(t *testing.T) {
+	errOut := captureStderr(t)
+	dir := filepath.Dir(createTempFiles(t, "app.log")[0])
+
+	w, err := openFileWriter(filepath.Join(dir, "{2006-01-02}", "app.log"), fileOptions{templates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	now := time.Date(2024, 6, 2, 23, 59, 0, 0, time.Local)
+	w.now = func() time.Time { return now }
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file in place of the directory of the next period fails the rotation
+	blocker := filepath.Join(dir, "2024-06-03")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, w, "1\n")
+	now = now.Add(2 * time.Minute)
+	mustWrite(t, w, "2\n")
+	if !strings.Contains(string(errOut.Bytes()), "failed to rotate") {
+		t.Errorf("want the rotation error reported, got %q", string(errOut.Bytes()))
+	}
+
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(24 * time.Hour)
+	mustWrite(t, w, "3\n")
+
+	checkFileLogs(t, filepath.Join(dir, "2024-06-02", "app.log"), [][]string{{"1"}, {"2"}})
+	checkFileLogs(t, filepath.Join(dir, "2024-06-04", "app.log"), [][]string{{"3"}})
+}
+
+func TestLiteralBracesPath(t *testing.T) {
+	filename := filepath.Join(filepath.Dir(createTempFiles(t, "app.log")[0]), "app-{1}.log")
+
+	w, err := openFileWriter(filename, fileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	mustWrite(t, w, "1\n")
+
+	checkFileLogs(t, filename, [][]string{{"1"}})
+}
+
+func TestRenderPathTemplate(t *testing.T) {
+	now := time.Date(2024, 6, 2, 15, 4, 0, 0, time.UTC)
+	for template, want := range map[string]string{
+		"/var/log/app.log":                   "/var/log/app.log",
+		"/var/log/{2006/01/02}/app.log":      "/var/log/2024/06/02/app.log",
+		"/var/log/{2006-01-02}/app-{15}.log": "/var/log/2024-06-02/app-15.log",
+	} {
+		if got, err := renderPathTemplate(template, now); err != nil || got != want {
+			t.Errorf("%s: want %s, got %s (%v)", template, want, got, err)
+		}
+	}
+	for _, template := range []string{"/var/log/{2006/app.log", "/var/log/2006}/app.log"} {
+		if _, err := renderPathTemplate(template, now); err == nil {
+			t.Errorf("%s: want an error", template)
+		}
+	}
+
+	if p := templatePeriod("/var/log/{2006-01-02}/app-{15}.log"); p != RotateHourly {
+		t.Errorf("want hourly period, got %q", p)
+	}
+}
+
+func TestRotateOnRestart(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+
+	if err := os.WriteFile(filename, []byte("old\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2024, 6, 2, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(filename, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	log := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}, Rotation: RotateHourly})
+	log.Info("new")
+
+	checkFileLogs(t, filename, [][]string{{"new"}})
+	checkFileLogs(t, filepath.Join(filepath.Dir(filename), "app.2024-06-02T12.log"), [][]string{{"old"}})
+}
+
+func mustWrite(t *testing.T, w *fileWriter, s string) {
+	t.Helper()
+
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileLockSharedFile(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	cfg := Config{DisableStdOut: true, DisableColor: true, Files: []string{filename}, FileLock: true, MaxRecordSize: 100}
+	first, second := newLogger(t, cfg), newLogger(t, cfg)
+
+	var wg sync.WaitGroup
+	for _, log := range []*Logger{first, second} {
+		wg.Add(1)
+		go func(log *Logger) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.Info(strings.Repeat("x", 200))
+			}
+		}(log)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(string(readFile(t, filename)), "\n"), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("want 200 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if len(line) != 99 || !strings.HasSuffix(line, "xxx") {
+			t.Fatalf("unexpected line: %q", line)
+		}
+	}
+}
+
+func TestTruncateRecord(t *testing.T) {
+	if got := string(truncateRecord([]byte("abcdef\n"), 4)); got != "abc\n" {
+		t.Errorf("want abc, got %q", got)
+	}
+	if got := string(truncateRecord([]byte("abcdef"), 4)); got != "abcd" {
+		t.Errorf("want abcd, got %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkConfig defines a named output. Entries can be targeted to it by the name, see Logger.To
+type SinkConfig struct {
+	// Path is a file path, "fd://<n>" or any URL supported by zap.Open (e.g. "stderr")
+	Path string
+	// Output is a custom output used instead of Path. It's closed by Logger.Shutdown if it implements io.Closer
+	Output zapcore.WriteSyncer
+	// Rotation overrides Config.Rotation for a plain file Path
+	Rotation RotationPeriod
+	// Exclusive makes the sink receive only the entries targeted to it or its groups
+	Exclusive bool
+	// Encoding overrides Config.Encoding for the sink, e.g. JSON to a file next to colored console stdout
+	Encoding Encoding
+	// DisableColor disables colors for the sink even if Config.DisableColor isn't set
+	DisableColor bool
+}
+
+// SinkGroup is a named group of outputs entries can be targeted to with Logger.To
+type SinkGroup struct {
+	// Outputs are names of the outputs in the group: "stdout", paths from Config.Files or names of Config.Sinks
+	Outputs []string
+	// Exclusive makes the outputs receive only the entries targeted to them (e.g. an audit file),
+	// instead of all the entries
+	Exclusive bool
+}
+
+// sinkTargets is a hidden field value carrying the names the logger entries are targeted to
+type sinkTargets []string
+
+// targetsField returns a field that is skipped by encoders but routes entries by routeCore
+func targetsField(targets []string) zap.Field {
+	return zap.Field{Key: "sinks", Type: zapcore.SkipType, Interface: sinkTargets(targets)}
+}
+
+// To returns a logger writing only to the given outputs or sink groups (see Config.SinkGroups),
+// e.g. l.To("audit").Info("user deleted").
+// Outputs are named "stdout", by their paths from Config.Files or by Config.Sinks names.
+// Custom Outputs and Cores don't receive targeted entries.
+// Entries targeted to unknown names are dropped. It replaces the targets of a previous To call and Config.LoggerSinks
+func (l *Logger) To(targets ...string) *Logger {
+	return l.withFields(targetsField(append([]string(nil), targets...)))
+}
+
+// routeCore writes untargeted entries unless exclusive, and entries targeted to any of its names
+type routeCore struct {
+	zapcore.Core
+	names     map[string]bool
+	exclusive bool
+	targets   sinkTargets // nil for untargeted entries
+	bindings  map[string]sinkTargets
+}
+
+// newRouteCores creates routing cores for the outputs according to the groups and logger bindings
+// Custom cores are unnamed, so they don't receive targeted entries
+func newRouteCores(outputs []namedOutput, custom []zapcore.Core, enc zapcore.Encoder, groups map[string]SinkGroup, loggerSinks map[string][]string) ([]zapcore.Core, error) {
+	bindings := make(map[string]sinkTargets, len(loggerSinks))
+	for name, targets := range loggerSinks {
+		bindings[name] = append(sinkTargets(nil), targets...)
+	}
+
+	routes := make(map[string]*routeCore, len(outputs))
+	routed := make([]zapcore.Core, len(outputs), len(outputs)+len(custom))
+	for i, output := range outputs {
+		outputEnc := enc
+		if output.enc != nil {
+			outputEnc = output.enc
+		}
+		core := newOutputCore(outputEnc.Clone(), output.ws)
+		if output.drops != nil {
+			core = &dropCore{Core: core, drops: output.drops}
+		}
+		route := &routeCore{Core: core, names: map[string]bool{}, exclusive: output.exclusive, bindings: bindings}
+		if output.name != "" {
+			if _, ok := routes[output.name]; ok {
+				return nil, errors.Errorf("duplicate output %q", output.name)
+			}
+			route.names[output.name] = true
+			routes[output.name] = route
+		}
+		routed[i] = route
+	}
+
+	for _, group := range sortedKeys(groups) {
+		if _, ok := routes[group]; ok {
+			return nil, errors.Errorf("sink group %q has the same name as an output", group)
+		}
+		for _, name := range groups[group].Outputs {
+			route, ok := routes[name]
+			if !ok {
+				return nil, errors.Errorf("unknown output %q in sink group %q", name, group)
+			}
+			route.names[group] = true
+			route.exclusive = route.exclusive || groups[group].Exclusive
+		}
+	}
+
+	for _, name := range sortedKeys(loggerSinks) {
+		for _, target := range loggerSinks[name] {
+			if _, ok := groups[target]; !ok && routes[target] == nil {
+				return nil, errors.Errorf("unknown sink %q bound to logger %q", target, name)
+			}
+		}
+	}
+
+	for _, core := range custom {
+		routed = append(routed, &routeCore{Core: core, bindings: bindings})
+	}
+	return routed, nil
+}
+
+// bound returns the targets bound to the logger name or its closest dotted parent, see Config.LoggerSinks
+func (c *routeCore) bound(name string) sinkTargets {
+	if len(c.bindings) == 0 {
+		return nil
+	}
+	if name, ok := closestName(name, func(name string) bool { _, ok := c.bindings[name]; return ok }); ok {
+		return c.bindings[name]
+	}
+	return nil
+}
+
+// closestName returns the logger name or its closest dotted parent the function reports, e.g. "db" for "db.pool"
+func closestName(name string, has func(name string) bool) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	for {
+		if has(name) {
+			return name, true
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			return "", false
+		}
+		name = name[:dot]
+	}
+}
+
+func (c *routeCore) accepts(targets sinkTargets) bool {
+	if targets == nil {
+		return !c.exclusive
+	}
+	for _, target := range targets {
+		if c.names[target] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	rest := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if targets, ok := f.Interface.(sinkTargets); ok && f.Type == zapcore.SkipType {
+			clone.targets = targets
+			continue
+		}
+		rest = append(rest, f)
+	}
+	clone.Core = c.Core.With(rest)
+	return &clone
+}
+
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	targets := c.targets
+	if targets == nil {
+		targets = c.bound(ent.LoggerName)
+	}
+	if !c.accepts(targets) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
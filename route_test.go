@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTo(t *testing.T) {
+	files := createTempFiles(t, "app.log", "audit.log")
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Files:         files,
+		SinkGroups:    map[string]SinkGroup{"audit": {Outputs: files[1:], Exclusive: true}},
+	})
+
+	log.Info("app")
+	log.To("audit").Info("audit")
+	log.To(files[0]).Info("app only")
+	log.To("audit", files[0]).WithField("a", 1).Info("both")
+	log.To("unknown").Info("nowhere")
+
+	checkFileLogs(t, files[0], [][]string{{"app"}, {"app only"}, {"both", `"a": 1`}})
+	checkFileLogs(t, files[1], [][]string{{"audit"}, {"both", `"a": 1`}})
+}
+
+func TestUnknownSinkGroupOutput(t *testing.T) {
+	_, err := New(Config{DisableStdOut: true, SinkGroups: map[string]SinkGroup{"audit": {Outputs: []string{"audit.log"}}}})
+	if err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestSinks(t *testing.T) {
+	files := createTempFiles(t, "app.log", "access.log")
+	access := &bufferSyncer{}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		DisableColor:  true,
+		Sinks: map[string]SinkConfig{
+			"app":    {Path: files[0]},
+			"access": {Path: files[1], Exclusive: true},
+			"custom": {Output: access, Exclusive: true},
+		},
+		SinkGroups:  map[string]SinkGroup{"http": {Outputs: []string{"access", "custom"}}},
+		LoggerSinks: map[string][]string{"http": {"http"}},
+	})
+
+	log.Info("app")
+	log.Named("http.access").Info("request")
+	log.Named("http").To("app").Info("overridden")
+
+	checkFileLogs(t, files[0], [][]string{{"app"}, {"overridden"}})
+	checkFileLogs(t, files[1], [][]string{{"http.access", "request"}})
+	if !strings.Contains(string(access.Bytes()), "request") {
+		t.Errorf("want the request in the custom sink, got %q", access.Bytes())
+	}
+}
+
+func TestSinkEncoding(t *testing.T) {
+	console, plain, json := &bufferSyncer{}, &bufferSyncer{}, &bufferSyncer{}
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Sinks: map[string]SinkConfig{
+			"console": {Output: console},
+			"plain":   {Output: plain, DisableColor: true},
+			"json":    {Output: json, Encoding: EncodingJSON},
+		},
+	})
+	log.Info("hello")
+
+	if got := string(console.Bytes()); !strings.Contains(got, "\x1b[34mINFO\x1b[0m\t") {
+		t.Errorf("want colored console output, got %q", got)
+	}
+	if got := string(plain.Bytes()); !strings.Contains(got, "\tINFO\t") {
+		t.Errorf("want plain console output, got %q", got)
+	}
+	if got := string(json.Bytes()); !strings.Contains(got, `"msg":"hello"`) || strings.Contains(got, "\x1b") {
+		t.Errorf("want JSON output, got %q", got)
+	}
+}
+
+func TestInvalidSinks(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no path":        {Sinks: map[string]SinkConfig{"app": {}}},
+		"unknown bound":  {Sinks: map[string]SinkConfig{"app": {Output: &bufferSyncer{}}}, LoggerSinks: map[string][]string{"db": {"db"}}},
+		"group and sink": {Sinks: map[string]SinkConfig{"app": {Output: &bufferSyncer{}}}, SinkGroups: map[string]SinkGroup{"app": {}}},
+		"sink encoding":  {Sinks: map[string]SinkConfig{"app": {Output: &bufferSyncer{}, Encoding: "xml"}}},
+	} {
+		cfg.DisableStdOut = true
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rpcServerErrorCodes are gRPC status codes of server-side failures, logged at Error level
+var rpcServerErrorCodes = map[string]bool{
+	"Unknown":          true,
+	"DeadlineExceeded": true,
+	"Unimplemented":    true,
+	"Internal":         true,
+	"Unavailable":      true,
+	"DataLoss":         true,
+}
+
+// RPCCall logs a single RPC. It's the building block of gRPC interceptors, see Logger.StartRPC
+type RPCCall struct {
+	log   *Logger
+	start time.Time
+	// parent is the logger StartRPC was called on, its access logger is built once and reused by the calls
+	parent *Logger
+	fields []zap.Field
+}
+
+// StartRPC returns a context with the RPC logger (see FromContext) with the method, peer and deadline fields.
+// The gRPC interceptors of the github.com/kiteggrad/logger/loggergrpc module are built on it
+func (l *Logger) StartRPC(ctx context.Context, method, peer string) (context.Context, *RPCCall) {
+	fields := []zap.Field{zap.String("rpc.method", method)}
+	if peer != "" {
+		fields = append(fields, zap.String("rpc.peer", peer))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		fields = append(fields, zap.Time("rpc.deadline", deadline))
+	}
+	call := &RPCCall{log: l.withFields(fields...), start: time.Now(), parent: l, fields: fields}
+	return ToContext(ctx, call.log), call
+}
+
+// Finish logs the RPC completion with the status code name (e.g. "OK", "NotFound") and latency.
+// Server-side failures (Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable, DataLoss)
+// are logged at Error level, other codes at Info level
+func (c *RPCCall) Finish(code string, err error) {
+	lvl := InfoLevel
+	if rpcServerErrorCodes[code] {
+		lvl = ErrorLevel
+	}
+
+	ce := c.parent.checkAccess(lvl, "rpc completed")
+	if ce == nil {
+		return
+	}
+	fields := append(c.fields[:len(c.fields):len(c.fields)],
+		zap.String("rpc.code", code),
+		zap.Duration("latency", time.Since(c.start)),
+	)
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(fields...)
+}
+
+// Recover recovers a panic of the RPC handler, logs it and sets the error returned by panicErr.
+// It must be deferred directly:
+//
+//	defer call.Recover(&err, func(interface{}) error { return status.Error(codes.Internal, "internal error") })
+func (c *RPCCall) Recover(err *error, panicErr func(v interface{}) error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	c.log.logRecovered(v, 0)
+	*err = panicErr(v)
+}
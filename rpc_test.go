@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// callRPC imitates a unary server interceptor around the handler
+func callRPC(ctx context.Context, log *Logger, handler func(ctx context.Context) error) (err error) {
+	ctx, call := log.StartRPC(ctx, "/users.Users/Get", "10.0.0.1:5000")
+	defer func() {
+		code := "OK"
+		if err != nil {
+			code = "Internal"
+		}
+		call.Finish(code, err)
+	}()
+	defer call.Recover(&err, func(v interface{}) error { return errors.Errorf("internal error: %v", v) })
+	return handler(ctx)
+}
+
+func TestStartRPC(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := callRPC(ctx, log, func(ctx context.Context) error {
+		FromContext(ctx).Info("handling")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err := callRPC(context.Background(), log, func(ctx context.Context) error { panic("boom") })
+	if err == nil || err.Error() != "internal error: boom" {
+		t.Errorf("want the panic error, got %v", err)
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 4 {
+		t.Fatalf("want 4 entries, got %+v", entries)
+	}
+	if fields := entries[0].ContextMap(); entries[0].Message != "handling" || fields["rpc.method"] != "/users.Users/Get" ||
+		fields["rpc.peer"] != "10.0.0.1:5000" || fields["rpc.deadline"] == nil {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+	if e := entries[1]; e.Message != "rpc completed" || e.Level != zapcore.InfoLevel || e.ContextMap()["rpc.code"] != "OK" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[2]; e.Message != "recovered from panic" || !strings.HasSuffix(e.Caller.File, "rpc_test.go") {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[3]; e.Level != zapcore.ErrorLevel || e.ContextMap()["rpc.code"] != "Internal" || e.ContextMap()["error"] != "internal error: boom" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSamplingTick = time.Second
+	// maxSampleKeys is the number of counted keys. All the counters are reset once it's reached,
+	// so memory stays bounded regardless of the key cardinality
+	maxSampleKeys = 4096
+	sampleLevels  = int(zapcore.FatalLevel-zapcore.DebugLevel) + 1
+)
+
+// SamplingConfig throttles repetitive entries. During every Tick the First entries with the same level
+// and sampling key are logged, then only every Thereafter-th of them.
+// Entries above Error level are never sampled
+type SamplingConfig struct {
+	// Tick is the sampling interval. Defaults to 1s
+	Tick time.Duration
+	// First is the number of entries logged per key every tick. Sampling is disabled if it's 0
+	First int
+	// Thereafter makes every Thereafter-th entry logged after the First ones. All of them are dropped if it's 0
+	Thereafter int
+	// Key is the field to sample by (e.g. "user_id" or "endpoint"), so a noisy value is throttled
+	// while rare ones stay fully logged. Entries without the field and all the entries
+	// if Key is empty are sampled by message.
+	// Every distinct value has its own counter, up to 4096 values. Once there are more, all the counters are reset,
+	// so a high-cardinality key lets more entries through instead of suppressing unrelated values
+	Key string
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// inc increments the counter, resetting it at the beginning of a new tick
+func (c *sampleCounter) inc(now time.Time, tick time.Duration) uint64 {
+	nanos := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > nanos {
+		return c.count.Inc()
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CAS(resetAt, nanos+tick.Nanoseconds()) {
+		return c.count.Inc()
+	}
+	return 1
+}
+
+// sampleCounters are the counters of sampling keys per level
+type sampleCounters struct {
+	mu     sync.RWMutex
+	levels [sampleLevels]map[string]*sampleCounter
+	size   int
+}
+
+func (cs *sampleCounters) get(lvl zapcore.Level, key string) *sampleCounter {
+	i := lvl - zapcore.DebugLevel
+	cs.mu.RLock()
+	counter, ok := cs.levels[i][key]
+	cs.mu.RUnlock()
+	if ok {
+		return counter
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if counter, ok := cs.levels[i][key]; ok {
+		return counter
+	}
+	if cs.size >= maxSampleKeys {
+		cs.levels, cs.size = [sampleLevels]map[string]*sampleCounter{}, 0
+	}
+	if cs.levels[i] == nil {
+		cs.levels[i] = map[string]*sampleCounter{}
+	}
+	counter = &sampleCounter{}
+	cs.levels[i][key] = counter
+	cs.size++
+	return counter
+}
+
+// samplingCore drops entries exceeding the SamplingConfig limits.
+// When the key field isn't among the logger fields, it can be among the entry fields,
+// which aren't passed to Check, so the decision is deferred to Write
+type samplingCore struct {
+	core     zapcore.Core
+	cfg      SamplingConfig
+	counters *sampleCounters
+	key      string // value of the key field from the logger fields
+	hasKey   bool
+}
+
+func newSamplingCore(core zapcore.Core, cfg SamplingConfig) *samplingCore {
+	if cfg.Tick <= 0 {
+		cfg.Tick = defaultSamplingTick
+	}
+	return &samplingCore{core: core, cfg: cfg, counters: &sampleCounters{}}
+}
+
+func (c *samplingCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.core = c.core.With(fields)
+	if key, ok := c.findKey(fields); ok {
+		clone.key, clone.hasKey = key, true
+	}
+	return &clone
+}
+
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level > zapcore.ErrorLevel {
+		return c.core.Check(ent, ce)
+	}
+	if !c.hasKey && c.cfg.Key != "" {
+		if c.core.Enabled(ent.Level) {
+			return ce.AddCore(ent, c)
+		}
+		return ce
+	}
+
+	if !c.sample(ent, c.key, c.hasKey) {
+		return ce
+	}
+	return c.core.Check(ent, ce)
+}
+
+// Write is called only for entries deferred by Check
+func (c *samplingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	key, ok := c.findKey(fields)
+	if !c.sample(ent, key, ok) {
+		return nil
+	}
+
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *samplingCore) Sync() error {
+	return c.core.Sync()
+}
+
+// sample reports whether the entry should be logged
+func (c *samplingCore) sample(ent zapcore.Entry, key string, hasKey bool) bool {
+	if hasKey {
+		key = "k" + key
+	} else {
+		key = "m" + ent.Message
+	}
+
+	n := c.counters.get(ent.Level, key).inc(ent.Time, c.cfg.Tick)
+	if n <= uint64(c.cfg.First) {
+		return true
+	}
+	return c.cfg.Thereafter > 0 && (n-uint64(c.cfg.First))%uint64(c.cfg.Thereafter) == 0
+}
+
+// findKey returns the string value of the key field
+func (c *samplingCore) findKey(fields []zapcore.Field) (string, bool) {
+	if c.cfg.Key == "" {
+		return "", false
+	}
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key != c.cfg.Key {
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(enc)
+		return fmt.Sprint(enc.Fields[c.cfg.Key]), true
+	}
+	return "", false
+}
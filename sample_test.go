@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSamplingByKey(t *testing.T) {
+	log, err := New(Config{DisableStdOut: true, Observe: true, Sampling: SamplingConfig{Tick: time.Hour, First: 2, Thereafter: 3, Key: "user_id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		log.WithField("user_id", 1).Errorf("noisy %d", i)
+	}
+	log.ErrorFields("rare", zap.Int("user_id", 2))
+	log.WithField("user_id", 3).Error("rare")
+	for i := 0; i < 3; i++ {
+		log.Info("no key")
+	}
+
+	// 2 first + 5th and 8th
+	if n := log.ObservedLogs().FilterField(zap.Int("user_id", 1)).Len(); n != 4 {
+		t.Errorf("want 4 noisy entries, got %d", n)
+	}
+	if n := log.ObservedLogs().FilterMessage("rare").Len(); n != 2 {
+		t.Errorf("want 2 rare entries, got %d", n)
+	}
+	if n := log.ObservedLogs().FilterMessage("no key").Len(); n != 2 {
+		t.Errorf("want 2 entries without key, got %d", n)
+	}
+}
+
+func TestSamplingTick(t *testing.T) {
+	core := newSamplingCore(nil, SamplingConfig{Tick: time.Second, First: 1})
+	now := time.Now()
+
+	counter := core.counters.get(InfoLevel, "k")
+	if n := counter.inc(now, time.Second); n != 1 {
+		t.Errorf("want 1, got %d", n)
+	}
+	if n := counter.inc(now, time.Second); n != 2 {
+		t.Errorf("want 2, got %d", n)
+	}
+	if n := counter.inc(now.Add(time.Second), time.Second); n != 1 {
+		t.Errorf("want reset, got %d", n)
+	}
+}
+
+func TestSamplingKeysDontCollide(t *testing.T) {
+	core := newSamplingCore(nil, SamplingConfig{First: 1})
+	now := time.Now()
+
+	for i := 0; i < maxSampleKeys; i++ {
+		if n := core.counters.get(InfoLevel, strconv.Itoa(i)).inc(now, time.Second); n != 1 {
+			t.Fatalf("want a counter per key, got %d entries of key %d", n, i)
+		}
+	}
+	if n := core.counters.get(InfoLevel, "0").inc(now, time.Second); n != 2 {
+		t.Errorf("want 2, got %d", n)
+	}
+
+	// The counters are reset once there are too many keys
+	core.counters.get(InfoLevel, "new")
+	if n := core.counters.get(InfoLevel, "0").inc(now, time.Second); n != 1 {
+		t.Errorf("want reset counter, got %d", n)
+	}
+}
@@ -0,0 +1,305 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// sanitize escapes control characters, so user input can't forge log lines or inject terminal escape sequences
+// (CWE-117): "\n" and "\r" become `\n` and `\r`, other control characters (including ESC of ANSI sequences,
+// C1 controls and Unicode line separators) become `\xNN` or `\uNNNN`. Tabs are kept
+func sanitize(s string) string {
+	return escapeRunes(s, unsafeRune)
+}
+
+// sanitizeJSONString escapes the control characters the JSON encoder writes as is: DEL, C1 controls and
+// Unicode line separators. The others are escaped by the encoder, so escaping them here would double the backslashes
+func sanitizeJSONString(s string) string {
+	return escapeRunes(s, jsonUnsafeRune)
+}
+
+// escapeRunes escapes the unsafe runes of s like sanitize
+func escapeRunes(s string, unsafe func(r rune) bool) string {
+	i := strings.IndexFunc(s, unsafe)
+	if i < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		switch {
+		case !unsafe(r):
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r <= 0xff:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return b.String()
+}
+
+func unsafeRune(r rune) bool {
+	return r < 0x20 && r != '\t' || jsonUnsafeRune(r)
+}
+
+func jsonUnsafeRune(r rune) bool {
+	return r >= 0x7f && r <= 0x9f || r == '\u2028' || r == '\u2029'
+}
+
+// sanitizeCore escapes control characters in messages, logger names and field values, see Config.Sanitize.
+// Messages and fields written only by encoders escaping strings as JSON are escaped by sanitizeJSONString
+type sanitizeCore struct {
+	core zapcore.Core
+	// messages escapes messages and logger names, fields escapes string values of fields, errors and objects
+	messages, fields func(string) string
+}
+
+// newSanitizeCore returns a sanitizing core for the encodings of the config outputs.
+// Messages and logger names are written as is by the console and pretty encodings, while field strings
+// are escaped as JSON or quoted by all the encodings. Cores of Config.Cores are expected to write them as is
+func newSanitizeCore(core zapcore.Core, cfg Config) *sanitizeCore {
+	encodings := []Encoding{cfg.Encoding.orDefault()}
+	for _, sink := range cfg.Sinks {
+		if sink.Encoding != "" {
+			encodings = append(encodings, sink.Encoding)
+		}
+	}
+	c := &sanitizeCore{core: core, messages: sanitizeJSONString, fields: sanitizeJSONString}
+	for _, enc := range encodings {
+		if enc == EncodingConsole || enc == EncodingPretty {
+			c.messages = sanitize
+		}
+	}
+	if len(cfg.Cores) != 0 {
+		c.messages, c.fields = sanitize, sanitize
+	}
+	return c
+}
+
+func (c *sanitizeCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *sanitizeCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.core = c.core.With(sanitizeFields(fields, c.fields))
+	return &clone
+}
+
+func (c *sanitizeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sanitizeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.messages(ent.Message)
+	ent.LoggerName = c.messages(ent.LoggerName)
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(sanitizeFields(fields, c.fields)...)
+	}
+	return nil
+}
+
+func (c *sanitizeCore) Sync() error {
+	return c.core.Sync()
+}
+
+// sanitizeFields returns the fields with string values escaped, copying them only if needed.
+// Errors are replaced with sanitizedError values, and objects, arrays and reflected values
+// are replaced with their encoded form if any of their strings is escaped
+func sanitizeFields(fields []zapcore.Field, escape func(string) string) []zapcore.Field {
+	result, copied := fields, false
+	replace := func(i int, f zapcore.Field) {
+		if !copied {
+			result, copied = append([]zapcore.Field(nil), fields...), true
+		}
+		result[i] = f
+	}
+	for i, f := range fields {
+		var value string
+		switch f.Type {
+		case zapcore.StringType:
+			value = f.String
+		case zapcore.ByteStringType:
+			b, _ := f.Interface.([]byte)
+			if !utf8.Valid(b) {
+				continue
+			}
+			value = string(b)
+		case zapcore.StringerType:
+			value = fmt.Sprint(f.Interface)
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok {
+				if sanitized, changed := sanitizeError(err, escape); changed {
+					f.Interface = sanitized
+					replace(i, f)
+				}
+			}
+			continue
+		case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType:
+			if v, changed := sanitizeEncoded(f, escape); changed {
+				replace(i, zap.Any(f.Key, v))
+			}
+			continue
+		default:
+			continue
+		}
+		sanitized := escape(value)
+		// Stringers are converted anyway, so they're evaluated once
+		if sanitized == value && f.Type != zapcore.StringerType {
+			continue
+		}
+		replace(i, zap.String(f.Key, sanitized))
+	}
+	return result
+}
+
+// sanitizedError is an error with the escaped message and verbose form (see fmt.Formatter) of the original one
+type sanitizedError struct {
+	msg, verbose string
+}
+
+func (e sanitizedError) Error() string { return e.msg }
+
+func (e sanitizedError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.verbose)
+		return
+	}
+	_, _ = io.WriteString(s, e.msg)
+}
+
+// sanitizedErrorGroup is a sanitizedError keeping the escaped causes of a multierr error
+type sanitizedErrorGroup struct {
+	sanitizedError
+	causes []error
+}
+
+func (e sanitizedErrorGroup) Errors() []error { return e.causes }
+
+// sanitizeError returns the error with escaped strings if any of them is changed
+func sanitizeError(err error, escape func(string) string) (error, bool) {
+	// zap encodes nil pointers as "<nil>" only if they aren't wrapped
+	if v := reflect.ValueOf(err); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return err, false
+	}
+	msg := err.Error()
+	verbose := msg
+	if _, ok := err.(fmt.Formatter); ok {
+		verbose = fmt.Sprintf("%+v", err)
+	}
+	sanitized := sanitizedError{msg: escape(msg), verbose: escape(verbose)}
+	changed := sanitized.msg != msg || sanitized.verbose != verbose
+
+	group, ok := err.(interface{ Errors() []error })
+	if !ok {
+		if !changed {
+			return err, false
+		}
+		return sanitized, true
+	}
+	causes := group.Errors()
+	sanitizedGroup := sanitizedErrorGroup{sanitizedError: sanitized, causes: make([]error, len(causes))}
+	for i, cause := range causes {
+		var causeChanged bool
+		sanitizedGroup.causes[i], causeChanged = sanitizeError(cause, escape)
+		changed = changed || causeChanged
+	}
+	if !changed {
+		return err, false
+	}
+	return sanitizedGroup, true
+}
+
+// sanitizeEncoded encodes the field value like the JSON encoder and escapes its strings,
+// reporting whether any of them is changed. Values failing to encode are kept
+func sanitizeEncoded(f zapcore.Field, escape func(string) string) (interface{}, bool) {
+	var v interface{}
+	if f.Type == zapcore.ReflectType {
+		v = f.Interface
+	} else {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		var ok bool
+		if v, ok = enc.Fields[f.Key]; !ok {
+			return nil, false
+		}
+	}
+	return sanitizeValue(v, escape)
+}
+
+// sanitizeValue escapes the strings of the encoded value. Values other than strings, maps and slices
+// of the map encoder are encoded to JSON first, e.g. structs of reflected fields
+func sanitizeValue(v interface{}, escape func(string) string) (interface{}, bool) {
+	switch v := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128, json.Number, time.Time, time.Duration:
+		return v, false
+	case string:
+		s := escape(v)
+		return s, s != v
+	case map[string]interface{}:
+		result, changed := v, false
+		for key, value := range v {
+			if sanitized, ok := sanitizeValue(value, escape); ok {
+				if !changed {
+					result, changed = make(map[string]interface{}, len(v)), true
+					for k, value := range v {
+						result[k] = value
+					}
+				}
+				result[key] = sanitized
+			}
+		}
+		return result, changed
+	case []interface{}:
+		result, changed := v, false
+		for i, value := range v {
+			if sanitized, ok := sanitizeValue(value, escape); ok {
+				if !changed {
+					result, changed = append([]interface{}(nil), v...), true
+				}
+				result[i] = sanitized
+			}
+		}
+		return result, changed
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return v, false
+	}
+	if _, ok := decoded.(string); !ok {
+		if _, ok := decoded.(map[string]interface{}); !ok {
+			if _, ok := decoded.([]interface{}); !ok {
+				return v, false
+			}
+		}
+	}
+	return sanitizeValue(decoded, escape)
+}
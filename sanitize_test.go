@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSanitize(t *testing.T) {
+	for in, want := range map[string]string{
+		"plain\ttext":                   "plain\ttext",
+		"user\n2024-01-01 INFO admin":   `user\n2024-01-01 INFO admin`,
+		"a\r\nb":                        `a\r\nb`,
+		"\x1b[31mred\x1b[0m":            `\x1b[31mred\x1b[0m`,
+		"csi\u009b2J line\u2028sep\x7f": `csi\x9b2J line\u2028sep\x7f`,
+		"юникод":                        "юникод",
+	} {
+		if got := sanitize(in); got != want {
+			t.Errorf("%q: want %s, got %s", in, want, got)
+		}
+	}
+}
+
+func TestSanitizeCore(t *testing.T) {
+	filename := createTempFiles(t, "1.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Sanitize: true, Files: []string{filename}})
+
+	log.WithField("user", "bob\nINFO forged").InfoFields("login\x1b[2J",
+		zap.ByteString("raw", []byte("a\rb")),
+		zap.Stringer("addr", stringer("10.0.0.1\n")),
+	)
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := readFile(t, filename)
+	if n := bytes.Count(out, []byte("\n")); n != 1 {
+		t.Fatalf("want a single line, got %d: %s", n, out)
+	}
+	// Fields of the console encoding are escaped by the JSON encoder
+	for _, want := range []string{`login\x1b[2J`, `"user": "bob\nINFO forged"`, `"raw": "a\rb"`, `"addr": "10.0.0.1\n"`} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("want %s in %s", want, out)
+		}
+	}
+}
+
+func TestSanitizePretty(t *testing.T) {
+	out := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, Encoding: EncodingPretty, Sanitize: true, Outputs: []zapcore.WriteSyncer{out}})
+
+	log.Named("api\nINFO").ErrorFields("failed",
+		zap.Error(errors.New("bad\x1b[2J input")),
+		zap.Object("user", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("name", "bob\x07")
+			return nil
+		})),
+		zap.Reflect("tags", []string{"a\x1bb"}),
+	)
+
+	got := string(out.Bytes())
+	if strings.Count(got, "\n") != 1 || strings.Contains(got, "\x1b") || strings.Contains(got, "\x07") {
+		t.Errorf("want escaped control characters, got %q", got)
+	}
+	// Field values are quoted or encoded as JSON by the encoder
+	for _, want := range []string{`api\nINFO`, `"bad\x1b[2J input"`, `"bob\u0007"`, `"a\u001bb"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in %s", want, got)
+		}
+	}
+}
+
+func TestSanitizeJSON(t *testing.T) {
+	out := &bufferSyncer{}
+	log := newLogger(t, Config{DisableStdOut: true, Encoding: EncodingJSON, Sanitize: true, Outputs: []zapcore.WriteSyncer{out}})
+
+	log.InfoFields("line\nbreak", zap.String("user", "bob\nINFO\u2028"), zap.Error(errors.New("bad\ninput")),
+		zap.Reflect("tags", map[string][]string{"names": {"a\u0085b"}}),
+	)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := entry["tags"].(map[string]interface{})
+	if entry["msg"] != "line\nbreak" || entry["user"] != "bob\nINFO\\u2028" || entry["error"] != "bad\ninput" ||
+		!reflect.DeepEqual(tags["names"], []interface{}{`a\x85b`}) {
+		t.Errorf("want strings escaped once, got %+v", entry)
+	}
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// SchemaConfig validates fields of entries against a JSON schema, preventing schema drift
+// before logs hit production pipelines. It's intended for development and tests, since it costs an extra encoding per entry.
+//
+// A subset of JSON schema is supported:
+//
+//	{
+//		"required": ["request_id"],
+//		"properties": {"request_id": {"type": "string"}, "status": {"type": "integer", "enum": [200, 500]}},
+//		"additionalProperties": false
+//	}
+type SchemaConfig struct {
+	// Schema is the JSON schema of entry fields. Validation is disabled if it's empty
+	Schema json.RawMessage
+	// Fail panics on violations, failing tests. Otherwise the entry is written and the violations are reported to stderr
+	Fail bool
+}
+
+type fieldSchema struct {
+	Type       interface{}             `json:"type"` // a type name or a list of them
+	Enum       []interface{}           `json:"enum"`
+	Properties map[string]*fieldSchema `json:"properties"`
+	Required   []string                `json:"required"`
+	Additional *bool                   `json:"additionalProperties"`
+}
+
+func parseSchema(data []byte) (*fieldSchema, error) {
+	var schema fieldSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, errors.Wrap(err, "failed to parse schema")
+	}
+	return &schema, nil
+}
+
+// validate appends violations of the value at the path
+func (s *fieldSchema) validate(path string, value interface{}, violations []string) []string {
+	if types := s.types(); len(types) != 0 {
+		actual := jsonType(value)
+		matched := false
+		for _, typ := range types {
+			matched = matched || typ == actual || (typ == "number" && actual == "integer")
+		}
+		if !matched {
+			return append(violations, fmt.Sprintf("%s: want %s, got %s", path, strings.Join(types, " or "), actual))
+		}
+	}
+
+	if len(s.Enum) != 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			found = found || fmt.Sprint(allowed) == fmt.Sprint(value)
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: %v isn't one of %v", path, value, s.Enum))
+		}
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return violations
+	}
+	for _, key := range s.Required {
+		if _, ok := object[key]; !ok {
+			violations = append(violations, fmt.Sprintf("%s: missing required field", joinPath(path, key)))
+		}
+	}
+	for _, key := range sortedKeys(object) {
+		if prop, ok := s.Properties[key]; ok {
+			violations = prop.validate(joinPath(path, key), object[key], violations)
+		} else if s.Additional != nil && !*s.Additional {
+			violations = append(violations, fmt.Sprintf("%s: unexpected field", joinPath(path, key)))
+		}
+	}
+	return violations
+}
+
+func (s *fieldSchema) types() []string {
+	switch typ := s.Type.(type) {
+	case string:
+		return []string{typ}
+	case []interface{}:
+		types := make([]string, 0, len(typ))
+		for _, t := range typ {
+			types = append(types, fmt.Sprint(t))
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonType returns the JSON schema type of a value decoded with json.Decoder.UseNumber
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaCore validates fields of entries. Entry fields aren't passed to Check, so the validation is deferred to Write
+type schemaCore struct {
+	core   zapcore.Core
+	schema *fieldSchema
+	fail   bool
+	fields []zapcore.Field
+}
+
+func (c *schemaCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *schemaCore) With(fields []zapcore.Field) zapcore.Core {
+	return &schemaCore{
+		core:   c.core.With(fields),
+		schema: c.schema,
+		fail:   c.fail,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *schemaCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry even if it doesn't match the schema or can't be validated,
+// reporting the violations to stderr, so they aren't filtered out by the level
+func (c *schemaCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	violations, err := c.violations(fields)
+	if len(violations) != 0 && c.fail {
+		panic(fmt.Sprintf("entry %q doesn't match the schema: %s", ent.Message, strings.Join(violations, "; ")))
+	}
+
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(fields...)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintf(stderr, "%v failed to validate entry %q: %v\n", time.Now().UTC(), ent.Message, err)
+	case len(violations) != 0:
+		fmt.Fprintf(stderr, "%v entry %q doesn't match the schema: %s\n", time.Now().UTC(), ent.Message, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// violations validates the logger and entry fields in their JSON form
+func (c *schemaCore) violations(fields []zapcore.Field) ([]string, error) {
+	data, err := json.Marshal(fieldsMap(c.fields, fields))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to json.Marshal fields")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var object map[string]interface{}
+	if err := dec.Decode(&object); err != nil {
+		return nil, errors.Wrap(err, "failed to json.Decode fields")
+	}
+
+	violations := c.schema.validate("", object, nil)
+	sort.Strings(violations)
+	return violations, nil
+}
+
+func (c *schemaCore) Sync() error {
+	return c.core.Sync()
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"required": ["request_id"],
+	"properties": {
+		"request_id": {"type": "string"},
+		"status": {"type": "integer", "enum": [200, 500]},
+		"user": {"type": "object", "required": ["id"]}
+	},
+	"additionalProperties": false
+}`
+
+// captureStderr redirects the error output of the cores to the returned buffer until the test finishes
+func captureStderr(t *testing.T) *bufferSyncer {
+	buf := &bufferSyncer{}
+	prev := stderr
+	stderr = buf
+	t.Cleanup(func() { stderr = prev })
+	return buf
+}
+
+func TestSchema(t *testing.T) {
+	errOut := captureStderr(t)
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Schema: SchemaConfig{Schema: []byte(testSchema)}})
+	log.SetLevel("error")
+
+	log.WithField("request_id", "1").WithField("status", 200).Error("valid")
+	log.WithFields(map[string]interface{}{"status": 404, "user": map[string]interface{}{}, "extra": true}).Error("invalid")
+
+	if n := log.ObservedLogs().Len(); n != 2 {
+		t.Fatalf("want 2 entries, got %d", n)
+	}
+	want := `entry "invalid" doesn't match the schema: ` +
+		"extra: unexpected field; request_id: missing required field; status: 404 isn't one of [200 500]; user.id: missing required field"
+	if got := string(errOut.Bytes()); !strings.Contains(got, want) {
+		t.Errorf("want %q reported, got %q", want, got)
+	}
+}
+
+func TestSchemaInvalidValue(t *testing.T) {
+	errOut := captureStderr(t)
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Schema: SchemaConfig{Schema: []byte(testSchema)}})
+
+	log.WithField("request_id", "1").WithField("ratio", math.NaN()).Info("not encodable")
+
+	if n := log.ObservedLogs().Len(); n != 1 {
+		t.Fatalf("want the entry written, got %d entries", n)
+	}
+	if got := string(errOut.Bytes()); !strings.Contains(got, `failed to validate entry "not encodable"`) {
+		t.Errorf("want the validation error reported, got %q", got)
+	}
+}
+
+func TestSchemaFail(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true, Schema: SchemaConfig{Schema: []byte(testSchema), Fail: true}})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic")
+		}
+	}()
+	log.WithField("request_id", 1).Info("invalid")
+}
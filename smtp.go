@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// SMTPConfig configures NewSMTPCore
+type SMTPConfig struct {
+	// Addr is the "host:port" of the SMTP server
+	Addr string
+	// Username and Password enable PLAIN authentication
+	Username string
+	Password string
+	// From is the sender address
+	From string
+	// To are the recipient addresses
+	To []string
+	// Subject is the digest subject. "{count}" is replaced with the number of entries.
+	// Defaults to "{count} log alerts"
+	Subject string
+	// Template is a digest line of an entry. "{key}" placeholders are replaced with entry field values,
+	// "{level}", "{logger}" and "{msg}" with the entry level, logger name and message.
+	// The JSON-encoded entry is written by default
+	Template string
+	// Interval is the digest interval. Entries logged during it are sent in a single email. Defaults to 5m
+	Interval time.Duration
+	// MaxEntries limits the number of entries in a digest, the rest are only counted. Defaults to 100
+	MaxEntries int
+	// Level is the minimum level of sent entries. Defaults to Error
+	Level zapcore.LevelEnabler
+	// Transport configures the connection. With TLS enabled the connection is TLS from the start (SMTPS),
+	// otherwise STARTTLS is used if the server supports it
+	Transport SinkTransportConfig
+}
+
+func (c SMTPConfig) subject() string {
+	if c.Subject == "" {
+		return "{count} log alerts"
+	}
+	return c.Subject
+}
+
+func (c SMTPConfig) interval() time.Duration {
+	if c.Interval <= 0 {
+		return 5 * time.Minute
+	}
+	return c.Interval
+}
+
+func (c SMTPConfig) maxEntries() int {
+	if c.MaxEntries <= 0 {
+		return 100
+	}
+	return c.MaxEntries
+}
+
+// smtpDigest collects entries and sends them by email periodically
+type smtpDigest struct {
+	cfg  SMTPConfig
+	host string
+	stop func() error
+
+	sending sync.Mutex
+	mu      sync.Mutex
+	lines   []string
+	skipped int
+}
+
+// smtpCore is a publishCore collecting entries into an SMTP digest
+type smtpCore struct {
+	*publishCore
+	digest *smtpDigest
+}
+
+// NewSMTPCore creates a core sending entries by email in digests, for deployments without an alerting stack.
+// Add it with Config.Cores, the last digest is sent by Logger.Shutdown
+func NewSMTPCore(cfg SMTPConfig) (zapcore.Core, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SMTP address")
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("SMTP alerts require From and To")
+	}
+
+	level := cfg.Level
+	if level == nil {
+		level = zapcore.ErrorLevel
+	}
+	d := &smtpDigest{cfg: cfg, host: host}
+	d.stop = runEvery(cfg.interval(), func() {
+		if err := d.flush(); err != nil {
+			fmt.Fprintf(stderr, "%v failed to send SMTP digest: %v\n", time.Now().UTC(), err)
+		}
+	})
+	return &smtpCore{publishCore: newPublishCore(level, d.add), digest: d}, nil
+}
+
+// Close stops the digest timer and sends the collected entries
+func (c *smtpCore) Close() error {
+	_ = c.digest.stop()
+	return c.digest.flush()
+}
+
+func (d *smtpDigest) add(e publishedEntry) error {
+	line := strings.TrimSuffix(string(e.Payload), "\n")
+	if d.cfg.Template != "" {
+		line = renderTemplate(d.cfg.Template, e, func(s string) string { return s })
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.lines) >= d.cfg.maxEntries() {
+		d.skipped++
+		return nil
+	}
+	d.lines = append(d.lines, line)
+	return nil
+}
+
+// flush sends the collected entries. They're kept for the next attempt if sending fails
+func (d *smtpDigest) flush() error {
+	d.sending.Lock()
+	defer d.sending.Unlock()
+
+	d.mu.Lock()
+	lines, skipped := d.lines, d.skipped
+	d.lines, d.skipped = nil, 0
+	d.mu.Unlock()
+
+	if len(lines) == 0 {
+		return nil
+	}
+	err := d.send(smtpMessage(d.cfg, lines, skipped))
+	if err != nil {
+		d.mu.Lock()
+		d.lines = append(lines, d.lines...)
+		d.skipped += skipped
+		if limit := d.cfg.maxEntries(); len(d.lines) > limit {
+			d.skipped += len(d.lines) - limit
+			d.lines = d.lines[:limit]
+		}
+		d.mu.Unlock()
+	}
+	return err
+}
+
+func smtpMessage(cfg SMTPConfig, lines []string, skipped int) []byte {
+	count := len(lines) + skipped
+	subject := strings.ReplaceAll(cfg.subject(), "{count}", strconv.Itoa(count))
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "\r\n%d more entries skipped\r\n", skipped)
+	}
+	return b.Bytes()
+}
+
+func (d *smtpDigest) send(msg []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Transport.timeout())
+	defer cancel()
+
+	conn, err := d.cfg.Transport.Dial(ctx, "tcp", d.cfg.Addr)
+	if err != nil {
+		return errors.Wrap(err, "failed to dial")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, d.host)
+	if err != nil {
+		_ = conn.Close()
+		return errors.Wrap(err, "failed to smtp.NewClient")
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !d.cfg.Transport.tlsEnabled() {
+		tlsCfg, err := d.cfg.Transport.TLSConfig()
+		if err != nil {
+			return err
+		}
+		tlsCfg.ServerName = d.host
+		if err := client.StartTLS(tlsCfg); err != nil {
+			return errors.Wrap(err, "failed to STARTTLS")
+		}
+	}
+	if d.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", d.cfg.Username, d.cfg.Password, d.host)); err != nil {
+			return errors.Wrap(err, "failed to authenticate")
+		}
+	}
+
+	if err := client.Mail(d.cfg.From); err != nil {
+		return errors.Wrap(err, "failed to set sender")
+	}
+	for _, to := range d.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return errors.Wrapf(err, "failed to add recipient %s", to)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "failed to start data")
+	}
+	if _, err := w.Write(msg); err != nil {
+		return errors.Wrap(err, "failed to write message")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "failed to send message")
+	}
+	return client.Quit()
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// serveSMTP accepts a single SMTP session and sends the received message to the channel
+func serveSMTP(t *testing.T, ln net.Listener, messages chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	reply := func(line string) {
+		if err := tp.PrintfLine("%s", line); err != nil {
+			t.Error(err)
+		}
+	}
+	reply("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO":
+			reply("250 localhost")
+		case "DATA":
+			reply("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			messages <- string(data)
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPCore(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	messages := make(chan string, 1)
+	go serveSMTP(t, ln, messages)
+
+	core, err := NewSMTPCore(SMTPConfig{
+		Addr:       ln.Addr().String(),
+		From:       "app@example.com",
+		To:         []string{"ops@example.com"},
+		Template:   "[{level}] {msg} (user {user})",
+		MaxEntries: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{core}})
+
+	log.Warn("skipped")
+	log.WithField("user", 1).Error("failed 1")
+	log.Error("failed 2")
+	log.Error("failed 3")
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-messages
+	for _, want := range []string{
+		"Subject: 3 log alerts",
+		"To: ops@example.com",
+		"[error] failed 1 (user 1)\n[error] failed 2 (user _)\n\n1 more entries skipped",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("want %q in the message, got %s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "[warn]") || strings.Contains(msg, "failed 3") {
+		t.Errorf("unexpected entries in the message: %s", msg)
+	}
+}
+
+func TestSMTPCoreRetries(t *testing.T) {
+	d := &smtpDigest{cfg: SMTPConfig{Addr: "127.0.0.1:1", MaxEntries: 2}, host: "127.0.0.1"}
+	for _, line := range []string{"1", "2", "3"} {
+		if err := d.add(publishedEntry{Payload: []byte(line + "\n")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.flush(); err == nil {
+		t.Fatal("want an error")
+	}
+	if len(d.lines) != 2 || d.skipped != 1 {
+		t.Errorf("want the entries kept, got %v and %d skipped", d.lines, d.skipped)
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SpanRecorder records entries logged with a context (see Logger.Ctx and FromContext) on the active span
+// of the context, so traces embed the relevant log lines. An OpenTelemetry recorder:
+//
+//	logger.SpanRecorderFunc(func(ctx context.Context, e logger.Entry) {
+//		span := trace.SpanFromContext(ctx)
+//		if !span.IsRecording() {
+//			return
+//		}
+//		attrs := []attribute.KeyValue{attribute.String("level", e.Level.String())}
+//		for k, v := range e.Fields {
+//			attrs = append(attrs, attribute.String(k, fmt.Sprint(v)))
+//		}
+//		span.AddEvent(e.Message, trace.WithTimestamp(e.Time), trace.WithAttributes(attrs...))
+//		if e.Level >= logger.ErrorLevel {
+//			span.SetStatus(codes.Error, e.Message)
+//		}
+//	})
+type SpanRecorder interface {
+	RecordEntry(ctx context.Context, e Entry)
+}
+
+// SpanFinder is implemented by recorders able to tell whether the context has a span to record entries on,
+// e.g. trace.SpanFromContext(ctx).IsRecording() of OpenTelemetry. Logger.Ctx doesn't add contexts without spans
+// to loggers, so their entries aren't built for the recorder
+type SpanFinder interface {
+	HasSpan(ctx context.Context) bool
+}
+
+// hasSpan reports whether the recorder may record entries of the context
+func hasSpan(ctx context.Context, recorder SpanRecorder) bool {
+	finder, ok := recorder.(SpanFinder)
+	return !ok || finder.HasSpan(ctx)
+}
+
+// SpanRecorderFunc is a function implementing SpanRecorder
+type SpanRecorderFunc func(ctx context.Context, e Entry)
+
+func (f SpanRecorderFunc) RecordEntry(ctx context.Context, e Entry) { f(ctx, e) }
+
+// spanContext is a hidden field value carrying the context of a logger created with Logger.Ctx
+type spanContext struct {
+	ctx context.Context
+}
+
+func spanContextField(ctx context.Context) zap.Field {
+	return zap.Field{Key: "span_context", Type: zapcore.SkipType, Interface: spanContext{ctx: ctx}}
+}
+
+// spanCore passes entries of loggers with a context to the span recorder, see Config.SpanRecorder
+type spanCore struct {
+	core     zapcore.Core
+	recorder SpanRecorder
+	ctx      context.Context
+	fields   []zapcore.Field
+}
+
+// Enabled is true for loggers with a context, so entries are recorded even without outputs
+func (c *spanCore) Enabled(lvl zapcore.Level) bool {
+	return c.ctx != nil || c.core.Enabled(lvl)
+}
+
+func (c *spanCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	for _, f := range fields {
+		if sc, ok := f.Interface.(spanContext); ok && f.Type == zapcore.SkipType {
+			clone.ctx = sc.ctx
+		}
+	}
+	clone.core = c.core.With(fields)
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *spanCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.core.Check(ent, ce)
+	if c.ctx != nil {
+		ce = ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write is called only for entries of loggers with a context
+func (c *spanCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.recorder.RecordEntry(c.ctx, entryOf(ent, fieldsMap(c.fields, fields)))
+	return nil
+}
+
+func (c *spanCore) Sync() error {
+	return c.core.Sync()
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type spanKey struct{}
+
+// fakeSpan records events like a recording span
+type fakeSpan struct {
+	events []string
+	status string
+}
+
+func TestSpanRecorder(t *testing.T) {
+	recorder := SpanRecorderFunc(func(ctx context.Context, e Entry) {
+		span, ok := ctx.Value(spanKey{}).(*fakeSpan)
+		if !ok {
+			return
+		}
+		span.events = append(span.events, e.Message+" user="+toString(e.Fields["user"]))
+		if e.Level >= ErrorLevel {
+			span.status = e.Message
+		}
+	})
+	log := newLogger(t, Config{DisableStdOut: true, SpanRecorder: recorder})
+	log.SetLevel("info")
+
+	span := &fakeSpan{}
+	ctx := context.WithValue(context.Background(), spanKey{}, span)
+	log.Info("without context")
+	ctxLog := log.Ctx(ctx).WithField("user", "bob")
+	ctxLog.Debug("below the level")
+	ctxLog.Info("login")
+	ctxLog.WithError(errors.New("boom")).Error("failed")
+
+	if len(span.events) != 2 || span.events[0] != "login user=bob" || span.events[1] != "failed user=bob" {
+		t.Errorf("unexpected span events: %q", span.events)
+	}
+	if span.status != "failed" {
+		t.Errorf("want the error status, got %q", span.status)
+	}
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// findingRecorder records entries of contexts with a fakeSpan and reports the spans as SpanFinder
+type findingRecorder struct {
+	SpanRecorderFunc
+}
+
+func (findingRecorder) HasSpan(ctx context.Context) bool {
+	_, ok := ctx.Value(spanKey{}).(*fakeSpan)
+	return ok
+}
+
+func TestSpanContextAddedOnce(t *testing.T) {
+	recorder := findingRecorder{SpanRecorderFunc: func(ctx context.Context, e Entry) {
+		span := ctx.Value(spanKey{}).(*fakeSpan)
+		span.events = append(span.events, e.Message)
+	}}
+	log := newLogger(t, Config{DisableStdOut: true, SpanRecorder: recorder})
+
+	if l := log.Ctx(context.Background()); l != log {
+		t.Error("want the logger as is for a context without a span")
+	}
+
+	span := &fakeSpan{}
+	ctx := context.WithValue(context.Background(), spanKey{}, span)
+	ctxLog := log.Ctx(ctx)
+	if l := ctxLog.Ctx(ctx); l != ctxLog {
+		t.Error("want the logger as is for the context it already has")
+	}
+	if n := ctxLog.Ctx(ctx).described.len; n != 1 {
+		t.Errorf("want the span context added once, got %d fields", n)
+	}
+	ctxLog.Ctx(ctx).Info("login")
+	if len(span.events) != 1 || span.events[0] != "login" {
+		t.Errorf("unexpected span events: %q", span.events)
+	}
+}
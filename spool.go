@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+type SpoolConfig struct {
+	// Dir is a directory to keep undelivered entries in. It's created if it doesn't exist
+	Dir string
+	// RetryInterval is the interval between delivery attempts of spooled entries. Defaults to 10s
+	RetryInterval time.Duration
+	// MaxSize limits the spool file size in bytes. Entries exceeding it are dropped. Zero means no limit
+	MaxSize int64
+}
+
+// spoolFileName is the name of the spool file inside SpoolConfig.Dir
+const spoolFileName = "spool.dat"
+
+// spool is a zapcore.WriteSyncer persisting entries the output failed to write
+// and replaying them in the background once the output recovers.
+// Entries are delivered at least once, even across process restarts
+type spool struct {
+	ws  zapcore.WriteSyncer
+	cfg SpoolConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	offset int64 // the position of the first undelivered entry
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSpool wraps a (usually remote) output with a disk-backed retry queue.
+// Once an entry is spooled, the following ones are spooled too until the queue is replayed, keeping the order.
+// The spool file is fsynced by Sync and Close. A torn record at its end (e.g. after a crash during a write) is discarded on open.
+// The returned output implements io.Closer and is closed by Logger.Shutdown if passed in Config.Outputs
+func NewSpool(ws zapcore.WriteSyncer, cfg SpoolConfig) (zapcore.WriteSyncer, error) {
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 10 * time.Second
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create spool dir")
+	}
+	file, err := os.OpenFile(filepath.Join(cfg.Dir, spoolFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open spool file")
+	}
+	size, err := completeSize(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s := &spool{
+		ws:   ws,
+		cfg:  cfg,
+		file: file,
+		size: size,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size == 0 {
+		if n, err := s.ws.Write(p); err == nil {
+			return n, nil
+		}
+	}
+	if err := s.append(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync replays the spooled entries and syncs the output, or persists the spool file if the replay fails
+func (s *spool) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.replay(); err != nil {
+		return multierr.Append(err, s.syncFile())
+	}
+	return multierr.Append(s.syncFile(), s.ws.Sync())
+}
+
+func (s *spool) syncFile() error {
+	return errors.Wrap(s.file.Sync(), "failed to sync spool file")
+}
+
+// Close stops the background replay. Undelivered entries stay in the spool file
+func (s *spool) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return multierr.Append(s.syncFile(), s.file.Close())
+}
+
+func (s *spool) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			_ = s.replay()
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// append adds a length-prefixed entry to the end of the spool file
+func (s *spool) append(p []byte) error {
+	record := int64(4 + len(p))
+	if s.cfg.MaxSize > 0 && s.size+record > s.cfg.MaxSize {
+		return errors.New("spool is full")
+	}
+
+	buf := make([]byte, record)
+	binary.BigEndian.PutUint32(buf, uint32(len(p)))
+	copy(buf[4:], p)
+
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		return errors.Wrap(err, "failed to write spool file")
+	}
+	s.size += record
+	return nil
+}
+
+// replay writes spooled entries to the output until it fails.
+// The file is truncated once all the entries are delivered
+func (s *spool) replay() error {
+	if s.size == 0 {
+		return nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s.file, s.offset, s.size-s.offset))
+	header := make([]byte, 4)
+	for s.offset < s.size {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return errors.Wrap(err, "failed to read spool file")
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return errors.Wrap(err, "failed to read spool file")
+		}
+
+		if _, err := s.ws.Write(data); err != nil {
+			return errors.Wrap(err, "failed to replay spooled entry")
+		}
+		s.offset += int64(len(header) + len(data))
+	}
+
+	if err := s.file.Truncate(0); err != nil {
+		return errors.Wrap(err, "failed to truncate spool file")
+	}
+	s.size, s.offset = 0, 0
+	return nil
+}
+
+// completeSize returns the size of the complete records of the spool file, truncating a torn record at its end
+func completeSize(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to stat spool file")
+	}
+
+	var size int64
+	header := make([]byte, 4)
+	for {
+		if _, err := file.ReadAt(header, size); err != nil {
+			break
+		}
+		record := int64(len(header)) + int64(binary.BigEndian.Uint32(header))
+		if size+record > info.Size() {
+			break
+		}
+		size += record
+	}
+
+	if size != info.Size() {
+		if err := file.Truncate(size); err != nil {
+			return 0, errors.Wrap(err, "failed to truncate torn spool record")
+		}
+	}
+	return size, nil
+}
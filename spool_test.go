@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSpool(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "spool")[0])
+	remote := &bufferSyncer{}
+
+	ws, err := NewSpool(remote, SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := ws.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("1\n")
+	remote.setErr(errors.New("connection refused"))
+	write("2\n")
+	remote.setErr(nil)
+	write("3\n") // spooled to keep the order
+
+	if err := ws.(*spool).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undelivered entries survive restarts
+	ws, err = NewSpool(remote, SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.(*spool).Close()
+
+	if err := ws.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(remote.Bytes()); got != "1\n2\n3\n" {
+		t.Errorf("unexpected remote data: %q", got)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, spoolFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("want empty spool file, got %d bytes", info.Size())
+	}
+}
+
+func TestSpoolTornRecord(t *testing.T) {
+	dir := filepath.Dir(createTempFiles(t, "spool")[0])
+	remote := &bufferSyncer{err: errors.New("connection refused")}
+
+	ws, err := NewSpool(remote, SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.Write([]byte("1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.(*spool).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash during the write of the second record
+	file, err := os.OpenFile(filepath.Join(dir, spoolFileName), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte{0, 0, 0, 10, '2'}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	remote.setErr(nil)
+	ws, err = NewSpool(remote, SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.(*spool).Close()
+
+	if err := ws.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(remote.Bytes()); got != "1\n" {
+		t.Errorf("unexpected remote data: %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"database/sql"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StatsCollector returns a snapshot of statistics logged as fields, see Logger.LogStatsEvery
+type StatsCollector func() map[string]interface{}
+
+// LogStatsEvery logs the snapshots of the collectors as a single "stats" entry at Info level every interval
+// until the returned stop function is called. A non-positive interval logs nothing.
+// It's lightweight telemetry for services without metrics:
+//
+//	stop := log.LogStatsEvery(time.Minute, logger.RuntimeStats(), logger.DBStats("orders", db))
+//	defer stop()
+func (l *Logger) LogStatsEvery(interval time.Duration, collectors ...StatsCollector) (stop func() error) {
+	base := l.base().WithOptions(zap.WithCaller(false))
+	return runEvery(interval, func() {
+		ce := base.Check(InfoLevel, "stats")
+		if ce == nil {
+			return
+		}
+		stats := make(map[string]interface{})
+		for _, collect := range collectors {
+			for k, v := range collect() {
+				stats[k] = v
+			}
+		}
+		fields := make([]zap.Field, 0, len(stats))
+		for _, key := range sortedKeys(stats) {
+			fields = append(fields, zap.Any(key, stats[key]))
+		}
+		ce.Write(fields...)
+	})
+}
+
+// RuntimeStats collects the number of goroutines and memory and GC statistics of runtime.MemStats
+// as the runtime.* fields. Reading them stops the world briefly, so intervals shouldn't be too short
+func RuntimeStats() StatsCollector {
+	return func() map[string]interface{} {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return map[string]interface{}{
+			"runtime.goroutines":     runtime.NumGoroutine(),
+			"runtime.heap_alloc":     m.HeapAlloc,
+			"runtime.heap_inuse":     m.HeapInuse,
+			"runtime.heap_objects":   m.HeapObjects,
+			"runtime.sys":            m.Sys,
+			"runtime.num_gc":         m.NumGC,
+			"runtime.gc_pause_total": time.Duration(m.PauseTotalNs),
+		}
+	}
+}
+
+// DBStats collects the connection pool statistics of the database as the db.<name>.* fields
+func DBStats(name string, db *sql.DB) StatsCollector {
+	prefix := "db." + name + "."
+	return func() map[string]interface{} {
+		s := db.Stats()
+		return map[string]interface{}{
+			prefix + "open":                s.OpenConnections,
+			prefix + "in_use":              s.InUse,
+			prefix + "idle":                s.Idle,
+			prefix + "max_open":            s.MaxOpenConnections,
+			prefix + "wait_count":          s.WaitCount,
+			prefix + "wait_duration":       s.WaitDuration,
+			prefix + "max_idle_closed":     s.MaxIdleClosed,
+			prefix + "max_lifetime_closed": s.MaxLifetimeClosed,
+		}
+	}
+}
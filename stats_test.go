@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type noConnDriver struct{}
+
+func (noConnDriver) Open(string) (driver.Conn, error) { return nil, errors.New("no connections") }
+
+func init() {
+	sql.Register("noconn", noConnDriver{})
+}
+
+func TestLogStatsEvery(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	db, err := sql.Open("noconn", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(5)
+
+	stop := log.LogStatsEvery(10*time.Millisecond, RuntimeStats(), DBStats("main", db), func() map[string]interface{} {
+		return map[string]interface{}{"queue.size": 3}
+	})
+	for deadline := time.Now().Add(5 * time.Second); log.ObservedLogs().Len() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) < 2 {
+		t.Fatalf("want at least 2 entries, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if entries[0].Message != "stats" || fields["queue.size"] != int64(3) || fields["db.main.max_open"] != int64(5) ||
+		fields["runtime.goroutines"] == nil {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+}
+
+func TestLogStatsEveryNonPositiveInterval(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	stop := log.LogStatsEvery(0, RuntimeStats())
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if n := log.ObservedLogs().Len(); n != 0 {
+		t.Errorf("want no entries, got %d", n)
+	}
+}
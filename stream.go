@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// EntryStream is a client stream of entries to a collector service.
+// The schema of the gRPC LogCollector service is in proto/logger/v1/log.proto,
+// the github.com/kiteggrad/logger/loggergrpc module implements the client (loggergrpc.NewCore) and the server
+type EntryStream interface {
+	Send(e Entry) error
+	CloseSend() error
+}
+
+// OpenStreamFunc opens a new EntryStream, e.g. calls the generated LogCollectorClient.Stream
+type OpenStreamFunc func(ctx context.Context) (EntryStream, error)
+
+type StreamConfig struct {
+	// Level is the minimum level of sent entries. All entries are sent if it's nil
+	Level zapcore.LevelEnabler
+}
+
+// streamCore is a zapcore.Core sending entries to an EntryStream.
+// A broken stream is reopened on the next write
+type streamCore struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+	conn   *streamConn
+}
+
+type streamConn struct {
+	open OpenStreamFunc
+
+	mu     sync.Mutex
+	stream EntryStream
+}
+
+// NewStreamCore creates a core streaming entries to a collector service. Add it with Config.Cores.
+// The stream is closed by Logger.Shutdown
+func NewStreamCore(open OpenStreamFunc, cfg StreamConfig) zapcore.Core {
+	if cfg.Level == nil {
+		cfg.Level = zapcore.DebugLevel
+	}
+	return &streamCore{LevelEnabler: cfg.Level, conn: &streamConn{open: open}}
+}
+
+func (c *streamCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *streamCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *streamCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.conn.send(entryOf(ent, fieldsMap(c.fields, fields)))
+}
+
+func (c *streamCore) Sync() error { return nil }
+
+func (c *streamCore) Close() error { return c.conn.close() }
+
+func (c *streamConn) send(e Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stream == nil {
+		stream, err := c.open(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to open stream")
+		}
+		c.stream = stream
+	}
+
+	if err := c.stream.Send(e); err != nil {
+		_ = c.stream.CloseSend()
+		c.stream = nil
+		return errors.Wrap(err, "failed to send entry")
+	}
+	return nil
+}
+
+func (c *streamConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stream == nil {
+		return nil
+	}
+	err := c.stream.CloseSend()
+	c.stream = nil
+	return err
+}
+
+// ServeStream is the server side of a stream core: it writes entries received with recv
+// to the logger until recv returns io.EOF, e.g. in the LogCollector service of loggergrpc.NewCollector.
+// It returns the number of received entries
+func ServeStream(l *Logger, recv func() (Entry, error)) (received int64, err error) {
+	for {
+		e, err := recv()
+		if errors.Is(err, io.EOF) {
+			return received, nil
+		}
+		if err != nil {
+			return received, errors.Wrap(err, "failed to receive entry")
+		}
+
+		l.LogBatch([]Entry{e})
+		received++
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// chanStream is an in-memory EntryStream
+type chanStream chan Entry
+
+func (s chanStream) Send(e Entry) error { s <- e; return nil }
+
+func (s chanStream) CloseSend() error { close(s); return nil }
+
+func (s chanStream) recv() (Entry, error) {
+	e, ok := <-s
+	if !ok {
+		return Entry{}, io.EOF
+	}
+	return e, nil
+}
+
+func TestStream(t *testing.T) {
+	stream := make(chanStream, 10)
+	client := newLogger(t, Config{
+		DisableStdOut: true,
+		Cores: []zapcore.Core{NewStreamCore(func(ctx context.Context) (EntryStream, error) {
+			return stream, nil
+		}, StreamConfig{})},
+	})
+
+	client.Named("api").WithField("a", 1).Info("1")
+	client.Warn("2")
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := createTempFiles(t, "1.log")[0]
+	server := newLogger(t, Config{DisableStdOut: true, Files: []string{filename}})
+
+	received, err := ServeStream(server, stream.recv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received != 2 {
+		t.Errorf("want 2 received entries, got %d", received)
+	}
+
+	checkFileLogs(t, filename, [][]string{
+		{"INFO", "api", `1	{"a": 1}`},
+		{"WARN", "2"},
+	})
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkError is a Sync failure of a single output
+type SinkError struct {
+	// Sink is the output name: a path or URL of Config.Files, a name of Config.Sinks,
+	// "outputs[<i>]" for Config.Outputs or empty for Config.Cores
+	Sink string
+	Err  error
+}
+
+func (e *SinkError) Error() string {
+	if e.Sink == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("sink %s: %s", e.Sink, e.Err)
+}
+
+func (e *SinkError) Unwrap() error { return e.Err }
+
+// SyncError is returned by Logger.Sync if some outputs failed to sync
+type SyncError struct {
+	Errors []*SinkError
+}
+
+func (e *SyncError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "failed to sync: " + strings.Join(msgs, "; ")
+}
+
+// Sinks returns the names of the failed outputs
+func (e *SyncError) Sinks() []string {
+	sinks := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		sinks = append(sinks, err.Sink)
+	}
+	return sinks
+}
+
+// newSyncError groups the errors of the core Sync by output, nil if there are no errors
+func newSyncError(err error) error {
+	if err == nil {
+		return nil
+	}
+	syncErr := &SyncError{}
+	for _, err := range multierr.Errors(err) {
+		var sinkErr *SinkError
+		if !errors.As(err, &sinkErr) {
+			sinkErr = &SinkError{Err: err}
+		}
+		syncErr.Errors = append(syncErr.Errors, sinkErr)
+	}
+	return syncErr
+}
+
+// reportingSyncer labels Sync errors of an output with its name and ignores the errors
+// of outputs which can't be synced, like stdout attached to a terminal or a pipe
+type reportingSyncer struct {
+	zapcore.WriteSyncer
+	name string
+}
+
+func (s reportingSyncer) Sync() error {
+	var failed error
+	for _, err := range multierr.Errors(s.WriteSyncer.Sync()) {
+		if !isUnsyncable(err) {
+			failed = multierr.Append(failed, err)
+		}
+	}
+	if failed == nil {
+		return nil
+	}
+	return &SinkError{Sink: s.name, Err: failed}
+}
+
+// isUnsyncable reports whether the error means the file doesn't support syncing rather than a failure.
+// The errors differ by platform, see unsyncableErrors
+func isUnsyncable(err error) bool {
+	for _, unsyncable := range unsyncableErrors {
+		if errors.Is(err, unsyncable) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build dragonfly || freebsd || netbsd || openbsd
+
+package logger
+
+import "syscall"
+
+// unsyncableErrors are the errors of fsync on files which can't be synced: EINVAL for pipes and sockets,
+// ENOTTY for terminals and EOPNOTSUPP for file systems without fsync support
+var unsyncableErrors = []error{syscall.EINVAL, syscall.ENOTTY, syscall.EOPNOTSUPP}
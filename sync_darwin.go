@@ -0,0 +1,8 @@
+package logger
+
+import "syscall"
+
+// unsyncableErrors are the errors of fsync on files which can't be synced: ENOTTY for terminals,
+// ENOTSUP for pipes and sockets, EINVAL for other special files and EOPNOTSUPP for file systems
+// without fsync support, which differs from ENOTSUP on Apple platforms
+var unsyncableErrors = []error{syscall.ENOTTY, syscall.ENOTSUP, syscall.EINVAL, syscall.EOPNOTSUPP}
@@ -0,0 +1,8 @@
+package logger
+
+import "syscall"
+
+// unsyncableErrors are the errors of fsync on files which can't be synced: EINVAL for pipes, sockets
+// and character devices like terminals and /dev/null, ENOTTY for terminals of some drivers
+// and EOPNOTSUPP for file systems without fsync support
+var unsyncableErrors = []error{syscall.EINVAL, syscall.ENOTTY, syscall.EOPNOTSUPP}
@@ -0,0 +1,8 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || plan9 || windows)
+
+package logger
+
+import "syscall"
+
+// unsyncableErrors are the errors of syncing files which can't be synced on other platforms
+var unsyncableErrors = []error{syscall.EINVAL, syscall.ENOTSUP, syscall.ENOTTY}
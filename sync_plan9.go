@@ -0,0 +1,7 @@
+package logger
+
+import "syscall"
+
+// unsyncableErrors are the errors of syncing files which can't be synced on Plan 9,
+// which has no ENOTSUP and ENOTTY errors
+var unsyncableErrors = []error{syscall.EINVAL}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// failingSyncer is an output failing to sync with the given error
+type failingSyncer struct {
+	bufferSyncer
+	err error
+}
+
+func (s *failingSyncer) Sync() error { return s.err }
+
+func TestSyncErrors(t *testing.T) {
+	failure := errors.New("disk is gone")
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Sinks:         map[string]SinkConfig{"audit": {Output: &failingSyncer{err: failure}}},
+		Outputs: []zapcore.WriteSyncer{
+			&failingSyncer{err: &os.PathError{Op: "sync", Path: "/dev/stdout", Err: syscall.EINVAL}},
+			&failingSyncer{err: failure},
+		},
+	})
+
+	err := log.Sync()
+	var syncErr *SyncError
+	if !errors.As(err, &syncErr) {
+		t.Fatalf("want *SyncError, got %v", err)
+	}
+	if want := []string{"audit", "outputs[1]"}; !reflect.DeepEqual(syncErr.Sinks(), want) {
+		t.Errorf("want failed sinks %v, got %v", want, syncErr.Sinks())
+	}
+	if !errors.Is(syncErr.Errors[0], failure) {
+		t.Errorf("want the sink error to wrap the failure, got %v", syncErr.Errors[0])
+	}
+	if want := "failed to sync: sink audit: disk is gone; sink outputs[1]: disk is gone"; err.Error() != want {
+		t.Errorf("want %q, got %q", want, err.Error())
+	}
+}
+
+func TestSyncSkipsUnsyncable(t *testing.T) {
+	log := newLogger(t, Config{
+		DisableStdOut: true,
+		Outputs:       []zapcore.WriteSyncer{&failingSyncer{err: &os.PathError{Op: "sync", Path: "/dev/stdout", Err: unsyncableErrors[0]}}},
+	})
+	if err := log.Sync(); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+func TestIsUnsyncable(t *testing.T) {
+	for _, err := range unsyncableErrors {
+		if !isUnsyncable(&os.PathError{Op: "sync", Path: "/dev/stdout", Err: err}) {
+			t.Errorf("want %v ignored", err)
+		}
+	}
+	if isUnsyncable(&os.PathError{Op: "sync", Path: "/var/log/app.log", Err: syscall.EIO}) {
+		t.Error("want I/O errors reported")
+	}
+}
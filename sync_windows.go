@@ -0,0 +1,12 @@
+package logger
+
+import "syscall"
+
+const (
+	errorInvalidFunction = syscall.Errno(1) // ERROR_INVALID_FUNCTION
+	errorInvalidHandle   = syscall.Errno(6) // ERROR_INVALID_HANDLE
+)
+
+// unsyncableErrors are the errors of FlushFileBuffers on files which can't be synced:
+// ERROR_INVALID_HANDLE for consoles and ERROR_INVALID_FUNCTION for the NUL device
+var unsyncableErrors = []error{errorInvalidHandle, errorInvalidFunction, syscall.EINVAL}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// tailBuffer is the subscription buffer of a live-tail client
+const tailBuffer = 1000
+
+// TailHandler returns an HTTP live-tail endpoint streaming the bus entries as JSON lines
+// in the format of EncodingJSON, e.g. for cmd/logtail. The minimum level is set with ?level=warn.
+// Entries are dropped for clients reading slower than the entries are logged.
+// Fields that can't be encoded to JSON are streamed as strings with the "<key>Error" fields.
+// The handler has no authentication and streams all the entries, so it should be served on an internal port
+// or behind an authenticating middleware:
+//
+//	mux.Handle("/debug/tail", requireAdmin(bus.TailHandler()))
+func (b *EntryBus) TailHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lvl := DebugLevel
+		if name := req.URL.Query().Get("level"); name != "" {
+			var err error
+			if lvl, err = parseLevel(name); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		sub := b.Subscribe(tailBuffer, func(e Entry) bool { return e.Level >= lvl })
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case e := <-sub.Entries():
+				line, err := marshalTailLine(e)
+				if err != nil {
+					fmt.Fprintf(stderr, "%v failed to encode tail entry %q: %v\n", time.Now().UTC(), e.Message, err)
+					continue
+				}
+				// The stream ends only when the client is gone
+				if _, err := w.Write(line); err != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+		}
+	})
+}
+
+// marshalTailLine encodes the entry as a JSON line. Fields that can't be encoded, e.g. NaN floats,
+// are written as strings with the "<key>Error" fields, like zap writes the reflected fields it fails to encode
+func marshalTailLine(e Entry) ([]byte, error) {
+	line := tailLine(e)
+	b, err := json.Marshal(line)
+	if err != nil {
+		for k, v := range e.Fields {
+			if _, err := json.Marshal(v); err != nil {
+				line[k] = fmt.Sprint(v)
+				line[k+"Error"] = err.Error()
+			}
+		}
+		if b, err = json.Marshal(line); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '\n'), nil
+}
+
+// tailLine returns the entry fields with the entry keys of EncodingJSON
+func tailLine(e Entry) map[string]interface{} {
+	line := make(map[string]interface{}, len(e.Fields)+4)
+	for k, v := range e.Fields {
+		line[k] = v
+	}
+	line["ts"] = e.Time.Format(time.RFC3339Nano)
+	line["level"] = e.Level.String()
+	line["msg"] = e.Message
+	if e.LoggerName != "" {
+		line["logger"] = e.LoggerName
+	}
+	return line
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTailHandler(t *testing.T) {
+	bus := NewEntryBus()
+	log := newLogger(t, Config{DisableStdOut: true, Cores: []zapcore.Core{bus.Core(nil)}})
+	server := httptest.NewServer(bus.TailHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?level=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	for deadline := time.Now().Add(time.Second); !bus.hasSubscribers(); {
+		if time.Now().After(deadline) {
+			t.Fatal("the client didn't subscribe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	log.Info("below the level")
+	log.WithField("ratio", math.NaN()).Warn("not a number")
+	log.Named("db").WithField("user", "bob").Warn("slow query")
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []map[string]interface{}
+	for len(lines) < 2 && scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %v: %v", lines, scanner.Err())
+	}
+	if line := lines[0]; line["msg"] != "not a number" || line["ratio"] != "NaN" || line["ratioError"] == nil {
+		t.Errorf("want the value that can't be encoded as a string, got %v", line)
+	}
+	if line := lines[1]; line["msg"] != "slow query" || line["level"] != "warn" || line["logger"] != "db" || line["user"] != "bob" {
+		t.Errorf("unexpected line: %v", line)
+	}
+
+	if resp, err := http.Get(server.URL + "?level=loud"); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("want bad request for an invalid level, got %v %v", resp, err)
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+// TemporalLogger adapts a logger to the log.Logger interface of the Temporal SDK, so workflow and activity entries
+// with their metadata (WorkflowID, RunID, ActivityType...) go through the same pipeline:
+//
+//	c, err := client.Dial(client.Options{Logger: logger.NewTemporalLogger(log.Named("temporal"))})
+//
+// The SDK log.WithLogger interface isn't implemented as it returns an SDK type, log.With wraps the logger instead.
+// Cadence clients take a *zap.Logger, use Logger.ZapDesugared for them
+type TemporalLogger struct {
+	log *Logger
+}
+
+// NewTemporalLogger creates a Temporal SDK logger. Keyvals are alternating keys and values
+func NewTemporalLogger(l *Logger) *TemporalLogger {
+	return &TemporalLogger{log: l}
+}
+
+func (t *TemporalLogger) Debug(msg string, keyvals ...interface{}) {
+	t.log.sugar().Debugw(msg, keyvals...)
+}
+
+func (t *TemporalLogger) Info(msg string, keyvals ...interface{}) {
+	t.log.sugar().Infow(msg, keyvals...)
+}
+
+func (t *TemporalLogger) Warn(msg string, keyvals ...interface{}) {
+	t.log.sugar().Warnw(msg, keyvals...)
+}
+
+func (t *TemporalLogger) Error(msg string, keyvals ...interface{}) {
+	t.log.sugar().Errorw(msg, keyvals...)
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// temporalLogger is the log.Logger interface of the Temporal SDK
+type temporalLogger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+var _ temporalLogger = (*TemporalLogger)(nil)
+
+func TestTemporalLogger(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	tl := NewTemporalLogger(log.Named("temporal"))
+	tl.Info("Started workflow", "WorkflowID", "order-1", "Attempt", 2)
+	tl.Error("Activity error", "ActivityType", "Charge")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.LoggerName != "temporal" || e.ContextMap()["WorkflowID"] != "order-1" || e.ContextMap()["Attempt"] != int64(2) ||
+		!strings.HasSuffix(e.Caller.File, "temporal_test.go") {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.Level != zapcore.ErrorLevel || e.ContextMap()["ActivityType"] != "Charge" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
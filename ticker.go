@@ -0,0 +1,33 @@
+package logger
+
+import "time"
+
+// runEvery calls fn every interval in a separate goroutine until the returned stop function is called.
+// A non-positive interval never calls fn
+func runEvery(interval time.Duration, fn func()) (stop func() error) {
+	if interval <= 0 {
+		return func() error { return nil }
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() error {
+		ticker.Stop()
+		close(done)
+		<-stopped
+		return nil
+	}
+}
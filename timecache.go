@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultTimeLayout is the time layout of console encodings
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// timeCache formats entry times, reusing the last formatted time within the same second,
+// so high-throughput logging doesn't format the time for every entry.
+// Layouts with sub-second precision are formatted every time
+type timeCache struct {
+	layout    string
+	cacheable bool
+	last      atomic.Value // cachedTime
+}
+
+type cachedTime struct {
+	unix      int64
+	loc       *time.Location
+	formatted string
+}
+
+func newTimeCache(layout string) *timeCache {
+	if layout == "" {
+		layout = defaultTimeLayout
+	}
+	return &timeCache{layout: layout, cacheable: !hasSubSecond(layout)}
+}
+
+// hasSubSecond reports whether the layout formats fractions of a second
+func hasSubSecond(layout string) bool {
+	t := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return t.Format(layout) != t.Add(123456789).Format(layout)
+}
+
+func (c *timeCache) format(t time.Time) string {
+	if !c.cacheable {
+		return t.Format(c.layout)
+	}
+	unix, loc := t.Unix(), t.Location()
+	if last, ok := c.last.Load().(cachedTime); ok && last.unix == unix && last.loc == loc {
+		return last.formatted
+	}
+	formatted := t.Format(c.layout)
+	c.last.Store(cachedTime{unix: unix, loc: loc, formatted: formatted})
+	return formatted
+}
+
+func (c *timeCache) encode(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(c.format(t))
+}
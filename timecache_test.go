@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTimeCache(t *testing.T) {
+	base := time.Date(2024, 6, 2, 10, 30, 15, 100, time.UTC)
+
+	c := newTimeCache("")
+	if got := c.format(base); got != "2024-06-02 10:30:15" {
+		t.Errorf("unexpected time %q", got)
+	}
+	if got := c.format(base.Add(500 * time.Millisecond)); got != "2024-06-02 10:30:15" {
+		t.Errorf("want the cached time within the second, got %q", got)
+	}
+	if got := c.format(base.Add(time.Second)); got != "2024-06-02 10:30:16" {
+		t.Errorf("want the next second, got %q", got)
+	}
+	if got := c.format(base.In(time.FixedZone("X", 3600))); got != "2024-06-02 11:30:15" {
+		t.Errorf("want the time in another location, got %q", got)
+	}
+
+	sub := newTimeCache("15:04:05.000")
+	if sub.cacheable {
+		t.Error("sub-second layout must not be cached")
+	}
+	if got := sub.format(base.Add(500 * time.Millisecond)); got != "10:30:15.500" {
+		t.Errorf("unexpected sub-second time %q", got)
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := newLogger(t, Config{DisableStdOut: true, DisableColor: true, TimeLayout: "15:04:05.000", Outputs: []zapcore.WriteSyncer{zapcore.AddSync(buf)}})
+	log.Info("message")
+
+	line := buf.String()
+	if _, err := time.Parse("15:04:05.000", strings.Fields(line)[0]); err != nil {
+		t.Errorf("want the configured time layout, got %q: %v", line, err)
+	}
+}
+
+func BenchmarkTimeCache(b *testing.B) {
+	c := newTimeCache("")
+	now := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = c.format(now)
+	}
+}
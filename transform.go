@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Transformer rewrites an entry before it's encoded, e.g. to redact or enrich fields.
+// Fields contain both the logger and the entry fields, Caller identifies the call site. Returning false drops the entry
+type Transformer func(e Entry) (Entry, bool)
+
+// transformers is a hidden field value carrying the transformers added with Logger.Use
+type transformers []Transformer
+
+// Use returns a logger passing its entries through the transformers in order, after the ones added before.
+// It's supported by loggers created with New
+func (l *Logger) Use(transformer ...Transformer) *Logger {
+	return l.withFields(zap.Field{Key: "transformers", Type: zapcore.SkipType, Interface: transformers(transformer)})
+}
+
+// transformCore passes entries of loggers with transformers through them.
+// Without transformers the context fields are applied to the wrapped core as usual.
+// With transformers, entries are written to the core without the visible context fields (bare),
+// since the transformers can change or remove them
+type transformCore struct {
+	core zapcore.Core
+	// bare is the wrapped core with the hidden context fields only (e.g. sink targets), which configure the cores
+	bare         zapcore.Core
+	fields       []zapcore.Field // the visible context fields
+	transformers transformers
+}
+
+func newTransformCore(core zapcore.Core) *transformCore {
+	return &transformCore{core: core, bare: core}
+}
+
+func (c *transformCore) Enabled(lvl zapcore.Level) bool {
+	return c.core.Enabled(lvl)
+}
+
+func (c *transformCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	var rest, hidden []zapcore.Field
+	for _, f := range fields {
+		if t, ok := f.Interface.(transformers); ok && f.Type == zapcore.SkipType {
+			clone.transformers = append(clone.transformers[:len(clone.transformers):len(clone.transformers)], t...)
+			continue
+		}
+		rest = append(rest, f)
+		if f.Type == zapcore.SkipType {
+			hidden = append(hidden, f)
+		} else {
+			clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], f)
+		}
+	}
+	clone.core = c.core.With(rest)
+	if len(hidden) != 0 {
+		clone.bare = c.bare.With(hidden)
+	}
+	return &clone
+}
+
+func (c *transformCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if len(c.transformers) == 0 {
+		return c.core.Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write is called only for entries of loggers with transformers
+func (c *transformCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	visible := append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	e := entryOf(ent, fieldsMap(visible))
+	for _, transform := range c.transformers {
+		var ok bool
+		if e, ok = transform(e); !ok {
+			return nil
+		}
+	}
+
+	ent.Time, ent.Level, ent.LoggerName, ent.Message = e.Time, e.Level, e.LoggerName, e.Message
+	if ce := c.bare.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = stderr
+		ce.Write(orderedFields(e.Fields, visible)...)
+	}
+	return nil
+}
+
+func (c *transformCore) Sync() error {
+	return c.core.Sync()
+}
+
+// orderedFields converts the map to fields keeping the order of the original fields.
+// The original fields whose values the transformers didn't change are kept as is, so they keep their types,
+// e.g. errors aren't turned into strings. Added keys follow in sorted order
+func orderedFields(m map[string]interface{}, original []zapcore.Field) []zap.Field {
+	fields := make([]zap.Field, 0, len(m))
+	seen := make(map[string]bool, len(original))
+	for _, f := range original {
+		// A field can be encoded to several keys, e.g. "error" and "errorVerbose"
+		values := fieldsMap([]zapcore.Field{f})
+		if unchangedField(values, m, seen) {
+			fields = append(fields, f)
+			for key := range values {
+				seen[key] = true
+			}
+			continue
+		}
+		for _, key := range sortedKeys(values) {
+			if v, ok := m[key]; ok && !seen[key] {
+				fields = append(fields, zap.Any(key, v))
+				seen[key] = true
+			}
+		}
+	}
+	for _, key := range sortedKeys(m) {
+		if !seen[key] {
+			fields = append(fields, zap.Any(key, m[key]))
+		}
+	}
+	return fields
+}
+
+// unchangedField reports whether the map has all the values a field is encoded to and none of them is written yet
+func unchangedField(values, m map[string]interface{}, seen map[string]bool) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for key, v := range values {
+		if seen[key] {
+			return false
+		}
+		if got, ok := m[key]; !ok || !reflect.DeepEqual(got, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestUse(t *testing.T) {
+	filename := createTempFiles(t, "app.log")[0]
+	log := newLogger(t, Config{DisableStdOut: true, Encoding: EncodingJSON, Files: []string{filename}})
+
+	redact := func(e Entry) (Entry, bool) {
+		if _, ok := e.Fields["password"]; ok {
+			e.Fields["password"] = "***"
+		}
+		return e, true
+	}
+	dropHealth := func(e Entry) (Entry, bool) {
+		return e, !strings.HasPrefix(e.Message, "health")
+	}
+	rewrite := func(e Entry) (Entry, bool) {
+		e.Message = strings.ToUpper(e.Message)
+		e.Fields["env"] = "test"
+		return e, true
+	}
+
+	l := log.WithField("user", "john").WithField("password", "secret").Use(redact, dropHealth)
+	l.Info("login")
+	l.Info("health check")
+	l.Use(rewrite).WithField("a", 1).Warn("rewritten")
+	log.WithField("password", "plain").Info("untransformed")
+
+	checkFileLogs(t, filename, [][]string{
+		{`/transform_test.go:`, `"msg":"login","user":"john","password":"***"}`},
+		{`"level":"warn"`, `"msg":"REWRITTEN","user":"john","password":"***","a":1,"env":"test"}`},
+		{`"msg":"untransformed","password":"plain"}`},
+	})
+	if n := strings.Count(string(readFile(t, filename)), "\n"); n != 3 {
+		t.Errorf("want 3 entries, got %d", n)
+	}
+}
+
+func TestUseWithTargets(t *testing.T) {
+	files := createTempFiles(t, "app.log", "audit.log")
+	log := newLogger(t, Config{DisableStdOut: true, Sinks: map[string]SinkConfig{
+		"app":   {Path: files[0]},
+		"audit": {Path: files[1]},
+	}})
+
+	log.To("audit").Use(func(e Entry) (Entry, bool) { return e, true }).Info("audited")
+
+	if data := readFile(t, files[0]); len(data) != 0 {
+		t.Errorf("want no entries in the untargeted sink, got %s", data)
+	}
+	checkFileLogs(t, files[1], [][]string{{"audited"}})
+}
+
+func TestTransformerCaller(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	// Logs every call site once
+	seen := map[uintptr]bool{}
+	once := log.Use(func(e Entry) (Entry, bool) {
+		if !e.Caller.Defined || seen[e.Caller.PC] {
+			return e, false
+		}
+		seen[e.Caller.PC] = true
+		e.Fields["package"] = e.CallerPackage()
+		return e, true
+	})
+	for i := 0; i < 3; i++ {
+		once.Info("loop")
+	}
+	once.Info("other")
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 2 || entries[0].Message != "loop" || entries[1].Message != "other" {
+		t.Fatalf("want an entry per call site, got %+v", entries)
+	}
+	if pkg := entries[0].ContextMap()["package"]; pkg != "github.com/kiteggrad/logger" {
+		t.Errorf("unexpected caller package %v", pkg)
+	}
+	if (Entry{}).CallerPackage() != "" {
+		t.Error("want no package of an undefined caller")
+	}
+}
+
+func TestUseKeepsFieldTypes(t *testing.T) {
+	log := newLogger(t, Config{DisableStdOut: true, Observe: true})
+
+	cause := errors.New("timeout")
+	log.WithError(cause).Use(func(e Entry) (Entry, bool) {
+		e.Fields["attempt"] = 2
+		return e, true
+	}).InfoFields("retried", zap.Int("attempt", 1))
+
+	entries := log.ObservedLogs().AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 entry, got %d", len(entries))
+	}
+	var gotErr error
+	for _, f := range entries[0].Context {
+		if f.Key == "error" && f.Type == zapcore.ErrorType {
+			gotErr, _ = f.Interface.(error)
+		}
+	}
+	if gotErr != cause {
+		t.Errorf("want the error field kept, got %+v", entries[0].Context)
+	}
+	if fields := entries[0].ContextMap(); fields["attempt"] != int64(2) {
+		t.Errorf("want the changed field, got %+v", fields)
+	}
+}
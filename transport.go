@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkTransportConfig is a common transport configuration of network outputs
+type SinkTransportConfig struct {
+	// CertFile and KeyFile are a client certificate for mutual TLS
+	CertFile string
+	KeyFile  string
+	// CAFile is a CA bundle to verify the server with instead of the system pool
+	CAFile string
+	// TLS enables TLS for TCP outputs. It's implied if any TLS file is set
+	TLS bool
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool
+
+	// Token is sent as "Authorization: Bearer <token>" by HTTP outputs
+	Token string
+	// Headers are sent by HTTP outputs with every request
+	Headers map[string]string
+	// ProxyURL is an HTTP proxy. By default the proxy is taken from the environment
+	ProxyURL string
+
+	// Timeout limits a single write (request). Defaults to 10s
+	Timeout time.Duration
+	// DialTimeout limits establishing a connection. Defaults to 5s
+	DialTimeout time.Duration
+}
+
+func (c SinkTransportConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c SinkTransportConfig) dialTimeout() time.Duration {
+	if c.DialTimeout <= 0 {
+		return 5 * time.Second
+	}
+	return c.DialTimeout
+}
+
+func (c SinkTransportConfig) tlsEnabled() bool {
+	return c.TLS || c.CertFile != "" || c.CAFile != "" || c.InsecureSkipVerify
+}
+
+// TLSConfig builds a *tls.Config from the TLS options
+func (c SinkTransportConfig) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to tls.LoadX509KeyPair")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// HTTPClient builds an *http.Client using the transport options.
+// Token and Headers aren't applied by the client, use SetHeaders on requests
+func (c SinkTransportConfig) HTTPClient() (*http.Client, error) {
+	tlsCfg, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	transport.DialContext = (&net.Dialer{Timeout: c.dialTimeout()}).DialContext
+	if c.ProxyURL != "" {
+		proxy, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse proxy url")
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport, Timeout: c.timeout()}, nil
+}
+
+// SetHeaders sets Token and Headers on the request
+func (c SinkTransportConfig) SetHeaders(req *http.Request) {
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+// Dial connects to the address using TLS if it's enabled
+func (c SinkTransportConfig) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: c.dialTimeout()}
+	if !c.tlsEnabled() {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	tlsCfg, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return (&tls.Dialer{NetDialer: dialer, Config: tlsCfg}).DialContext(ctx, network, addr)
+}
+
+// httpOutput is a zapcore.WriteSyncer sending every entry with a POST request
+type httpOutput struct {
+	url       string
+	transport SinkTransportConfig
+	client    *http.Client
+}
+
+// NewHTTPOutput creates an output sending every entry to the URL with a POST request.
+// Combine it with ConcurrentOutputs, NewCircuitBreaker and NewSpool for resilient shipping
+func NewHTTPOutput(url string, transport SinkTransportConfig) (zapcore.WriteSyncer, error) {
+	client, err := transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &httpOutput{url: url, transport: transport, client: client}, nil
+}
+
+func (o *httpOutput) Write(p []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, o.url, bytes.NewReader(p))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to http.NewRequest")
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	o.transport.SetHeaders(req)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to send entry")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return len(p), nil
+}
+
+func (o *httpOutput) Sync() error { return nil }
+
+// tcpOutput is a zapcore.WriteSyncer writing entries to a TCP connection.
+// The connection is reestablished on the next write after a failure
+type tcpOutput struct {
+	addr      string
+	transport SinkTransportConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewTCPOutput creates an output writing entries to a TCP (or TLS) connection
+func NewTCPOutput(addr string, transport SinkTransportConfig) zapcore.WriteSyncer {
+	return &tcpOutput{addr: addr, transport: transport}
+}
+
+func (o *tcpOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.conn == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), o.transport.dialTimeout())
+		defer cancel()
+
+		conn, err := o.transport.Dial(ctx, "tcp", o.addr)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to dial")
+		}
+		o.conn = conn
+	}
+
+	if err := o.conn.SetWriteDeadline(time.Now().Add(o.transport.timeout())); err != nil {
+		return 0, o.reset(errors.Wrap(err, "failed to set write deadline"))
+	}
+	n, err := o.conn.Write(p)
+	if err != nil {
+		return n, o.reset(errors.Wrap(err, "failed to write"))
+	}
+	return n, nil
+}
+
+func (o *tcpOutput) Sync() error { return nil }
+
+func (o *tcpOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.reset(nil)
+}
+
+// reset closes the connection, so the next write reconnects
+func (o *tcpOutput) reset(err error) error {
+	if o.conn != nil {
+		_ = o.conn.Close()
+		o.conn = nil
+	}
+	return err
+}
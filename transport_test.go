@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPOutput(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected Authorization header: %q", got)
+		}
+		if got := r.Header.Get("X-Tenant"); got != "team" {
+			t.Errorf("unexpected X-Tenant header: %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer srv.Close()
+
+	ws, err := NewHTTPOutput(srv.URL, SinkTransportConfig{Token: "secret", Headers: map[string]string{"X-Tenant": "team"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.Write([]byte("entry\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got != "entry\n" {
+		t.Errorf("unexpected body: %q", got)
+	}
+}
+
+func TestTCPOutput(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	ws := NewTCPOutput(ln.Addr().String(), SinkTransportConfig{})
+	defer ws.(io.Closer).Close()
+
+	if _, err := ws.Write([]byte("entry\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got != "entry\n" {
+		t.Errorf("unexpected data: %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// userAgentBrowsers are the product tokens of browsers and clients in order of precedence,
+// e.g. Edge user agents contain the Chrome and Safari tokens too
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"SamsungBrowser/", "Samsung Internet"}, {"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"}, {"CriOS/", "Chrome"}, {"FxiOS/", "Firefox"}, {"Version/", "Safari"},
+	{"curl/", "curl"}, {"Wget/", "Wget"}, {"Go-http-client/", "Go"}, {"python-requests/", "python-requests"},
+}
+
+// userAgentSystems are the operating system tokens in order of precedence
+var userAgentSystems = []struct{ token, name string }{
+	{"Windows", "Windows"}, {"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iOS"}, {"iPod", "iOS"},
+	{"CrOS", "ChromeOS"}, {"Mac OS X", "macOS"}, {"Macintosh", "macOS"}, {"Linux", "Linux"},
+}
+
+// UserAgent is the result of ParseUserAgent
+type UserAgent struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	// Device is "bot", "mobile", "tablet" or "desktop"
+	Device string
+}
+
+// ParseUserAgent recognizes common browsers, bots and operating systems in a User-Agent header value.
+// Unknown values are returned empty
+func ParseUserAgent(ua string) UserAgent {
+	var parsed UserAgent
+	for _, b := range userAgentBrowsers {
+		if i := strings.Index(ua, b.token); i >= 0 {
+			parsed.Browser = b.name
+			parsed.BrowserVersion, _, _ = strings.Cut(ua[i+len(b.token):], " ")
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(ua, s.token) {
+			parsed.OS = s.name
+			break
+		}
+	}
+
+	lower := strings.ToLower(ua)
+	switch {
+	case strings.Contains(lower, "bot") || strings.Contains(lower, "crawler") || strings.Contains(lower, "spider"):
+		parsed.Device = "bot"
+		if parsed.Browser == "" {
+			parsed.Browser = botName(ua)
+		}
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet"):
+		parsed.Device = "tablet"
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone"):
+		parsed.Device = "mobile"
+	case parsed.OS == "Android":
+		// Android tablets don't have the "Mobile" token
+		parsed.Device = "tablet"
+	default:
+		parsed.Device = "desktop"
+	}
+	return parsed
+}
+
+// botName returns the product token containing "bot", e.g. "Googlebot" of "Googlebot/2.1"
+func botName(ua string) string {
+	for _, token := range strings.FieldsFunc(ua, func(r rune) bool { return r == ' ' || r == ';' || r == '(' || r == ')' }) {
+		name, _, _ := strings.Cut(token, "/")
+		if strings.Contains(strings.ToLower(name), "bot") {
+			return name
+		}
+	}
+	return ""
+}
+
+// UserAgentEnricher returns a cached Enricher parsing the user agent of the key field (e.g. "user_agent")
+// into the "<key>.browser", "<key>.browser_version", "<key>.os" and "<key>.device" fields, see ParseUserAgent
+func UserAgentEnricher(key string) Enricher {
+	return NewCachedEnricher(EnricherFunc(func(e Entry) map[string]interface{} {
+		ua := ParseUserAgent(fmt.Sprint(e.Fields[key]))
+		fields := map[string]interface{}{key + ".device": ua.Device}
+		for name, value := range map[string]string{"browser": ua.Browser, "browser_version": ua.BrowserVersion, "os": ua.OS} {
+			if value != "" {
+				fields[key+"."+name] = value
+			}
+		}
+		return fields
+	}), CachedEnricherConfig{Key: key})
+}